@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	log "github.com/behance/go-logrus"
+)
+
+// Apply - make metronome match a directory of job manifests
+//  - Implements CommandParse/CommandExec
+//  - Jobs() + Schedules() to read the cluster then POST/PUT/DELETE /v1/jobs and /v1/jobs/$jobId/schedules
+type Apply struct {
+	files    PathList
+	dryRun   bool
+	prune    bool
+	selector LabelSelector
+	overlay  string
+	jobs     []*met.Job
+}
+
+// Usage - apply usage
+func (apply *Apply) Usage(writer io.Writer) {
+	fmt.Fprintln(writer, `
+apply -f <dir|file> [--overlay NAME] [--dry-run] [--prune --selector key=value]
+	  Create, update or delete jobs and their schedules so metronome matches the manifests.
+	  Manifests are job specs (json or yaml) with embedded schedules; a job's schedules not in its manifest are deleted.
+	  --prune also deletes jobs without a manifest, but only those matching --selector`)
+	flags := flag.NewFlagSet("apply", flag.ExitOnError)
+	apply.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// FlagSet - apply flags
+func (apply *Apply) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.Var(&apply.files, "f", "Manifest file or directory of *.json, *.yaml, *.yml.  '-' for stdin.  You can call more than once")
+	flags.BoolVar(&apply.dryRun, "dry-run", false, "Print the plan without changing anything")
+	flags.BoolVar(&apply.prune, "prune", false, "Delete jobs matching --selector that have no manifest")
+	flags.StringVar(&apply.overlay, "overlay", "", "NAME of the overlay in the spec's overlays member to merge over the base spec e.g. prod")
+	flags.Var(&apply.selector, "selector", "Label selector e.g. 'team=reports,env!=prod' . Only matching jobs may be deleted by --prune")
+	return flags
+}
+
+// Validate - manifests are required; pruning needs a selector so it can't delete everything
+func (apply *Apply) Validate() error {
+	if len(apply.files) == 0 {
+		return errors.New("-f required")
+	}
+	if apply.prune && len(apply.selector) == 0 {
+		return errors.New("--prune requires --selector")
+	}
+	return nil
+}
+
+// Parse - read and validate every manifest up front so nothing is applied from a broken set
+func (apply *Apply) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("apply", flag.ExitOnError)
+	apply.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = apply.Validate(); err != nil {
+		panic(err)
+	}
+	manifests, err := manifestFiles(apply.files)
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	for _, file := range manifests {
+		job, err := readJobSpec(file, apply.overlay)
+		if err == nil {
+			err = job.Validate()
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", file, err.Error()))
+			continue
+		}
+		apply.jobs = append(apply.jobs, job)
+	}
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "\n"))
+	}
+	return apply, nil
+}
+
+// manifestFiles - expand directories to the spec files directly inside them
+func manifestFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		if path == StdinSpec {
+			files = append(files, path)
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		var found []string
+		for _, entry := range entries {
+			switch strings.ToLower(filepath.Ext(entry.Name())) {
+			case ".json", ".yaml", ".yml":
+				if !entry.IsDir() {
+					found = append(found, filepath.Join(path, entry.Name()))
+				}
+			}
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// Execute - plan, print the plan, then carry it out unless --dry-run
+func (apply *Apply) Execute(runtime *Runtime) (interface{}, error) {
+	current, err := met.CurrentJobs(runtime.client)
+	if err != nil {
+		return nil, err
+	}
+	var prune func(*met.Job) bool
+	if apply.prune {
+		prune = func(job *met.Job) bool {
+			return apply.selector.Matches(job.GetLabels())
+		}
+	}
+	plan, err := met.NewPlan(apply.jobs, current, prune)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan) == 0 {
+		return "no changes", nil
+	}
+	steps := make([]string, 0, len(plan))
+	for _, step := range plan {
+		steps = append(steps, step.String())
+		log.Infof("plan: %s", step)
+	}
+	if apply.dryRun {
+		return steps, nil
+	}
+	err = plan.Execute(runtime.client, func(step *met.PlanStep, err error) {
+		if err != nil {
+			log.Errorf("%s failed: %s", step, err.Error())
+		} else {
+			log.Infof("%s done", step)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("applied %d changes", len(plan)), nil
+}
@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	log "github.com/behance/go-logrus"
+)
+
+// BlackoutTopLevel - top level cli menu for blackout windows
+//  Implements CommandParse
+type BlackoutTopLevel JobTopLevel
+
+// Usage - CommandParse implementation
+func (theBlackout *BlackoutTopLevel) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "blackout {run|status} <options>:\n")
+	fmt.Fprintln(writer, `
+	  run <options>     | Disable schedules while blackouts are open and restore them after
+	  status <options>  | Which blackouts are open
+
+	  Blackouts are a json or yaml file: {"blackouts": [{"name": "...", "start": RFC3339, "end": RFC3339, "selector": "team=reports,env!=dev"},
+	    {"name": "...", "cron": "0 22 * * FRI", "duration": "4h", "timezone": "Europe/Berlin"}]}
+	`)
+}
+
+// Parse - parse the top level `blackout <action>` menu
+func (theBlackout *BlackoutTopLevel) Parse(args []string) (exec CommandExec, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			fmt.Fprintln(buf, r.(error).Error())
+			fmt.Fprintf(buf, "\n %s usage:\n", theBlackout.subcommand)
+			if theBlackout.task != nil {
+				theBlackout.task.Usage(buf)
+			}
+			theBlackout.Usage(buf)
+			err = errors.New(buf.String())
+		}
+	}()
+	if len(args) == 0 {
+		panic(errors.New("sub command required"))
+	}
+	theBlackout.subcommand = args[0]
+	switch theBlackout.subcommand {
+	case "run":
+		theBlackout.task = CommandParse(new(BlackoutRun))
+	case "status":
+		theBlackout.task = CommandParse(new(BlackoutStatus))
+	case "help", "--help":
+		panic(errors.New("blackout help"))
+	default:
+		panic(fmt.Errorf("blackout: unknown action '%s'", theBlackout.subcommand))
+	}
+	var subcommandArgs []string
+	if len(args) > 1 {
+		subcommandArgs = args[1:]
+	}
+	if exec, err = theBlackout.task.Parse(subcommandArgs); err != nil {
+		panic(err)
+	}
+	return exec, nil
+}
+
+// readBlackouts - decode and validate a json or yaml blackout file
+func readBlackouts(file string) ([]*met.Blackout, error) {
+	raw, err := readSpec(file)
+	if err != nil {
+		return nil, err
+	}
+	if raw, err = specJSON(raw); err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err.Error())
+	}
+	blackouts, err := met.ParseBlackouts(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err.Error())
+	}
+	return blackouts, nil
+}
+
+// BlackoutRun - the blackout controller
+//  - Implements CommandParse/CommandExec
+//  - GET /v1/jobs and /v1/jobs/$jobId/schedules then PUT /v1/jobs/$jobId/schedules/$scheduleId
+type BlackoutRun struct {
+	file      string
+	state     string
+	interval  time.Duration
+	once      bool
+	dryRun    bool
+	blackouts []*met.Blackout
+}
+
+// FlagSet - blackout file, state file and how often to check
+func (theRun *BlackoutRun) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar(&theRun.file, "file", "", "Blackout windows, json or yaml")
+	flags.StringVar(&theRun.state, "state", "blackout-state.json", "Where the controller remembers which schedules it paused and their previous enabled")
+	flags.DurationVar(&theRun.interval, "interval", time.Minute, "How often to check the windows")
+	flags.BoolVar(&theRun.once, "once", false, "Check once and exit e.g. from cron")
+	flags.BoolVar(&theRun.dryRun, "dry-run", false, "Report the changes without making them.  Implies --once")
+	return flags
+}
+
+// Validate - a blackout file and state file
+func (theRun *BlackoutRun) Validate() error {
+	if theRun.file == "" {
+		return errors.New("file required")
+	}
+	if theRun.state == "" {
+		return errors.New("state required")
+	}
+	if theRun.interval <= 0 {
+		return errors.New("interval must be > 0")
+	}
+	return nil
+}
+
+// Usage - CommandParse implementation
+func (theRun *BlackoutRun) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("blackout run", flag.ExitOnError)
+	theRun.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - read and validate the blackout file
+func (theRun *BlackoutRun) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("blackout run", flag.ExitOnError)
+	theRun.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theRun.Validate(); err != nil {
+		panic(err)
+	}
+	if theRun.blackouts, err = readBlackouts(theRun.file); err != nil {
+		return nil, err
+	}
+	return theRun, nil
+}
+
+// Execute - reconcile once, or every --interval until killed.  The state file makes stopping at any point safe
+func (theRun *BlackoutRun) Execute(runtime *Runtime) (interface{}, error) {
+	controller := &met.BlackoutController{
+		Client:    runtime.client,
+		Blackouts: theRun.blackouts,
+		StateFile: theRun.state,
+		DryRun:    theRun.dryRun,
+	}
+	for {
+		actions, err := controller.Reconcile(time.Now())
+		for _, action := range actions {
+			log.Infof("blackout: %s", action)
+		}
+		if theRun.once || theRun.dryRun {
+			if actions == nil {
+				actions = []string{}
+			}
+			return actions, err
+		}
+		if err != nil {
+			log.Errorf("blackout: %s", err.Error())
+		}
+		time.Sleep(theRun.interval)
+	}
+}
+
+// BlackoutStatus - which blackouts are open, offline
+//  - Implements CommandParse/CommandExec/CommandLocal
+type BlackoutStatus struct {
+	file      string
+	at        string
+	when      time.Time
+	blackouts []*met.Blackout
+}
+
+// FlagSet - blackout file and when
+func (theStatus *BlackoutStatus) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar(&theStatus.file, "file", "", "Blackout windows, json or yaml")
+	flags.StringVar(&theStatus.at, "at", "", "RFC3339 time or +DURATION from now to check.  Default now")
+	return flags
+}
+
+// Validate - a blackout file and a readable time
+func (theStatus *BlackoutStatus) Validate() (err error) {
+	if theStatus.file == "" {
+		return errors.New("file required")
+	}
+	theStatus.when = time.Now()
+	if theStatus.at != "" {
+		if theStatus.when, err = parseWhen(theStatus.at, theStatus.when); err != nil {
+			return fmt.Errorf("at: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// Usage - CommandParse implementation
+func (theStatus *BlackoutStatus) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("blackout status", flag.ExitOnError)
+	theStatus.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - read and validate the blackout file
+func (theStatus *BlackoutStatus) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("blackout status", flag.ExitOnError)
+	theStatus.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theStatus.Validate(); err != nil {
+		panic(err)
+	}
+	if theStatus.blackouts, err = readBlackouts(theStatus.file); err != nil {
+		return nil, err
+	}
+	return theStatus, nil
+}
+
+// Local - CommandLocal implementation
+func (theStatus *BlackoutStatus) Local() bool {
+	return true
+}
+
+// Execute - a line per blackout
+func (theStatus *BlackoutStatus) Execute(runtime *Runtime) (interface{}, error) {
+	buf := new(bytes.Buffer)
+	tw := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tOPEN\tSELECTOR")
+	for _, blackout := range theStatus.blackouts {
+		open, err := blackout.Active(theStatus.when)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(tw, "%s\t%t\t%v\n", blackout.Name, open, blackout.Selector.String())
+	}
+	tw.Flush()
+	return Text(buf.String()), nil
+}
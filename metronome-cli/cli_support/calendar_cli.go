@@ -0,0 +1,343 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+)
+
+// calendar output formats
+const (
+	CalendarFormatTable = "table"
+	CalendarFormatJSON  = "json"
+	CalendarFormatICS   = "ics"
+)
+
+// icsTime - iCalendar UTC date-time
+const icsTime = "20060102T150405Z"
+
+// SchedCalendar - the timeline of every job's enabled schedules between two times
+//  - Implements CommandParse/CommandExec
+//  - GET /v1/jobs and /v1/jobs/$jobId/schedules
+type SchedCalendar struct {
+	window
+	format string
+	out    string
+}
+
+// window - the time span and jobs a calendar or forecast covers
+type window struct {
+	from     string
+	to       string
+	selector LabelSelector
+	start    time.Time
+	end      time.Time
+}
+
+// FlagSet - from, to and selector
+func (win *window) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar(&win.from, "from", "", "Start of the window: an RFC3339 time or +DURATION from now.  Default now")
+	flags.StringVar(&win.to, "to", "+24h", "End of the window: an RFC3339 time or +DURATION from --from")
+	flags.Var(&win.selector, "selector", "Label selector e.g. 'env!=dev,tier in (batch,etl)' . Only the matching jobs.  Default every job")
+	return flags
+}
+
+// Validate - a window that ends after it starts
+func (win *window) Validate() (err error) {
+	win.start = time.Now()
+	if win.from != "" {
+		if win.start, err = parseWhen(win.from, win.start); err != nil {
+			return fmt.Errorf("from: %s", err.Error())
+		}
+	}
+	if win.end, err = parseWhen(win.to, win.start); err != nil {
+		return fmt.Errorf("to: %s", err.Error())
+	}
+	if !win.end.After(win.start) {
+		return errors.New("to must be after from")
+	}
+	return nil
+}
+
+// selects - the job is covered by the selector; an empty one covers every job
+func (win *window) selects(job *met.Job) bool {
+	return win.selector.Matches(job.Labels)
+}
+
+// FlagSet - window, selector and output
+func (theCal *SchedCalendar) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theCal.window.FlagSet(flags)
+	flags.StringVar(&theCal.format, "format", CalendarFormatTable, "Output format: table, json or ics (iCalendar)")
+	flags.StringVar(&theCal.out, "out", "", "Write to FILE instead of stdout e.g. tonight.ics")
+	return flags
+}
+
+// Validate - a window that ends after it starts and a known format
+func (theCal *SchedCalendar) Validate() error {
+	switch theCal.format {
+	case CalendarFormatTable, CalendarFormatJSON, CalendarFormatICS:
+	default:
+		return fmt.Errorf("format must be one of %s, %s, %s", CalendarFormatTable, CalendarFormatJSON, CalendarFormatICS)
+	}
+	return theCal.window.Validate()
+}
+
+// Usage - CommandParse implementation
+func (theCal *SchedCalendar) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("schedule calendar", flag.ExitOnError)
+	theCal.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - CommandParse implementation
+func (theCal *SchedCalendar) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("schedule calendar", flag.ExitOnError)
+	theCal.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theCal.Validate(); err != nil {
+		panic(err)
+	}
+	return theCal, nil
+}
+
+// Execute - expand the schedules and render the timeline
+func (theCal *SchedCalendar) Execute(runtime *Runtime) (interface{}, error) {
+	current, err := met.CurrentJobs(runtime.client)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []*met.Job
+	for _, job := range current {
+		if theCal.selects(job) {
+			jobs = append(jobs, job)
+		}
+	}
+	firings, err := met.ExpandSchedules(jobs, theCal.start, theCal.end)
+	if err != nil {
+		return nil, err
+	}
+	var out string
+	switch theCal.format {
+	case CalendarFormatJSON:
+		if firings == nil {
+			firings = []met.Firing{}
+		}
+		raw, err := json.MarshalIndent(firings, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		out = string(raw) + "\n"
+	case CalendarFormatICS:
+		out = calendarICS(firings, time.Now())
+	default:
+		out = calendarTable(firings)
+	}
+	if theCal.out != "" {
+		if err = ioutil.WriteFile(theCal.out, []byte(out), 0644); err != nil {
+			return nil, err
+		}
+		return fmt.Sprintf("wrote %d runs to %s", len(firings), theCal.out), nil
+	}
+	return Text(out), nil
+}
+
+// calendarTable - one line per firing, times in the schedule's time zone
+func calendarTable(firings []met.Firing) string {
+	buf := new(bytes.Buffer)
+	tw := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tZONE\tJOB\tSCHEDULE\tCPUS\tMEM")
+	for _, firing := range firings {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%g\t%d\n", firing.At.Format("2006-01-02 15:04 -0700"), firing.Timezone, firing.JobID, firing.ScheduleID, firing.Cpus, firing.Mem)
+	}
+	tw.Flush()
+	return buf.String()
+}
+
+// icsText - escape a TEXT value per RFC 5545
+func icsText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(text)
+}
+
+// icsLineOctets - RFC 5545 content lines longer than this are folded
+const icsLineOctets = 75
+
+// icsFold - `line` split into content lines of at most 75 octets, continuations starting with a space.  Never splits a utf-8 sequence
+func icsFold(line string) string {
+	if len(line) <= icsLineOctets {
+		return line
+	}
+	buf := new(bytes.Buffer)
+	limit := icsLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// the leading space counts
+		limit = icsLineOctets - 1
+	}
+	buf.WriteString(line)
+	return buf.String()
+}
+
+// calendarICS - an iCalendar with an event per firing
+func calendarICS(firings []met.Firing, stamp time.Time) string {
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//go-metronome//schedule calendar//EN", "CALSCALE:GREGORIAN"}
+	for _, firing := range firings {
+		at := firing.At.UTC()
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:%s.%s.%d@metronome", firing.JobID, firing.ScheduleID, at.Unix()),
+			"DTSTAMP:"+stamp.UTC().Format(icsTime),
+			"DTSTART:"+at.Format(icsTime),
+			"SUMMARY:"+icsText(fmt.Sprintf("%s (%s)", firing.JobID, firing.ScheduleID)),
+			"DESCRIPTION:"+icsText(fmt.Sprintf("cpus %g, mem %d MB, disk %d MB, schedule time zone %s", firing.Cpus, firing.Mem, firing.Disk, firing.Timezone)),
+			"END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+	for i, line := range lines {
+		lines[i] = icsFold(line)
+	}
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// SchedForecast - expected cluster load from every job's schedules and the peaks over capacity
+//  - Implements CommandParse/CommandExec
+//  - GET /v1/jobs then /v1/jobs/$jobId?embed=history&embed=schedules for each job
+type SchedForecast struct {
+	window
+	capacity met.Resources
+	fallback time.Duration
+	format   string
+}
+
+// FlagSet - window, selector, capacity and output
+func (theCast *SchedForecast) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theCast.window.FlagSet(flags)
+	flags.Float64Var(&theCast.capacity.Cpus, "cpus", 0, "Cpus the cluster has for jobs.  A peak is any time the jobs running ask for more")
+	flags.IntVar(&theCast.capacity.Mem, "mem", 0, "Memory (MB) the cluster has for jobs")
+	flags.IntVar(&theCast.capacity.Disk, "disk", 0, "Disk (MB) the cluster has for jobs")
+	flags.DurationVar(&theCast.fallback, "default-duration", 10*time.Minute, "How long runs of a job without history are expected to take")
+	flags.StringVar(&theCast.format, "format", CalendarFormatTable, "Output format: table or json")
+	return flags
+}
+
+// Validate - a window, some capacity and a known format
+func (theCast *SchedForecast) Validate() error {
+	if theCast.capacity == (met.Resources{}) {
+		return errors.New("one of --cpus, --mem or --disk required")
+	}
+	if theCast.fallback <= 0 {
+		return errors.New("default-duration must be > 0")
+	}
+	if theCast.format != CalendarFormatTable && theCast.format != CalendarFormatJSON {
+		return fmt.Errorf("format must be %s or %s", CalendarFormatTable, CalendarFormatJSON)
+	}
+	return theCast.window.Validate()
+}
+
+// Usage - CommandParse implementation
+func (theCast *SchedForecast) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("schedule forecast", flag.ExitOnError)
+	theCast.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - CommandParse implementation
+func (theCast *SchedForecast) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("schedule forecast", flag.ExitOnError)
+	theCast.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theCast.Validate(); err != nil {
+		panic(err)
+	}
+	return theCast, nil
+}
+
+// Execute - forecast the load and report the peaks
+func (theCast *SchedForecast) Execute(runtime *Runtime) (interface{}, error) {
+	all, err := runtime.client.Jobs()
+	if err != nil {
+		return nil, err
+	}
+	var jobs []*met.Job
+	for i := range *all {
+		if !theCast.selects(&(*all)[i]) {
+			continue
+		}
+		// the job list has no history
+		job, err := runtime.client.GetJob((*all)[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %s", (*all)[i].ID, err.Error())
+		}
+		jobs = append(jobs, job)
+	}
+	segments, err := met.ForecastLoad(jobs, theCast.start, theCast.end, theCast.fallback)
+	if err != nil {
+		return nil, err
+	}
+	peaks := met.Peaks(segments, theCast.capacity)
+	if theCast.format == CalendarFormatJSON {
+		if peaks == nil {
+			peaks = []met.LoadSegment{}
+		}
+		raw, err := json.MarshalIndent(peaks, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return Text(string(raw) + "\n"), nil
+	}
+	if len(peaks) == 0 {
+		return fmt.Sprintf("no peaks over capacity in %d jobs between %s and %s", len(jobs), theCast.start.Format(time.RFC3339), theCast.end.Format(time.RFC3339)), nil
+	}
+	return Text(peakTable(peaks)), nil
+}
+
+// peakTable - one line per peak with the runs contributing to it
+func peakTable(peaks []met.LoadSegment) string {
+	buf := new(bytes.Buffer)
+	tw := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "START\tEND\tCPUS\tMEM\tDISK\tJOBS")
+	for _, peak := range peaks {
+		runs := make([]string, 0, len(peak.Runs))
+		for _, run := range peak.Runs {
+			runs = append(runs, fmt.Sprintf("%s/%s@%s(%s)", run.JobID, run.ScheduleID, run.At.Format("15:04"), run.Duration))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%g\t%d\t%d\t%s\n", peak.Start.Format("2006-01-02 15:04Z07:00"), peak.End.Format("15:04Z07:00"), peak.Load.Cpus, peak.Load.Mem, peak.Load.Disk, strings.Join(runs, " "))
+	}
+	tw.Flush()
+	return buf.String()
+}
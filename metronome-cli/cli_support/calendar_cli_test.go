@@ -0,0 +1,42 @@
+package cli_test
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	cli "github.com/adobe-platform/go-metronome/metronome-cli/cli_support"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Calendar", func() {
+	Describe("calendarICS", func() {
+		at := time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC)
+
+		It("Folds content lines at 75 octets without splitting characters", func() {
+			firing := met.Firing{JobID: "reports.nightly-with-a-rather-long-job-id", ScheduleID: "nachtlauf-übersicht-ß", At: at, Timezone: "Europe/Berlin", Cpus: 0.5, Mem: 128}
+			ics := cli.CalendarICS([]met.Firing{firing}, at)
+			Expect(ics).To(HaveSuffix("END:VCALENDAR\r\n"))
+			var unfolded []string
+			for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+				Expect(len(line)).To(BeNumerically("<=", 75), line)
+				Expect(utf8.ValidString(line)).To(BeTrue(), line)
+				if strings.HasPrefix(line, " ") {
+					unfolded[len(unfolded)-1] += line[1:]
+				} else {
+					unfolded = append(unfolded, line)
+				}
+			}
+			Expect(unfolded).To(ContainElement("SUMMARY:reports.nightly-with-a-rather-long-job-id (nachtlauf-übersicht-ß)"))
+			Expect(unfolded).To(ContainElement(`DESCRIPTION:cpus 0.5\, mem 128 MB\, disk 0 MB\, schedule time zone Europe/Berlin`))
+		})
+
+		It("Leaves short lines alone", func() {
+			ics := cli.CalendarICS(nil, at)
+			Expect(ics).To(Equal("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//go-metronome//schedule calendar//EN\r\nCALSCALE:GREGORIAN\r\nEND:VCALENDAR\r\n"))
+		})
+	})
+})
@@ -8,3 +8,9 @@ const (
 	DefaultMemory   = 128
 	DefaultDisk     = 128
 )
+
+// Container runtimes accepted by --runtime
+const (
+	RuntimeDocker = "docker"
+	RuntimeUcr    = "ucr"
+)
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"errors"
+	"flag"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -36,6 +37,113 @@ func (i *RunArgs) Set(value string) error {
 	return nil
 }
 
+// PathList - thin type providing Flags Value implementation for repeated job field paths e.g. --unset run.env.FOO
+type PathList []string
+
+// String - Value interface implementation
+func (list *PathList) String() string {
+	return fmt.Sprintf("%v", *list)
+}
+
+// Set - Value interface implementation
+func (list *PathList) Set(value string) error {
+	if value == "" {
+		return errors.New("path required")
+	}
+	*list = append(*list, value)
+	return nil
+}
+
+// LabelSelector - thin type providing Flags Value implementation for met.Selector
+//  e.g. --selector 'owner=zeus,env!=dev,tier in (batch,etl)'.  Repeating the flag adds requirements
+type LabelSelector met.Selector
+
+// String - Value interface implementation
+func (selector *LabelSelector) String() string {
+	return met.Selector(*selector).String()
+}
+
+// Set - Value interface implementation
+func (selector *LabelSelector) Set(value string) error {
+	parsed, err := met.ParseSelector(value)
+	if err != nil {
+		return err
+	}
+	if len(parsed) == 0 {
+		return errors.New("selector requires at least one requirement")
+	}
+	*selector = append(*selector, parsed...)
+	return nil
+}
+
+// Matches - the labels meet every requirement.  An empty selector matches every job
+func (selector LabelSelector) Matches(labels *met.Labels) bool {
+	return met.Selector(selector).Matches(labels)
+}
+
+// BulkSelector - --selector and --parallelism for commands that act on every job matching a selector
+type BulkSelector struct {
+	selector    LabelSelector
+	parallelism int
+}
+
+// FlagSet - selector and parallelism.  `what` says what the selector picks
+func (bulk *BulkSelector) FlagSet(flags *flag.FlagSet, what string) *flag.FlagSet {
+	flags.Var(&bulk.selector, "selector", "Label selector e.g. 'owner=zeus,env!=dev,tier in (batch,etl),!deprecated' . "+what)
+	flags.IntVar(&bulk.parallelism, "parallelism", met.DefaultParallelism, "Jobs acted on at once with --selector")
+	return flags
+}
+
+// Selected - a selector was given
+func (bulk *BulkSelector) Selected() bool {
+	return len(bulk.selector) > 0
+}
+
+// Jobs - the matching jobs
+func (bulk *BulkSelector) Jobs(runtime *Runtime) ([]met.Job, error) {
+	return met.SelectJobs(runtime.client, met.Selector(bulk.selector))
+}
+
+// ForEach - call action for each matching job on a bounded pool of workers.
+//  Returns a result per job; when some fail the results are returned with an error so every outcome is printed
+func (bulk *BulkSelector) ForEach(runtime *Runtime, action func(jobID string) (interface{}, error)) (interface{}, error) {
+	jobs, err := bulk.Jobs(runtime)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.ID)
+	}
+	results, failed := met.ForEachJob(ids, bulk.parallelism, action)
+	return results, met.BulkError(results, failed)
+}
+
+// PathValue - a job field path and the value to set there
+type PathValue struct {
+	Path  string
+	Value string
+}
+
+// PathValueList - thin type providing Flags Value implementation for repeated path=value e.g. --set run.cpus=2
+//  Keeps command line order; the value may itself contain '='
+type PathValueList []PathValue
+
+// String - Value interface implementation
+func (list *PathValueList) String() string {
+	return fmt.Sprintf("%v", *list)
+}
+
+// Set - Value interface implementation
+func (list *PathValueList) Set(value string) error {
+	pv := strings.SplitN(value, "=", 2)
+	if len(pv) != 2 || strings.TrimSpace(pv[0]) == "" {
+		return errors.New("Expected path=value")
+	}
+	*list = append(*list, PathValue{Path: strings.TrimSpace(pv[0]), Value: pv[1]})
+	return nil
+}
+
 // type override to support parsing.  env alias' map[string]string
 // It implements flag.Value via Set/String
 
@@ -67,7 +175,7 @@ type ConstraintList []met.Constraint
 
 // String - Value interface implementation
 func (list *ConstraintList) String() string {
-	return fmt.Sprintf("%s", *list)
+	return fmt.Sprintf("%v", *list)
 }
 
 // Set - Value interface definition used with Flags
@@ -111,7 +219,7 @@ type ArtifactList []met.Artifact
 
 // String - Value interface implementation
 func (list *ArtifactList) String() string {
-	return fmt.Sprintf("%s", *list)
+	return fmt.Sprintf("%v", *list)
 }
 
 // Set - Value interface implemention
@@ -154,3 +262,45 @@ func (list *ArtifactList) Set(value string) (err error) {
 
 	return nil
 }
+
+// NetworkList - thin type providing Flags Value interface implementation for Metronome networks
+type NetworkList []met.Network
+
+// String - Value interface implementation
+func (list *NetworkList) String() string {
+	return fmt.Sprintf("%v", *list)
+}
+
+// Set - Value interface implementation.  Takes `mode` or `mode:name`
+func (list *NetworkList) Set(value string) error {
+	pieces := strings.SplitN(value, ":", 2)
+	var name string
+	if len(pieces) == 2 {
+		name = pieces[1]
+	}
+	network, err := met.NewNetwork(pieces[0], name)
+	if err != nil {
+		return err
+	}
+	*list = append(*list, *network)
+	return nil
+}
+
+// DockerParamList - thin type providing Flags Value interface implementation for Metronome docker parameters
+//  keys may repeat so a map won't do
+type DockerParamList []met.DockerParameter
+
+// String - Value interface implementation
+func (list *DockerParamList) String() string {
+	return fmt.Sprintf("%v", *list)
+}
+
+// Set - Value interface implementation.  Takes `key=value`
+func (list *DockerParamList) Set(value string) error {
+	kv := strings.SplitN(value, "=", 2)
+	if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+		return errors.New("Docker parameters should be KEY=VALUE")
+	}
+	*list = append(*list, met.DockerParameter{Key: strings.TrimSpace(kv[0]), Value: kv[1]})
+	return nil
+}
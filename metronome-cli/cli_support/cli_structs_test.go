@@ -0,0 +1,52 @@
+package cli_test
+
+import (
+	"net/http"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	cli "github.com/adobe-platform/go-metronome/metronome-cli/cli_support"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("BulkSelector", func() {
+	var (
+		server  *ghttp.Server
+		runtime *cli.Runtime
+	)
+	jsonHeader := http.Header{"Content-Type": {"application/json"}}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.AppendHandlers(ghttp.VerifyRequest("GET", "/v1/jobs"))
+		client, err := met.NewClient(met.Config{URL: server.URL(), RequestTimeout: 5})
+		Expect(err).NotTo(HaveOccurred())
+		runtime = cli.NewTestRuntime(client)
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", "/v1/jobs"),
+			ghttp.RespondWith(http.StatusOK, `[{"id":"a","labels":{"team":"x"}},{"id":"b","labels":{"team":"x"}},{"id":"c","labels":{"team":"y"}}]`, jsonHeader),
+		))
+		server.RouteToHandler("POST", "/v1/jobs/a/runs", ghttp.RespondWith(http.StatusCreated, `{"id":"run-a","jobId":"a"}`, jsonHeader))
+		server.RouteToHandler("POST", "/v1/jobs/b/runs", ghttp.RespondWith(http.StatusNotFound, `{"message":"nope"}`, jsonHeader))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Returns every job's outcome along with the error when some fail", func() {
+		exec, err := new(cli.RunStartJob).Parse([]string{"--selector", "team=x"})
+		Expect(err).NotTo(HaveOccurred())
+		result, err := exec.Execute(runtime)
+		Expect(err).To(MatchError("1 of 2 jobs failed"))
+		Expect(cli.HasResult(result)).To(BeTrue())
+		results := result.([]met.BulkResult)
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].JobID).To(Equal("a"))
+		Expect(results[0].Error).To(BeEmpty())
+		Expect(results[1].JobID).To(Equal("b"))
+		Expect(results[1].Error).NotTo(BeEmpty())
+	})
+})
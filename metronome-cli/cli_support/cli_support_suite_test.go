@@ -0,0 +1,13 @@
+package cli_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestCliSupport(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CliSupport Suite")
+}
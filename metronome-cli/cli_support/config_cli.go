@@ -0,0 +1,372 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+)
+
+// ConfigTopLevel - top level cli menu for the config file's contexts
+//  Implements CommandParse.  Every action is local: none talks to metronome
+type ConfigTopLevel JobTopLevel
+
+// Usage - CommandParse implementation
+func (theConfig *ConfigTopLevel) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "config {get-contexts|current-context|use-context|set-context|delete-context} <options>:\n")
+	fmt.Fprintln(writer, `
+	  get-contexts               | List the contexts of the config file
+	  current-context            | The context in use
+	  use-context NAME           | Make NAME the current context
+	  set-context NAME <options> | Create NAME or change the given settings of it
+	  delete-context NAME        | Remove NAME
+
+	  The config file is $METRONOME_CONFIG, else $XDG_CONFIG_HOME/metronome/config.yaml, else ~/.config/metronome/config.yaml
+	  Global flags override METRONOME_URL, METRONOME_TOKEN, METRONOME_USER, METRONOME_PASSWORD, METRONOME_CONTEXT,
+	  METRONOME_INSECURE_SKIP_TLS_VERIFY, METRONOME_CA_FILE and METRONOME_OUTPUT which override the context
+	`)
+}
+
+// Parse - parse the top level `config <action>` menu
+func (theConfig *ConfigTopLevel) Parse(args []string) (exec CommandExec, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			fmt.Fprintln(buf, r.(error).Error())
+			fmt.Fprintf(buf, "\n %s usage:\n", theConfig.subcommand)
+			if theConfig.task != nil {
+				theConfig.task.Usage(buf)
+			}
+			theConfig.Usage(buf)
+			err = errors.New(buf.String())
+		}
+	}()
+	if len(args) == 0 {
+		panic(errors.New("sub command required"))
+	}
+	theConfig.subcommand = args[0]
+	switch theConfig.subcommand {
+	case "get-contexts":
+		theConfig.task = CommandParse(new(ConfigGetContexts))
+	case "current-context":
+		theConfig.task = CommandParse(new(ConfigCurrentContext))
+	case "use-context":
+		theConfig.task = CommandParse(new(ConfigUseContext))
+	case "set-context":
+		theConfig.task = CommandParse(new(ConfigSetContext))
+	case "delete-context":
+		theConfig.task = CommandParse(new(ConfigDeleteContext))
+	case "help", "--help":
+		theConfig.Usage(os.Stderr)
+		return nil, errors.New("config usage")
+	default:
+		return nil, fmt.Errorf("config: unknown action '%s'", theConfig.subcommand)
+	}
+	var subcommandArgs []string
+	if len(args) > 1 {
+		subcommandArgs = args[1:]
+	}
+	if exec, err = theConfig.task.Parse(subcommandArgs); err != nil {
+		panic(err)
+	}
+	return exec, nil
+}
+
+// contextName - the NAME argument, before or after the flags
+func contextName(flags *flag.FlagSet, args []string) (string, error) {
+	name := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if err := flags.Parse(args); err != nil {
+		return "", err
+	}
+	if name == "" {
+		name = flags.Arg(0)
+	}
+	if name == "" {
+		return "", errors.New("context NAME required")
+	}
+	return name, nil
+}
+
+// ContextSummary - a context without its credentials, for get-contexts
+type ContextSummary struct {
+	Current  bool     `json:"current"`
+	Name     string   `json:"name"`
+	URLs     []string `json:"urls"`
+	Auth     string   `json:"auth"`
+	Insecure bool     `json:"insecureSkipTlsVerify,omitempty"`
+	CAFile   string   `json:"caFile,omitempty"`
+	Output   string   `json:"output,omitempty"`
+	Expires  string   `json:"tokenExpiry,omitempty"`
+}
+
+// summarize - the context as get-contexts shows it
+func summarize(ctx *Context, current string) ContextSummary {
+	auth := ctx.Auth
+	if auth == "" {
+		switch {
+		case ctx.Token != "":
+			auth = AuthToken
+		case ctx.User != "":
+			auth = AuthBasic
+		default:
+			auth = AuthNone
+		}
+	}
+	return ContextSummary{Current: ctx.Name == current, Name: ctx.Name, URLs: ctx.URLs, Auth: auth, Insecure: ctx.Insecure, CAFile: ctx.CAFile, Output: ctx.Output, Expires: ctx.TokenExpiry}
+}
+
+// ConfigGetContexts - list the contexts
+//  - Implements CommandParse/CommandExec/CommandLocal
+type ConfigGetContexts int
+
+// Usage - CommandParse implementation
+func (theConfig *ConfigGetContexts) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "config get-contexts\n\tList the contexts; * marks the current one\n")
+}
+
+// Parse - nothing to parse
+func (theConfig *ConfigGetContexts) Parse([]string) (CommandExec, error) {
+	return theConfig, nil
+}
+
+// Execute - the contexts without their credentials
+func (theConfig *ConfigGetContexts) Execute(runtime *Runtime) (interface{}, error) {
+	contexts := make([]ContextSummary, 0, len(runtime.cliConfig.Contexts))
+	for _, ctx := range runtime.cliConfig.Contexts {
+		contexts = append(contexts, summarize(ctx, runtime.cliConfig.CurrentContext))
+	}
+	return contexts, nil
+}
+
+// Local - CommandLocal implementation
+func (theConfig *ConfigGetContexts) Local() bool {
+	return true
+}
+
+// ConfigCurrentContext - the name of the current context
+//  - Implements CommandParse/CommandExec/CommandLocal
+type ConfigCurrentContext int
+
+// Usage - CommandParse implementation
+func (theConfig *ConfigCurrentContext) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "config current-context\n\tThe current context's name\n")
+}
+
+// Parse - nothing to parse
+func (theConfig *ConfigCurrentContext) Parse([]string) (CommandExec, error) {
+	return theConfig, nil
+}
+
+// Execute - the name; an error when there is none
+func (theConfig *ConfigCurrentContext) Execute(runtime *Runtime) (interface{}, error) {
+	if runtime.cliConfig.CurrentContext == "" {
+		return nil, fmt.Errorf("no current context in %s", runtime.configFile)
+	}
+	return runtime.cliConfig.CurrentContext, nil
+}
+
+// Local - CommandLocal implementation
+func (theConfig *ConfigCurrentContext) Local() bool {
+	return true
+}
+
+// ConfigUseContext - make a context the current one
+//  - Implements CommandParse/CommandExec/CommandLocal
+type ConfigUseContext struct {
+	name string
+}
+
+// Usage - CommandParse implementation
+func (theConfig *ConfigUseContext) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "config use-context NAME\n\tUse context NAME from now on\n")
+}
+
+// Parse - the context name
+func (theConfig *ConfigUseContext) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("config use-context", flag.ContinueOnError)
+	if theConfig.name, err = contextName(flags, args); err != nil {
+		return nil, err
+	}
+	return theConfig, nil
+}
+
+// Execute - set current-context and save the config file
+func (theConfig *ConfigUseContext) Execute(runtime *Runtime) (interface{}, error) {
+	if runtime.cliConfig.Context(theConfig.name) == nil {
+		return nil, fmt.Errorf("context %s not found in %s", theConfig.name, runtime.configFile)
+	}
+	runtime.cliConfig.CurrentContext = theConfig.name
+	if err := runtime.cliConfig.Save(runtime.configFile); err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("switched to context %s", theConfig.name), nil
+}
+
+// Local - CommandLocal implementation
+func (theConfig *ConfigUseContext) Local() bool {
+	return true
+}
+
+// ConfigSetContext - create a context or change the settings given on the command line
+//  - Implements CommandParse/CommandExec/CommandLocal
+type ConfigSetContext struct {
+	name    string
+	set     map[string]bool
+	context Context
+	urls    string
+	current bool
+}
+
+// FlagSet - one flag per context setting
+func (theConfig *ConfigSetContext) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar(&theConfig.urls, "url", "", "Metronome url.  Several comma separated are tried in order")
+	flags.StringVar(&theConfig.context.Auth, "auth", "", "How to authenticate: none, token, basic or login (a token cached by the login command)")
+	flags.StringVar(&theConfig.context.Token, "token", "", "Authorization token for --auth token")
+	flags.StringVar(&theConfig.context.User, "user", "", "User for --auth basic")
+	flags.StringVar(&theConfig.context.Password, "password", "", "Password for --auth basic")
+	flags.BoolVar(&theConfig.context.Insecure, "insecure-skip-tls-verify", false, "Don't verify Metronome's tls certificate")
+	flags.StringVar(&theConfig.context.CAFile, "ca-file", "", "PEM certificates to verify Metronome's tls certificate with")
+	flags.StringVar(&theConfig.context.Output, "output", "", "Default output format e.g. wide")
+	flags.StringVar(&theConfig.context.LoginURL, "login-url", "", "Login endpoint for --auth login.  Default the cluster's "+met.ACSLoginPath)
+	flags.BoolVar(&theConfig.current, "use", false, "Also make it the current context")
+	return flags
+}
+
+// Usage - CommandParse implementation
+func (theConfig *ConfigSetContext) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "config set-context NAME <options>\n\tOnly the given settings change\n")
+	flags := flag.NewFlagSet("config set-context", flag.ExitOnError)
+	theConfig.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - the name and the settings given
+func (theConfig *ConfigSetContext) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("config set-context", flag.ContinueOnError)
+	theConfig.FlagSet(flags)
+	flags.SetOutput(new(bytes.Buffer))
+	if theConfig.name, err = contextName(flags, args); err != nil {
+		return nil, err
+	}
+	theConfig.set = make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { theConfig.set[f.Name] = true })
+	return theConfig, nil
+}
+
+// apply - the given settings over `ctx`
+func (theConfig *ConfigSetContext) apply(ctx *Context) {
+	given := theConfig.context
+	if theConfig.set["url"] {
+		ctx.URLs = nil
+		for _, url := range strings.Split(theConfig.urls, ",") {
+			if url = strings.TrimSpace(url); url != "" {
+				ctx.URLs = append(ctx.URLs, url)
+			}
+		}
+	}
+	if theConfig.set["auth"] {
+		ctx.Auth = given.Auth
+	}
+	if theConfig.set["token"] {
+		ctx.Token = given.Token
+	}
+	if theConfig.set["user"] {
+		ctx.User = given.User
+	}
+	if theConfig.set["password"] {
+		ctx.Password = given.Password
+	}
+	if theConfig.set["insecure-skip-tls-verify"] {
+		ctx.Insecure = given.Insecure
+	}
+	if theConfig.set["ca-file"] {
+		ctx.CAFile = given.CAFile
+	}
+	if theConfig.set["output"] {
+		ctx.Output = given.Output
+	}
+	if theConfig.set["login-url"] {
+		ctx.LoginURL = given.LoginURL
+	}
+}
+
+// Execute - update or add the context and save the config file.  Returns the context without credentials
+func (theConfig *ConfigSetContext) Execute(runtime *Runtime) (interface{}, error) {
+	ctx := &Context{Name: theConfig.name}
+	if have := runtime.cliConfig.Context(theConfig.name); have != nil {
+		copied := *have
+		ctx = &copied
+	}
+	theConfig.apply(ctx)
+	if err := ctx.Validate(); err != nil {
+		return nil, err
+	}
+	runtime.cliConfig.SetContext(ctx)
+	if theConfig.current {
+		runtime.cliConfig.CurrentContext = ctx.Name
+	}
+	if err := runtime.cliConfig.Save(runtime.configFile); err != nil {
+		return nil, err
+	}
+	return []ContextSummary{summarize(ctx, runtime.cliConfig.CurrentContext)}, nil
+}
+
+// Local - CommandLocal implementation
+func (theConfig *ConfigSetContext) Local() bool {
+	return true
+}
+
+// ConfigDeleteContext - remove a context
+//  - Implements CommandParse/CommandExec/CommandLocal
+type ConfigDeleteContext struct {
+	name string
+}
+
+// Usage - CommandParse implementation
+func (theConfig *ConfigDeleteContext) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "config delete-context NAME\n\tRemove context NAME.  There is no current context after removing it\n")
+}
+
+// Parse - the context name
+func (theConfig *ConfigDeleteContext) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("config delete-context", flag.ContinueOnError)
+	if theConfig.name, err = contextName(flags, args); err != nil {
+		return nil, err
+	}
+	return theConfig, nil
+}
+
+// Execute - remove the context and save the config file
+func (theConfig *ConfigDeleteContext) Execute(runtime *Runtime) (interface{}, error) {
+	contexts := make([]*Context, 0, len(runtime.cliConfig.Contexts))
+	for _, ctx := range runtime.cliConfig.Contexts {
+		if ctx.Name != theConfig.name {
+			contexts = append(contexts, ctx)
+		}
+	}
+	if len(contexts) == len(runtime.cliConfig.Contexts) {
+		return nil, fmt.Errorf("context %s not found in %s", theConfig.name, runtime.configFile)
+	}
+	runtime.cliConfig.Contexts = contexts
+	if runtime.cliConfig.CurrentContext == theConfig.name {
+		runtime.cliConfig.CurrentContext = ""
+	}
+	if err := runtime.cliConfig.Save(runtime.configFile); err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("deleted context %s", theConfig.name), nil
+}
+
+// Local - CommandLocal implementation
+func (theConfig *ConfigDeleteContext) Local() bool {
+	return true
+}
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Auth methods of a Context
+const (
+	AuthNone  = "none"
+	AuthToken = "token"
+	AuthBasic = "basic"
+	AuthLogin = "login"
+)
+
+// LoginMargin - a cached login token this close to expiring is refreshed before use
+const LoginMargin = met.RefreshMargin
+
+// Environment variables overriding the config file.  Flags override both
+const (
+	EnvConfig   = "METRONOME_CONFIG"
+	EnvContext  = "METRONOME_CONTEXT"
+	EnvURL      = "METRONOME_URL"
+	EnvToken    = "METRONOME_TOKEN"
+	EnvUser     = "METRONOME_USER"
+	EnvPassword = "METRONOME_PASSWORD"
+	EnvInsecure = "METRONOME_INSECURE_SKIP_TLS_VERIFY"
+	EnvCAFile   = "METRONOME_CA_FILE"
+	EnvOutput   = "METRONOME_OUTPUT"
+)
+
+// Context - a named cluster and how to talk to it
+type Context struct {
+	Name     string   `yaml:"name"`
+	URLs     []string `yaml:"urls,omitempty"`
+	Auth     string   `yaml:"auth,omitempty"`
+	Token    string   `yaml:"token,omitempty"`
+	User     string   `yaml:"user,omitempty"`
+	Password string   `yaml:"password,omitempty"`
+	Insecure bool     `yaml:"insecure-skip-tls-verify,omitempty"`
+	CAFile   string   `yaml:"ca-file,omitempty"`
+	Output   string   `yaml:"output,omitempty"`
+
+	LoginURL    string `yaml:"login-url,omitempty"`
+	TokenExpiry string `yaml:"token-expiry,omitempty"`
+}
+
+// LoginToken - the cached login token; nil without one
+func (ctx *Context) LoginToken() *met.LoginToken {
+	if ctx.Token == "" {
+		return nil
+	}
+	token := &met.LoginToken{Token: ctx.Token}
+	if ctx.TokenExpiry != "" {
+		token.Expires, _ = time.Parse(time.RFC3339, ctx.TokenExpiry)
+	}
+	return token
+}
+
+// SetLoginToken - cache a login token, or clear it with nil
+func (ctx *Context) SetLoginToken(token *met.LoginToken) {
+	ctx.Token, ctx.TokenExpiry = "", ""
+	if token == nil {
+		return
+	}
+	ctx.Token = token.Token
+	if !token.Expires.IsZero() {
+		ctx.TokenExpiry = token.Expires.UTC().Format(time.RFC3339)
+	}
+}
+
+// LoginEndpoint - login-url, else the ACS login endpoint of the cluster at `metronomeURL`
+func (ctx *Context) LoginEndpoint(metronomeURL string) (string, error) {
+	if ctx.LoginURL != "" {
+		return ctx.LoginURL, nil
+	}
+	return met.ACSLoginURL(metronomeURL)
+}
+
+// Validate - a name, a known auth method and a known output format
+func (ctx *Context) Validate() error {
+	if ctx.Name == "" {
+		return fmt.Errorf("context name required")
+	}
+	switch ctx.Auth {
+	case "", AuthNone, AuthToken, AuthBasic, AuthLogin:
+	default:
+		return fmt.Errorf("context %s: auth '%s' should be one of %s, %s, %s or %s", ctx.Name, ctx.Auth, AuthNone, AuthToken, AuthBasic, AuthLogin)
+	}
+	if ctx.TokenExpiry != "" {
+		if _, err := time.Parse(time.RFC3339, ctx.TokenExpiry); err != nil {
+			return fmt.Errorf("context %s: token-expiry: %s", ctx.Name, err.Error())
+		}
+	}
+	if ctx.Output != "" {
+		if _, err := NewPrinter(ctx.Output); err != nil {
+			return fmt.Errorf("context %s: %s", ctx.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// CLIConfig - the contents of the config file: contexts and the one in use
+type CLIConfig struct {
+	CurrentContext string     `yaml:"current-context,omitempty"`
+	Contexts       []*Context `yaml:"contexts"`
+}
+
+// ConfigPath - $METRONOME_CONFIG, else $XDG_CONFIG_HOME/metronome/config.yaml, else ~/.config/metronome/config.yaml
+func ConfigPath() string {
+	if file := os.Getenv(EnvConfig); file != "" {
+		return file
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			home = "."
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "metronome", "config.yaml")
+}
+
+// LoadCLIConfig - read the config file.  A missing file is an empty config
+func LoadCLIConfig(file string) (*CLIConfig, error) {
+	config := &CLIConfig{Contexts: make([]*Context, 0)}
+	raw, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return config, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = yaml.UnmarshalStrict(raw, config); err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err.Error())
+	}
+	for _, ctx := range config.Contexts {
+		if err = ctx.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err.Error())
+		}
+	}
+	return config, nil
+}
+
+// Save - write the config file readable only by its owner since it may hold credentials
+func (config *CLIConfig) Save(file string) error {
+	raw, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err = ioutil.WriteFile(tmp, raw, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// Context - the named context, nil if there is none
+func (config *CLIConfig) Context(name string) *Context {
+	for _, ctx := range config.Contexts {
+		if ctx.Name == name {
+			return ctx
+		}
+	}
+	return nil
+}
+
+// SetContext - add the context or replace the one with its name
+func (config *CLIConfig) SetContext(ctx *Context) {
+	for i, have := range config.Contexts {
+		if have.Name == ctx.Name {
+			config.Contexts[i] = ctx
+			return
+		}
+	}
+	config.Contexts = append(config.Contexts, ctx)
+}
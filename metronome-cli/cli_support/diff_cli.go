@@ -0,0 +1,308 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+)
+
+// diff output formats
+const (
+	DiffFormatUnified = "diff"
+	DiffFormatJSON    = "json"
+)
+
+// ansi colors for the unified diff
+const (
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+	colorReset = "\x1b[0m"
+)
+
+// diffContext - unchanged lines shown around each change
+const diffContext = 3
+
+// JobDiff - show what `job update --file` would change
+//  - Implements CommandParse/CommandExec
+//  - GET /v1/jobs/$jobId?embed=schedules
+type JobDiff struct {
+	JobID
+	file    string
+	overlay string
+	format  string
+	color   bool
+	noColor bool
+	job     *met.Job
+}
+
+// FlagSet - diff flags
+func (theJob *JobDiff) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar((*string)(&theJob.JobID), "job-id", "", "Job Id to compare with.  Defaults to the id in --file")
+	flags.StringVar(&theJob.file, "file", "", "Job spec file, json or yaml, '-' for stdin")
+	flags.StringVar(&theJob.overlay, "overlay", "", "NAME of the overlay in the spec's overlays member to merge over the base spec e.g. prod")
+	flags.StringVar(&theJob.format, "format", DiffFormatUnified, "Output format: diff (unified diff of the normalized specs) or json (list of changes by field path)")
+	flags.BoolVar(&theJob.color, "color", false, "Color the unified diff even when stdout isn't a terminal")
+	flags.BoolVar(&theJob.noColor, "no-color", false, "Don't color the unified diff.  By default it is colored only on a terminal")
+	return flags
+}
+
+// Validate - a spec file and a known format are required
+func (theJob *JobDiff) Validate() error {
+	if theJob.file == "" {
+		return errors.New("file required")
+	}
+	if theJob.format != DiffFormatUnified && theJob.format != DiffFormatJSON {
+		return fmt.Errorf("format must be %s or %s", DiffFormatUnified, DiffFormatJSON)
+	}
+	return nil
+}
+
+// Usage - CommandParse implementation
+func (theJob *JobDiff) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("job diff", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - read the spec file
+func (theJob *JobDiff) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("job diff", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theJob.Validate(); err != nil {
+		panic(err)
+	}
+	if theJob.job, err = readJobSpec(theJob.file, theJob.overlay); err != nil {
+		return nil, err
+	}
+	if theJob.JobID == "" {
+		theJob.JobID = JobID(theJob.job.ID)
+	}
+	if theJob.JobID == "" {
+		return nil, errors.New("job-id required when the spec has no id")
+	}
+	theJob.job.ID = string(theJob.JobID)
+	return theJob, nil
+}
+
+// Execute - compare the live job with the spec file.  Prints nothing (or `[]`) when they are equivalent
+func (theJob *JobDiff) Execute(runtime *Runtime) (interface{}, error) {
+	live, err := runtime.client.GetJob(string(theJob.JobID))
+	if err != nil {
+		return nil, err
+	}
+	live.Schedules = updatedSchedules(live.Schedules, theJob.job.Schedules)
+	spec, err := expandedJob(theJob.job)
+	if err != nil {
+		return nil, err
+	}
+	if theJob.format == DiffFormatJSON {
+		changes, err := met.DiffJobs(live, spec)
+		if err != nil {
+			return nil, err
+		}
+		if changes == nil {
+			changes = []met.Change{}
+		}
+		out, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return Text(string(out) + "\n"), nil
+	}
+	before, err := met.NormalizedJob(live)
+	if err != nil {
+		return nil, err
+	}
+	after, err := met.NormalizedJob(spec)
+	if err != nil {
+		return nil, err
+	}
+	color := theJob.color || !theJob.noColor && stdoutIsTerminal()
+	return Text(unifiedDiff("live/"+string(theJob.JobID), theJob.file, string(before), string(after), color)), nil
+}
+
+// updatedSchedules - the live schedules `job update --file` would touch: those the spec names.  Others are left alone
+//  by update (see syncSchedules) so they aren't shown as removed
+func updatedSchedules(live []*met.Schedule, spec []*met.Schedule) []*met.Schedule {
+	named := make(map[string]bool, len(spec))
+	for _, sched := range spec {
+		named[sched.ID] = true
+	}
+	var kept []*met.Schedule
+	for _, sched := range live {
+		if named[sched.ID] {
+			kept = append(kept, sched)
+		}
+	}
+	return kept
+}
+
+// expandedJob - a copy of the spec with H tokens in its schedules expanded for the job, as update would create them
+func expandedJob(job *met.Job) (*met.Job, error) {
+	expanded := *job
+	expanded.Schedules = make([]*met.Schedule, 0, len(job.Schedules))
+	for _, sched := range job.Schedules {
+		sched, err := sched.Expanded(job.ID)
+		if err != nil {
+			return nil, err
+		}
+		expanded.Schedules = append(expanded.Schedules, sched)
+	}
+	return &expanded, nil
+}
+
+// stdoutIsTerminal - whether output is read by a person rather than a pipe or file
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// hunkRange - the unified diff `start,count` of a hunk side.  An empty side names the line before it, e.g. -0,0
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		start--
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// lineOp - one line of an edit script: ' ' kept, '-' removed, '+' added
+type lineOp struct {
+	op   byte
+	text string
+}
+
+// editScript - longest common subsequence of the lines.  Job specs are small so the quadratic table is fine
+func editScript(a []string, b []string) []lineOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var ops []lineOp
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, lineOp{' ', a[i]})
+			i++
+			j++
+		case j >= len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, lineOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, lineOp{'+', b[j]})
+			j++
+		}
+	}
+	return ops
+}
+
+// splitLines - the lines of `text`, none for empty text
+func splitLines(text string) []string {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// unifiedDiff - `diff -u` style output taking `before` to `after`, empty when they are the same
+func unifiedDiff(fromName string, toName string, before string, after string, color bool) string {
+	ops := editScript(splitLines(before), splitLines(after))
+	paint := func(code string, line string) string {
+		if !color {
+			return line
+		}
+		return code + line + colorReset
+	}
+	buf := new(bytes.Buffer)
+	// line numbers (1 based) in before/after at the start of ops[k]
+	aLine := make([]int, len(ops)+1)
+	bLine := make([]int, len(ops)+1)
+	aLine[0], bLine[0] = 1, 1
+	for k, op := range ops {
+		aLine[k+1], bLine[k+1] = aLine[k], bLine[k]
+		if op.op != '+' {
+			aLine[k+1]++
+		}
+		if op.op != '-' {
+			bLine[k+1]++
+		}
+	}
+	for k := 0; k < len(ops); {
+		if ops[k].op == ' ' {
+			k++
+			continue
+		}
+		// grow the hunk while the next change is within 2*context kept lines
+		start := k - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := k
+		for end < len(ops) {
+			if ops[end].op != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].op == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContext {
+				end += diffContext
+				if end > next {
+					end = next
+				}
+				break
+			}
+			end = next
+		}
+		if buf.Len() == 0 {
+			fmt.Fprintln(buf, paint(colorRed, "--- "+fromName))
+			fmt.Fprintln(buf, paint(colorGreen, "+++ "+toName))
+		}
+		fmt.Fprintln(buf, paint(colorCyan, fmt.Sprintf("@@ -%s +%s @@", hunkRange(aLine[start], aLine[end]-aLine[start]), hunkRange(bLine[start], bLine[end]-bLine[start]))))
+		for _, op := range ops[start:end] {
+			line := string(op.op) + op.text
+			switch op.op {
+			case '-':
+				line = paint(colorRed, line)
+			case '+':
+				line = paint(colorGreen, line)
+			}
+			fmt.Fprintln(buf, line)
+		}
+		k = end
+	}
+	return buf.String()
+}
@@ -0,0 +1,107 @@
+package cli_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	cli "github.com/adobe-platform/go-metronome/metronome-cli/cli_support"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Job diff", func() {
+	Describe("unifiedDiff", func() {
+		It("Prints nothing for equal text", func() {
+			Expect(cli.UnifiedDiff("a", "b", "x\ny\n", "x\ny\n", false)).To(BeEmpty())
+		})
+
+		It("Numbers hunks like diff -u", func() {
+			out := cli.UnifiedDiff("a", "b", "1\n2\n3\n4\n5\n", "1\n2\n3\nfour\n5\n", false)
+			Expect(out).To(Equal("--- a\n+++ b\n@@ -1,5 +1,5 @@\n 1\n 2\n 3\n-4\n+four\n 5\n"))
+		})
+
+		It("Names the line before an empty side", func() {
+			Expect(cli.UnifiedDiff("a", "b", "", "x\ny\n", false)).To(ContainSubstring("@@ -0,0 +1,2 @@"))
+			Expect(cli.UnifiedDiff("a", "b", "x\ny\n", "", false)).To(ContainSubstring("@@ -1,2 +0,0 @@"))
+		})
+
+		It("Colors only when asked", func() {
+			Expect(cli.UnifiedDiff("a", "b", "x\n", "y\n", false)).NotTo(ContainSubstring("\x1b["))
+			Expect(cli.UnifiedDiff("a", "b", "x\n", "y\n", true)).To(ContainSubstring("\x1b[31m-x\x1b[0m"))
+		})
+	})
+
+	Describe("updatedSchedules", func() {
+		It("Keeps only the live schedules the spec names, as update does", func() {
+			live := []*met.Schedule{{ID: "nightly"}, {ID: "manual"}}
+			Expect(cli.UpdatedSchedules(live, []*met.Schedule{{ID: "nightly"}, {ID: "new"}})).To(Equal([]*met.Schedule{{ID: "nightly"}}))
+			Expect(cli.UpdatedSchedules(live, nil)).To(BeEmpty())
+		})
+	})
+
+	Describe("Execute", func() {
+		var (
+			server  *ghttp.Server
+			runtime *cli.Runtime
+			file    string
+		)
+		jsonHeader := http.Header{"Content-Type": {"application/json"}}
+		spec := `{"id":"report","run":{"cmd":"true","cpus":1,"mem":64,"disk":0},"schedules":[{"id":"hourly","cron":"H H(0-5) * * *"}]}`
+
+		diff := func(format string) string {
+			exec, err := new(cli.JobDiff).Parse([]string{"--file", file, "--format", format, "--no-color"})
+			Expect(err).NotTo(HaveOccurred())
+			out, err := exec.Execute(runtime)
+			Expect(err).NotTo(HaveOccurred())
+			return string(out.(cli.Text))
+		}
+		serveLive := func(cron string) {
+			live := `{"id":"report","run":{"cmd":"true","cpus":1,"mem":64,"disk":0},"schedules":[{"id":"hourly","cron":"` + cron + `"}]}`
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs/report"),
+				ghttp.RespondWith(http.StatusOK, live, jsonHeader),
+			))
+		}
+
+		BeforeEach(func() {
+			dir, err := ioutil.TempDir("", "diff")
+			Expect(err).NotTo(HaveOccurred())
+			file = filepath.Join(dir, "report.json")
+			Expect(ioutil.WriteFile(file, []byte(spec), 0600)).To(Succeed())
+
+			server = ghttp.NewServer()
+			server.AppendHandlers(ghttp.VerifyRequest("GET", "/v1/jobs"))
+			client, err := met.NewClient(met.Config{URL: server.URL(), RequestTimeout: 5})
+			Expect(err).NotTo(HaveOccurred())
+			runtime = cli.NewTestRuntime(client)
+		})
+
+		AfterEach(func() {
+			server.Close()
+			os.RemoveAll(filepath.Dir(file))
+		})
+
+		It("Shows no change for a schedule with H tokens that update wouldn't touch", func() {
+			sched, err := (&met.Schedule{ID: "hourly", Cron: "H H(0-5) * * *"}).Expanded("report")
+			Expect(err).NotTo(HaveOccurred())
+			serveLive(sched.Cron)
+			serveLive(sched.Cron)
+			Expect(diff(cli.DiffFormatUnified)).To(BeEmpty())
+			Expect(diff(cli.DiffFormatJSON)).To(Equal("[]\n"))
+		})
+
+		It("Shows the expanded cron when it changed", func() {
+			sched, err := (&met.Schedule{ID: "hourly", Cron: "H H(0-5) * * *"}).Expanded("report")
+			Expect(err).NotTo(HaveOccurred())
+			serveLive("0 12 * * *")
+			out := diff(cli.DiffFormatUnified)
+			Expect(out).To(ContainSubstring("-      \"cron\": \"0 12 * * *\""))
+			Expect(out).To(ContainSubstring("+      \"cron\": \"" + sched.Cron + "\""))
+		})
+	})
+})
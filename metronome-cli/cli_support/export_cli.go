@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	log "github.com/behance/go-logrus"
+)
+
+// import conflict modes - what to do with a job that already exists
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictFail      = "fail"
+)
+
+// JobExport - write jobs with their schedules as canonical spec files, one per job
+//  - Implements CommandParse/CommandExec
+//  - GET /v1/jobs and /v1/jobs/$jobId/schedules
+type JobExport struct {
+	JobID
+	all bool
+	dir string
+}
+
+// FlagSet - export flags
+func (theJob *JobExport) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theJob.JobID.FlagSet(flags)
+	flags.BoolVar(&theJob.all, "all", false, "Export every job")
+	flags.StringVar(&theJob.dir, "dir", "", "Directory to write <job-id>.json files to.  Created if missing")
+	return flags
+}
+
+// Validate - a directory and exactly one of --all/--job-id
+func (theJob *JobExport) Validate() error {
+	if theJob.dir == "" {
+		return errors.New("dir required")
+	}
+	if theJob.all == (theJob.JobID != "") {
+		return errors.New("one of --all or --job-id required")
+	}
+	return nil
+}
+
+// Usage - CommandParse implementation
+func (theJob *JobExport) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("job export", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - CommandParse implementation
+func (theJob *JobExport) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("job export", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theJob.Validate(); err != nil {
+		panic(err)
+	}
+	return theJob, nil
+}
+
+// Execute - write the spec files.  Returns the files written
+func (theJob *JobExport) Execute(runtime *Runtime) (interface{}, error) {
+	var jobs []*met.Job
+	if theJob.all {
+		current, err := met.CurrentJobs(runtime.client)
+		if err != nil {
+			return nil, err
+		}
+		jobs = current
+	} else {
+		job, err := runtime.client.GetJob(string(theJob.JobID))
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err := os.MkdirAll(theJob.dir, 0755); err != nil {
+		return nil, err
+	}
+	written := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		spec, err := met.CanonicalJob(job)
+		if err != nil {
+			return written, fmt.Errorf("job %s: %s", job.ID, err.Error())
+		}
+		file := filepath.Join(theJob.dir, job.ID+".json")
+		if err = ioutil.WriteFile(file, append(spec, '\n'), 0644); err != nil {
+			return written, err
+		}
+		written = append(written, file)
+	}
+	return written, nil
+}
+
+// JobImport - recreate jobs and their schedules from spec files, e.g. ones written by `job export`
+//  - Implements CommandParse/CommandExec
+//  - GET /v1/jobs then POST/PUT /v1/jobs and /v1/jobs/$jobId/schedules
+type JobImport struct {
+	dir        string
+	onConflict string
+	jobs       []*met.Job
+}
+
+// FlagSet - import flags
+func (theJob *JobImport) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar(&theJob.dir, "dir", "", "Directory of *.json, *.yaml, *.yml job specs")
+	flags.StringVar(&theJob.onConflict, "on-conflict", ConflictFail, "When a job already exists: skip it, overwrite it (schedules missing from the file are deleted) or fail before importing anything")
+	return flags
+}
+
+// Validate - a directory and a known conflict mode
+func (theJob *JobImport) Validate() error {
+	if theJob.dir == "" {
+		return errors.New("dir required")
+	}
+	switch theJob.onConflict {
+	case ConflictSkip, ConflictOverwrite, ConflictFail:
+		return nil
+	}
+	return fmt.Errorf("on-conflict must be one of %s, %s, %s", ConflictSkip, ConflictOverwrite, ConflictFail)
+}
+
+// Usage - CommandParse implementation
+func (theJob *JobImport) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("job import", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - read and validate every spec before touching metronome
+func (theJob *JobImport) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("job import", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theJob.Validate(); err != nil {
+		panic(err)
+	}
+	files, err := manifestFiles([]string{theJob.dir})
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	for _, file := range files {
+		job, err := readJobSpec(file, "")
+		if err == nil {
+			// metronome accepted these once; its checks, not the client-side ones, decide again
+			err = job.ValidateStructure()
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", file, err.Error()))
+			continue
+		}
+		theJob.jobs = append(theJob.jobs, job)
+	}
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "\n"))
+	}
+	return theJob, nil
+}
+
+// Execute - create the missing jobs and deal with existing ones per --on-conflict
+func (theJob *JobImport) Execute(runtime *Runtime) (interface{}, error) {
+	current, err := met.CurrentJobs(runtime.client)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool)
+	for _, job := range current {
+		existing[job.ID] = true
+	}
+	var desired []*met.Job
+	var conflicts, skipped []string
+	for _, job := range theJob.jobs {
+		if !existing[job.ID] || theJob.onConflict == ConflictOverwrite {
+			desired = append(desired, job)
+		} else if theJob.onConflict == ConflictSkip {
+			skipped = append(skipped, job.ID)
+		} else {
+			conflicts = append(conflicts, job.ID)
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("nothing imported, jobs already exist: %s", strings.Join(conflicts, ", "))
+	}
+	for _, id := range skipped {
+		log.Infof("skip job %s: already exists", id)
+	}
+	plan, err := met.NewPlan(desired, current, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = plan.Execute(runtime.client, func(step *met.PlanStep, err error) {
+		if err != nil {
+			log.Errorf("%s failed: %s", step, err.Error())
+		} else {
+			log.Infof("%s done", step)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("imported %d jobs, %d changes, %d skipped", len(desired), len(plan), len(skipped)), nil
+}
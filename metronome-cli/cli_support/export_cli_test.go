@@ -0,0 +1,130 @@
+package cli_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	cli "github.com/adobe-platform/go-metronome/metronome-cli/cli_support"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Job import", func() {
+	var (
+		server  *ghttp.Server
+		runtime *cli.Runtime
+		dir     string
+	)
+	jsonHeader := http.Header{"Content-Type": {"application/json"}}
+
+	verifyJob := func(id string, cmd string) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			var job met.Job
+			Expect(json.NewDecoder(req.Body).Decode(&job)).To(Succeed())
+			Expect(job.ID).To(Equal(id))
+			Expect(job.Run.Cmd).To(Equal(cmd))
+		}
+	}
+	write := func(name string, content string) {
+		Expect(ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600)).To(Succeed())
+	}
+	importWith := func(mode string) (interface{}, error) {
+		exec, err := new(cli.JobImport).Parse([]string{"--dir", dir, "--on-conflict", mode})
+		Expect(err).NotTo(HaveOccurred())
+		return exec.Execute(runtime)
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "import")
+		Expect(err).NotTo(HaveOccurred())
+		write("a.json", `{"id":"a","run":{"cmd":"new","cpus":1,"mem":64,"disk":0}}`)
+		write("b.yaml", "id: b\nrun:\n  cmd: b\n  cpus: 1\n  mem: 64\n  disk: 0\n")
+
+		server = ghttp.NewServer()
+		server.AppendHandlers(ghttp.VerifyRequest("GET", "/v1/jobs"))
+		client, err := met.NewClient(met.Config{URL: server.URL(), RequestTimeout: 5})
+		Expect(err).NotTo(HaveOccurred())
+		runtime = cli.NewTestRuntime(client)
+		// a already exists
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs"),
+				ghttp.RespondWith(http.StatusOK, `[{"id":"a","run":{"cmd":"old","cpus":1,"mem":64,"disk":0}}]`, jsonHeader),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs/a/schedules"),
+				ghttp.RespondWith(http.StatusOK, `[]`, jsonHeader),
+			),
+		)
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	It("Imports nothing with fail when a job exists", func() {
+		_, err := importWith(cli.ConflictFail)
+		Expect(err).To(MatchError("nothing imported, jobs already exist: a"))
+		Expect(server.ReceivedRequests()).To(HaveLen(3))
+	})
+
+	It("Creates only the missing jobs with skip", func() {
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("POST", "/v1/jobs"),
+			verifyJob("b", "b"),
+			ghttp.RespondWith(http.StatusCreated, `{"id":"b"}`, jsonHeader),
+		))
+		result, err := importWith(cli.ConflictSkip)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("imported 1 jobs, 1 changes, 1 skipped"))
+		Expect(server.ReceivedRequests()).To(HaveLen(4))
+	})
+
+	It("Updates existing jobs and creates the missing ones with overwrite", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/v1/jobs/a"),
+				verifyJob("a", "new"),
+				ghttp.RespondWith(http.StatusOK, `{"id":"a"}`, jsonHeader),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/v1/jobs"),
+				ghttp.RespondWith(http.StatusCreated, `{"id":"b"}`, jsonHeader),
+			),
+		)
+		result, err := importWith(cli.ConflictOverwrite)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("imported 2 jobs, 2 changes, 0 skipped"))
+		Expect(server.ReceivedRequests()).To(HaveLen(5))
+	})
+
+	It("Leaves schedule checks to metronome", func() {
+		write("b.yaml", "id: b\nrun:\n  cmd: b\n  cpus: 1\n  mem: 64\n  disk: 0\nschedules:\n- id: leap\n  cron: 0 0 29 2 *\n  timezone: Etc/Unknown\n")
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/v1/jobs"),
+				ghttp.RespondWith(http.StatusCreated, `{"id":"b"}`, jsonHeader),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/v1/jobs/b/schedules"),
+				ghttp.RespondWith(http.StatusCreated, `{"id":"leap"}`, jsonHeader),
+			),
+		)
+		_, err := importWith(cli.ConflictSkip)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Still rejects specs without an id or run before calling metronome", func() {
+		write("c.json", `{"id":"c"}`)
+		_, err := new(cli.JobImport).Parse([]string{"--dir", dir})
+		Expect(err).To(MatchError(ContainSubstring("c.json: 1 validation error(s)")))
+	})
+})
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"bufio"
+	"io"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+)
+
+// test access to unexported helpers
+var (
+	SpecJSON    = specJSON
+	ReadJobSpec = readJobSpec
+
+	UnifiedDiff      = unifiedDiff
+	UpdatedSchedules = updatedSchedules
+
+	CalendarICS = calendarICS
+)
+
+// NewTestRuntime - a runtime talking to `client`, as Connect leaves it
+func NewTestRuntime(client met.Metronome) *Runtime {
+	return &Runtime{client: client}
+}
+
+// SetStdin - where login reads the username and password from
+func SetStdin(reader io.Reader) {
+	stdin = bufio.NewReader(reader)
+}
+
+// Config - the client config Parse resolved
+func (runtime *Runtime) Config() met.Config {
+	return runtime.config
+}
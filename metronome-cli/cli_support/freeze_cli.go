@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	log "github.com/behance/go-logrus"
+)
+
+// Freeze - disable every enabled schedule across all jobs after writing a snapshot `thaw` restores from
+//  - Implements CommandParse/CommandExec
+//  - GET /v1/jobs and /v1/jobs/$jobId/schedules, PUT /v1/jobs/$jobId/schedules/$scheduleId,
+//    with --stop-runs GET /v1/jobs/$jobId/runs and POST /v1/jobs/$jobId/runs/$runId/actions/stop
+type Freeze struct {
+	snapshot string
+	stopRuns bool
+}
+
+// FlagSet - snapshot file and whether to stop active runs
+func (theFreeze *Freeze) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar(&theFreeze.snapshot, "snapshot", "", "File to write the schedules' state to.  Must not exist")
+	flags.BoolVar(&theFreeze.stopRuns, "stop-runs", false, "Also stop every active run")
+	return flags
+}
+
+// Validate - a snapshot file is required
+func (theFreeze *Freeze) Validate() error {
+	if theFreeze.snapshot == "" {
+		return errors.New("snapshot required")
+	}
+	return nil
+}
+
+// Usage - CommandParse implementation
+func (theFreeze *Freeze) Usage(writer io.Writer) {
+	fmt.Fprintln(writer, `
+freeze --snapshot FILE [--stop-runs]
+	  Disable every enabled schedule of every job.  'thaw --snapshot FILE' enables them again`)
+	flags := flag.NewFlagSet("freeze", flag.ExitOnError)
+	theFreeze.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - CommandParse implementation
+func (theFreeze *Freeze) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("freeze", flag.ExitOnError)
+	theFreeze.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theFreeze.Validate(); err != nil {
+		panic(err)
+	}
+	return theFreeze, nil
+}
+
+// Execute - snapshot, then freeze.  Returns what was disabled and stopped, also when some of it failed
+func (theFreeze *Freeze) Execute(runtime *Runtime) (interface{}, error) {
+	snap, err := met.TakeSnapshot(runtime.client)
+	if err != nil {
+		return nil, err
+	}
+	if err = snap.Save(theFreeze.snapshot); err != nil {
+		return nil, err
+	}
+	log.Infof("snapshot of %d schedules written to %s", len(snap.Schedules), theFreeze.snapshot)
+	return met.Freeze(runtime.client, snap, theFreeze.stopRuns)
+}
+
+// ThawResult - what thaw did and what no longer matched the snapshot
+type ThawResult struct {
+	Actions   []string `json:"actions"`
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+// Thaw - put back the schedules' enabled as a freeze found them
+//  - Implements CommandParse/CommandExec
+//  - GET /v1/jobs and /v1/jobs/$jobId/schedules then PUT /v1/jobs/$jobId/schedules/$scheduleId
+type Thaw struct {
+	snapshot string
+}
+
+// FlagSet - snapshot file
+func (theThaw *Thaw) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar(&theThaw.snapshot, "snapshot", "", "Snapshot written by freeze")
+	return flags
+}
+
+// Validate - a snapshot file is required
+func (theThaw *Thaw) Validate() error {
+	if theThaw.snapshot == "" {
+		return errors.New("snapshot required")
+	}
+	return nil
+}
+
+// Usage - CommandParse implementation
+func (theThaw *Thaw) Usage(writer io.Writer) {
+	fmt.Fprintln(writer, `
+thaw --snapshot FILE
+	  Enable the schedules that were enabled when FILE was written, and disable those enabled since that were not.
+	  Other changes made since are kept and reported`)
+	flags := flag.NewFlagSet("thaw", flag.ExitOnError)
+	theThaw.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - CommandParse implementation
+func (theThaw *Thaw) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("thaw", flag.ExitOnError)
+	theThaw.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theThaw.Validate(); err != nil {
+		panic(err)
+	}
+	return theThaw, nil
+}
+
+// Execute - thaw and report the conflicts.  Returns what was done, also when some of it failed
+func (theThaw *Thaw) Execute(runtime *Runtime) (interface{}, error) {
+	snap, err := met.LoadSnapshot(theThaw.snapshot)
+	if err != nil {
+		return nil, err
+	}
+	actions, conflicts, err := met.Thaw(runtime.client, snap)
+	for _, conflict := range conflicts {
+		log.Warnf("conflict %s", conflict)
+	}
+	if actions == nil {
+		return nil, err
+	}
+	return &ThawResult{Actions: actions, Conflicts: conflicts}, err
+}
@@ -4,7 +4,10 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	met "github.com/adobe-platform/go-metronome/metronome"
 	log "github.com/behance/go-logrus"
@@ -18,9 +21,21 @@ type Runtime struct {
 	Debug     bool
 	help      bool
 	client    met.Metronome
+	config    met.Config
 	authToken string
 	user      string
 	pw        string
+	output    string
+	printer   *Printer
+
+	contextName string
+	insecure    bool
+	caFile      string
+	configFile  string
+	cliConfig   *CLIConfig
+	context     *Context
+	login       bool
+	relogin     bool
 }
 
 //
@@ -30,11 +45,16 @@ type Runtime struct {
 // FlagSet - Set up the flags
 func (runtime *Runtime) FlagSet(name string) *flag.FlagSet {
 	flags := flag.NewFlagSet(name, flag.ExitOnError)
-	flags.StringVar(&runtime.httpAddr, "metronome-url", DefaultHTTPAddr, "Set the Metronome address")
+	flags.StringVar(&runtime.contextName, "context", "", "Context of the config file to use instead of its current-context")
+	flags.StringVar(&runtime.httpAddr, "metronome-url", DefaultHTTPAddr, "Set the Metronome address.  Several comma separated are tried in order")
 	flags.BoolVar(&runtime.Debug, "debug", false, "Turn on debug")
 	flags.StringVar(&runtime.authToken, "authorization", "", "Authorization token")
 	flags.StringVar(&runtime.user, "user", "", "user")
 	flags.StringVar(&runtime.pw, "password", "", "password")
+	flags.BoolVar(&runtime.insecure, "insecure-skip-tls-verify", false, "Don't verify Metronome's tls certificate")
+	flags.StringVar(&runtime.caFile, "ca-file", "", "PEM certificates to verify Metronome's tls certificate with")
+	flags.StringVar(&runtime.output, "o", OutputTable, "Output format: table, wide, json, yaml, jsonpath=TEMPLATE or go-template=TEMPLATE.  Results go to stdout, logs to stderr")
+	flags.StringVar(&runtime.output, "output", OutputTable, "Same as -o")
 	return flags
 }
 
@@ -45,14 +65,56 @@ func (runtime *Runtime) Usage(writer io.Writer) {
 	flags.PrintDefaults()
 }
 
-// Parse - Process command line arguments
+// Parse - Process command line arguments.  Each setting comes from its flag, else its METRONOME_* environment
+//  variable, else the context (--context, $METRONOME_CONTEXT or the config file's current-context), else the default
 func (runtime *Runtime) Parse(args []string) (CommandExec, error) {
 	flags := runtime.FlagSet("<global options> ")
 	if err := flags.Parse(args); err != nil {
 		return nil, err
 	}
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	runtime.configFile = ConfigPath()
+	cliConfig, err := LoadCLIConfig(runtime.configFile)
+	if err != nil {
+		return nil, err
+	}
+	runtime.cliConfig = cliConfig
+	ctx := &Context{}
+	runtime.context = ctx
+	if name := resolve(set["context"], runtime.contextName, EnvContext, cliConfig.CurrentContext); name != "" {
+		if ctx = cliConfig.Context(name); ctx == nil {
+			return nil, fmt.Errorf("context %s not found in %s", name, runtime.configFile)
+		}
+		runtime.context = ctx
+	}
+	runtime.resolveCredentials(set, ctx)
+	runtime.httpAddr = resolve(set["metronome-url"], runtime.httpAddr, EnvURL, strings.Join(ctx.URLs, ","))
+	runtime.caFile = resolve(set["ca-file"], runtime.caFile, EnvCAFile, ctx.CAFile)
+	runtime.output = resolve(set["o"] || set["output"], runtime.output, EnvOutput, ctx.Output)
+	if !set["insecure-skip-tls-verify"] {
+		runtime.insecure = ctx.Insecure
+		if value := os.Getenv(EnvInsecure); value != "" {
+			if runtime.insecure, err = strconv.ParseBool(value); err != nil {
+				return nil, fmt.Errorf("%s: %s", EnvInsecure, err.Error())
+			}
+		}
+	}
+
+	printer, err := NewPrinter(runtime.output)
+	if err != nil {
+		return nil, err
+	}
+	runtime.printer = printer
 	config := met.NewDefaultConfig()
-	config.URL = runtime.httpAddr
+	urls := strings.Split(runtime.httpAddr, ",")
+	config.URL = strings.TrimSpace(urls[0])
+	for _, other := range urls[1:] {
+		config.URLs = append(config.URLs, strings.TrimSpace(other))
+	}
+	config.AllowUnverifiedTLS = runtime.insecure
+	config.CAFile = runtime.caFile
 	if runtime.authToken != "" {
 		if strings.Contains(runtime.authToken, "token=") {
 			config.AuthToken = runtime.authToken
@@ -69,14 +131,113 @@ func (runtime *Runtime) Parse(args []string) (CommandExec, error) {
 	if runtime.Debug {
 		config.Debug = runtime.Debug
 	}
-
-	client, err := met.NewClient(config)
-	if err != nil {
-		return nil, err
+	if runtime.login {
+		if token := ctx.LoginToken(); token != nil {
+			config.TokenExpires = token.Expires
+		}
+		config.RefreshToken = runtime.refreshLogin
 	}
-	runtime.client = client
+	runtime.config = config
 
 	log.Debugf("Runtime <global flags> ok")
 	// No exec returned
 	return nil, nil
 }
+
+// resolveCredentials - token, user and password are taken together from the flags, else the environment, else the
+//  context's auth method, so a token given on the command line never mixes with a password from the config file
+func (runtime *Runtime) resolveCredentials(set map[string]bool, ctx *Context) {
+	if set["authorization"] || set["user"] || set["password"] {
+		return
+	}
+	token, user, pw := os.Getenv(EnvToken), os.Getenv(EnvUser), os.Getenv(EnvPassword)
+	if token != "" || user != "" || pw != "" {
+		runtime.authToken, runtime.user, runtime.pw = token, user, pw
+		return
+	}
+	switch ctx.Auth {
+	case AuthToken:
+		runtime.authToken = ctx.Token
+	case AuthBasic:
+		runtime.user, runtime.pw = ctx.User, ctx.Password
+	case AuthLogin:
+		runtime.useLogin(ctx)
+	case "":
+		// a token with an expiry was cached by login
+		if ctx.TokenExpiry != "" {
+			runtime.useLogin(ctx)
+			return
+		}
+		runtime.authToken, runtime.user, runtime.pw = ctx.Token, ctx.User, ctx.Password
+	}
+}
+
+// useLogin - the context's cached login token while it is valid, else log in again on Connect.  Either way the client
+//  logs in again when the token expires or is refused
+func (runtime *Runtime) useLogin(ctx *Context) {
+	runtime.login = true
+	if ctx.LoginToken().Valid(time.Now(), LoginMargin) {
+		runtime.authToken = ctx.Token
+	} else {
+		runtime.relogin = true
+	}
+}
+
+// resolve - the flag's value when it was given, else the environment variable's, else the context's, else the flag's default
+func resolve(set bool, flagValue string, env string, fromContext string) string {
+	if set {
+		return flagValue
+	}
+	if value := os.Getenv(env); value != "" {
+		return value
+	}
+	if fromContext != "" {
+		return fromContext
+	}
+	return flagValue
+}
+
+// Connect - create the metronome client from the parsed global options.
+//  Kept apart from Parse so that CommandLocal executors never need a reachable cluster
+func (runtime *Runtime) Connect() error {
+	if runtime.relogin {
+		token, err := runtime.refreshLogin()
+		if err != nil {
+			return err
+		}
+		runtime.config.AuthToken = fmt.Sprintf("token=%s", token.Token)
+		runtime.config.TokenExpires = token.Expires
+		runtime.relogin = false
+	}
+	client, err := met.NewClient(runtime.config)
+	if err != nil {
+		return err
+	}
+	runtime.client = client
+	return nil
+}
+
+// refreshLogin - log in again for a context whose cached token expired: with the context's password if it keeps
+//  one, else one typed at the terminal.  Also the client's RefreshToken
+func (runtime *Runtime) refreshLogin() (*met.LoginToken, error) {
+	ctx := runtime.context
+	password := ctx.Password
+	if password == "" {
+		if !stdinIsTerminal() || ctx.User == "" {
+			return nil, fmt.Errorf("the token of context %s expired or is missing; run 'login'", ctx.Name)
+		}
+		var err error
+		if password, err = readPassword(fmt.Sprintf("Token of context %s expired.  Password for %s: ", ctx.Name, ctx.User)); err != nil {
+			return nil, err
+		}
+	}
+	return loginAndSave(runtime, ctx, ctx.User, password)
+}
+
+// Print - write a CommandExec result to `writer` in the -o format
+func (runtime *Runtime) Print(writer io.Writer, result interface{}) error {
+	if runtime.printer == nil {
+		runtime.printer, _ = NewPrinter(OutputTable)
+	}
+	return runtime.printer.Print(writer, result)
+}
@@ -15,3 +15,13 @@ type CommandParse interface {
 	Parse(args []string) (CommandExec, error)
 	Usage(writer io.Writer)
 }
+
+// CommandLocal - optionally implemented by a CommandExec that works without talking to metronome
+//  main skips Runtime.Connect for these so they can run offline (e.g. in CI)
+type CommandLocal interface {
+	Local() bool
+}
+
+// Text - a CommandExec result main writes to stdout as is rather than logging it as json
+//  e.g. a diff meant for people or another program to read
+type Text string
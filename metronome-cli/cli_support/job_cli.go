@@ -6,6 +6,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 
 	met "github.com/adobe-platform/go-metronome/metronome"
@@ -25,15 +26,22 @@ type JobTopLevel struct {
 
 // Usage - show usage
 func (theJob *JobTopLevel) Usage(writer io.Writer) {
-	fmt.Fprintf(writer, "job {create|delete|update|ls|get|schedules|schedule|help}\n")
+	fmt.Fprintf(writer, "job {create|delete|update|patch|diff|ls|get|fingerprint|schedules|schedule|validate|render|export|import|help}\n")
 	fmt.Fprintln(writer, `
 	  create  <options>   | creates a Job
-	  delete  <options>   | deletes a Job
+	  delete  <options>   | deletes a Job or the Jobs matching a label selector
 	  update  <options>   | update a Job
+	  patch   <options>   | change some fields of a Job leaving the rest
+	  diff    <options>   | show how a Job spec file differs from the live Job
 	  get     <options>   | get a Job by job-id
+	  fingerprint <options> | hash of a Job's spec for update --if-match
 	  schedules <options> | get all schedules [] for a Job
 	  schedule  <options> | get a particular Schedule for Job
-	  ls                  | get all Jobs []
+	  ls      <options>   | get all Jobs [] or those matching a label selector
+	  validate <options>  | validate a Job spec file offline
+	  render  <options>   | print a Job spec file with its overlay merged in
+	  export  <options>   | write Jobs and their schedules to spec files
+	  import  <options>   | create Jobs and their schedules from spec files
 	  Call job <action> help for more on a sub-command
 	`)
 
@@ -74,14 +82,35 @@ func (theJob *JobTopLevel) Parse(args []string) (exec CommandExec, err error) {
 	case "get":
 		// GET /v1/jobs/$jobId
 		theJob.task = CommandParse(new(JobGet))
+	case "fingerprint":
+		// GET /v1/jobs/$jobId
+		theJob.task = CommandParse(new(JobFingerprint))
 	case "update":
 		// PUT /v1/jobs/$jobId
 		theJob.task = CommandParse(new(JobUpdate))
+	case "patch":
+		// GET then PUT /v1/jobs/$jobId
+		theJob.task = CommandParse(new(JobPatch))
+	case "diff":
+		// GET /v1/jobs/$jobId
+		theJob.task = CommandParse(new(JobDiff))
 	case "schedules":
 		// GET /v1/jobs/$jobId/schedules  []Schedule
 		theJob.task = CommandParse(new(JobScheduleList))
 	case "schedule":
 		theJob.task = CommandParse(new(JobScheduleCreate))
+	case "validate":
+		// offline - no metronome api call
+		theJob.task = CommandParse(new(JobValidate))
+	case "render":
+		// offline - no metronome api call
+		theJob.task = CommandParse(new(JobRender))
+	case "export":
+		// GET /v1/jobs/$jobId or /v1/jobs then each job's schedules
+		theJob.task = CommandParse(new(JobExport))
+	case "import":
+		// GET /v1/jobs then POST/PUT jobs and schedules
+		theJob.task = CommandParse(new(JobImport))
 	case "help", "--help":
 		theJob.Usage(os.Stderr)
 		return nil, errors.New("job usage")
@@ -114,11 +143,23 @@ type JobCreateConfig struct {
 	mem                   int
 	description           string
 	dockerImage           string
+	dockerForcePull       bool
+	dockerPrivileged      bool
+	dockerParams          DockerParamList
+	runtime               string
+	image                 string
+	imageKind             string
+	pullSecret            string
+	gpus                  int
+	networks              NetworkList
+	taskKillGracePeriod   int
 	restartPolicy         string
 	activeDeadlineSeconds int
 	constraints           ConstraintList
 	volumes               VolumeList
 	env                   NvList
+	secretEnv             NvList
+	secrets               NvList
 	labels                NvList
 	artifacts             ArtifactList
 	args                  RunArgs
@@ -128,29 +169,70 @@ type JobCreateConfig struct {
 	runNow                bool
 }
 
-// makeJob - construct a metronome job for the structure - usually populated via cli flags
-func (theJob *JobCreateConfig) makeJob() (*met.Job, error) {
-	var container *met.Docker
-	if theJob.dockerImage != "" {
-		container = &met.Docker{
-			Image: theJob.dockerImage,
+// makeContainer - the docker or ucr container described by the container flags.  Both nil without an image
+func (theJob *JobCreateConfig) makeContainer() (container *met.Docker, ucr *met.Ucr, err error) {
+	switch theJob.runtime {
+	case "", RuntimeDocker:
+		image := theJob.dockerImage
+		if image == "" {
+			image = theJob.image
+		}
+		if image != "" {
+			container = &met.Docker{
+				Image:          image,
+				ForcePullImage: theJob.dockerForcePull,
+				Privileged:     theJob.dockerPrivileged,
+				Parameters:     []met.DockerParameter(theJob.dockerParams),
+			}
+		}
+	case RuntimeUcr:
+		image := theJob.image
+		if image == "" {
+			image = theJob.dockerImage
+		}
+		if ucr, err = met.NewUcr(image, theJob.imageKind); err != nil {
+			return nil, nil, err
 		}
+		if len(theJob.dockerParams) > 0 {
+			return nil, nil, errors.New("--docker-param is not supported by --runtime ucr")
+		}
+		ucr.SetForcePull(theJob.dockerForcePull).SetPrivileged(theJob.dockerPrivileged).SetPullSecret(theJob.pullSecret)
+	default:
+		return nil, nil, fmt.Errorf("runtime must be %s or %s not %s", RuntimeDocker, RuntimeUcr, theJob.runtime)
 	}
-	run, err := met.NewRun(theJob.cpus, theJob.disk, theJob.mem)
+	return container, ucr, nil
+}
 
+// makeJob - construct a metronome job for the structure - usually populated via cli flags
+func (theJob *JobCreateConfig) makeJob() (*met.Job, error) {
+	container, ucr, err := theJob.makeContainer()
+	if err != nil {
+		return nil, err
+	}
+	run, err := met.NewRun(theJob.cpus, theJob.mem, theJob.disk)
 	if err != nil {
 		return nil, err
 	}
 	if theJob.maxLaunchDelay < 1 {
 		return nil, errors.New("max-launch-delay must be greater than 1")
 	}
-	run.SetMaxLaunchDelay(theJob.maxLaunchDelay)
+	run.SetMaxLaunchDelay(theJob.maxLaunchDelay).SetGpus(theJob.gpus).SetTaskKillGracePeriodSeconds(theJob.taskKillGracePeriod)
+	if len(theJob.networks) > 0 {
+		run.SetNetworks([]met.Network(theJob.networks))
+	}
 
 	if len(theJob.constraints) > 0 {
 		run.SetPlacement(&met.Placement{Constraints: []met.Constraint(theJob.constraints)})
 	}
-	if len(theJob.env) > 0 {
-		run.SetEnv(theJob.env)
+	for name, value := range theJob.env {
+		run.AddEnv(name, value)
+	}
+	for name, source := range theJob.secrets {
+		run.AddSecret(name, source)
+	}
+	// --secret-env declares a secret named after the variable and references it
+	for name, source := range theJob.secretEnv {
+		run.AddSecret(name, source).AddSecretEnv(name, name)
 	}
 	if len(theJob.args) > 0 {
 		run.SetArgs([]string(theJob.args))
@@ -180,18 +262,165 @@ func (theJob *JobCreateConfig) makeJob() (*met.Job, error) {
 
 	} else if container != nil {
 		newJob.GetRun().SetDocker(container).SetCmd(theJob.cmd)
+	} else if ucr != nil {
+		newJob.GetRun().SetUcr(ucr).SetCmd(theJob.cmd)
 	}
 	log.Debugf("JobCreateRuntime: %+v", theJob)
+	if err = newJob.Validate(); err != nil {
+		return nil, err
+	}
 	return newJob, nil
 
 }
 
+// overrideJob - apply the flags the user actually set (`set`, by flag name) over a job read from a spec file.
+//  Scalars replace, --env/--secret/--secret-env/--label add or replace keys, repeatable list flags replace the whole list
+func (theJob *JobCreateConfig) overrideJob(job *met.Job, set map[string]bool) error {
+	if set["job-id"] {
+		job.SetID(string(theJob.JobID))
+	}
+	if set["description"] {
+		job.SetDescription(theJob.description)
+	}
+	if len(theJob.labels) > 0 {
+		if job.Labels == nil {
+			job.SetLabel(met.Labels{})
+		}
+		for name, value := range theJob.labels {
+			(*job.Labels)[name] = value
+		}
+	}
+	if job.Run == nil {
+		job.SetRun(&met.Run{})
+	}
+	run := job.GetRun()
+	if set["cpus"] {
+		run.SetCpus(theJob.cpus)
+	}
+	if set["memory"] {
+		run.SetMem(theJob.mem)
+	}
+	if set["disk"] {
+		run.SetDisk(theJob.disk)
+	}
+	if set["gpus"] {
+		run.SetGpus(theJob.gpus)
+	}
+	if set["max-launch-delay"] {
+		run.SetMaxLaunchDelay(theJob.maxLaunchDelay)
+	}
+	if set["task-kill-grace-period"] {
+		run.SetTaskKillGracePeriodSeconds(theJob.taskKillGracePeriod)
+	}
+	if set["cmd"] {
+		run.SetCmd(theJob.cmd)
+	}
+	if set["user"] {
+		run.SetUser(theJob.user)
+	}
+	if set["arg"] {
+		run.SetArgs([]string(theJob.args))
+	}
+	if set["network"] {
+		run.SetNetworks([]met.Network(theJob.networks))
+	}
+	if set["volume"] {
+		run.SetVolumes([]met.Volume(theJob.volumes))
+	}
+	if set["artifact"] {
+		run.SetArtifacts([]met.Artifact(theJob.artifacts))
+	}
+	if set["constraint"] {
+		if run.Placement == nil {
+			run.SetPlacement(&met.Placement{})
+		}
+		run.GetPlacement().SetConstraints([]met.Constraint(theJob.constraints))
+	}
+	for name, value := range theJob.env {
+		run.AddEnv(name, value)
+	}
+	for name, source := range theJob.secrets {
+		run.AddSecret(name, source)
+	}
+	for name, source := range theJob.secretEnv {
+		run.AddSecret(name, source).AddSecretEnv(name, name)
+	}
+	if set["restart-policy"] || set["restart-active-deadline-seconds"] {
+		if run.Restart == nil {
+			run.SetRestart(&met.Restart{Policy: theJob.restartPolicy})
+		}
+		if set["restart-policy"] {
+			run.GetRestart().SetPolicy(theJob.restartPolicy)
+		}
+		if set["restart-active-deadline-seconds"] {
+			run.GetRestart().SetActiveDeadlineSeconds(theJob.activeDeadlineSeconds)
+		}
+	}
+	// a new image replaces the container; otherwise the container options tweak the one in the file
+	if set["docker-image"] || set["image"] || set["runtime"] || set["image-kind"] || set["pull-secret"] {
+		container, ucr, err := theJob.makeContainer()
+		if err != nil {
+			return err
+		}
+		if container == nil && ucr == nil {
+			return errors.New("--docker-image or --image required to change the container")
+		}
+		run.SetDocker(container).SetUcr(ucr)
+		return nil
+	}
+	if docker := run.GetDocker(); docker != nil {
+		if set["docker-force-pull"] {
+			docker.SetForcePullImage(theJob.dockerForcePull)
+		}
+		if set["docker-privileged"] {
+			docker.SetPrivileged(theJob.dockerPrivileged)
+		}
+		if set["docker-param"] {
+			docker.SetParameters([]met.DockerParameter(theJob.dockerParams))
+		}
+	} else if ucr := run.GetUcr(); ucr != nil {
+		if set["docker-force-pull"] {
+			ucr.SetForcePull(theJob.dockerForcePull)
+		}
+		if set["docker-privileged"] {
+			ucr.SetPrivileged(theJob.dockerPrivileged)
+		}
+		if set["docker-param"] {
+			return errors.New("--docker-param is not supported by a ucr container")
+		}
+	}
+	return nil
+}
+
+// jobFromFlags - theJob.job from --file with the explicitly set flags on top, or from the flags alone
+func (theJob *JobCreateRuntime) jobFromFlags(flags *flag.FlagSet) (err error) {
+	if theJob.file == "" {
+		theJob.job, err = theJob.JobCreateConfig.makeJob()
+		return err
+	}
+	if theJob.job, err = readJobSpec(theJob.file, theJob.overlay); err != nil {
+		return err
+	}
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	if err = theJob.overrideJob(theJob.job, set); err != nil {
+		return err
+	}
+	theJob.JobID = JobID(theJob.job.GetID())
+	return theJob.job.Validate()
+}
+
 // JobCreateRuntime - Entity used to create a metronome job
 //  Implements the CommandParse interface
 type JobCreateRuntime struct {
 	JobCreateConfig
 	job           *met.Job
+	file          string
+	overlay       string
 	disableRunNow bool
+	ifMatch       string
 }
 
 // FlagSet - Flags to setup creating a job.
@@ -201,25 +430,45 @@ func (theJob *JobCreateRuntime) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
 	if theJob.env == nil {
 		theJob.env = make(map[string]string)
 	}
+	if theJob.secretEnv == nil {
+		theJob.secretEnv = make(map[string]string)
+	}
+	if theJob.secrets == nil {
+		theJob.secrets = make(map[string]string)
+	}
 	if theJob.labels == nil {
 		theJob.labels = make(map[string]string)
 	}
 
 	log.Debugf("nvlist: %+v", theJob.env)
+	flags.StringVar(&theJob.file, "file", "", "Job spec file, json or yaml, '-' for stdin.  Flags given alongside override the file")
+	flags.StringVar(&theJob.overlay, "overlay", "", "NAME of the overlay in the spec's overlays member to merge over the base spec e.g. prod")
 	flags.StringVar((*string)(&theJob.JobID), "job-id", "", "Job Id")
 	flags.StringVar(&theJob.description, "description", "", "Job Description - optional")
 	flags.StringVar((*string)(&theJob.dockerImage), "docker-image", "", "Docker Image")
+	flags.BoolVar(&theJob.dockerForcePull, "docker-force-pull", false, "Pull the image on every launch.  Also applies to --runtime ucr")
+	flags.BoolVar(&theJob.dockerPrivileged, "docker-privileged", false, "Run the container privileged.  Also applies to --runtime ucr")
+	flags.StringVar(&theJob.runtime, "runtime", RuntimeDocker, "Container runtime: docker or ucr")
+	flags.StringVar(&theJob.image, "image", "", "Container image.  Same as --docker-image for --runtime docker")
+	flags.StringVar(&theJob.imageKind, "image-kind", met.ImageKindDocker, "UCR image kind: docker or appc")
+	flags.StringVar(&theJob.pullSecret, "pull-secret", "", "UCR only. Job.Run.Secrets entry holding registry credentials.  Declare it with --secret")
+	flags.Var(&theJob.dockerParams, "docker-param", "KEY=VALUE . Adds a `docker run` parameter to Job.Run.Docker.Parameters.  You can call more than once")
 	flags.Float64Var(&theJob.cpus, "cpus", DefaultCPUs, "cpus")
 	flags.IntVar(&theJob.mem, "memory", DefaultMemory, "memory")
 	flags.IntVar(&theJob.disk, "disk", DefaultDisk, "disk")
-	flags.StringVar(&theJob.restartPolicy, "restart-policy", "NEVER", "Restart policy on job failure: NEVER or ALWAYS")
+	flags.IntVar(&theJob.gpus, "gpus", 0, "gpus")
+	flags.Var(&theJob.networks, "network", "{host|container/bridge|container:NAME} . Adds a network to Job.Run.Networks.  You can call more than once")
+	flags.IntVar(&theJob.taskKillGracePeriod, "task-kill-grace-period", 0, "Seconds between SIGTERM and SIGKILL when a task is killed.  0 uses the metronome default")
+	flags.StringVar(&theJob.restartPolicy, "restart-policy", "NEVER", "Restart policy on job failure: NEVER or ON_FAILURE")
 	flags.IntVar(&theJob.activeDeadlineSeconds, "restart-active-deadline-seconds", 0, "If the job fails, how long should we try to restart the job. If no value is set, this means forever.")
 	flags.Var(&theJob.constraints, "constraint", "Add Constraint used to construct Job->Run->[]Constraint")
 	flags.Var(&theJob.volumes, "volume", "/host:/container:{RO|RW} . Adds Volume passed to metrononome->Job->Run->Volumes. You can call more than once")
 	flags.Var(&theJob.artifacts, "artifact", `uri=xxx  executable={true|false}  cache={true|false} extract={true|false} executable={true|false}
 	                                cache,extract,executable are optional.  uri is required`)
 	flags.Var(&theJob.args, "arg", "Adds Arg metrononome->Job->Run->Args. You can call more than once")
-	flags.Var(&theJob.env, "env", "VAR=VAL . Adds Volume passed on to Job.Run.[]Volumes.  You can call more than once")
+	flags.Var(&theJob.env, "env", "VAR=VAL . Adds a literal to Job.Run.Env.  You can call more than once")
+	flags.Var(&theJob.secretEnv, "secret-env", "VAR=secret/path . Adds an env var read from the secret store.  You can call more than once")
+	flags.Var(&theJob.secrets, "secret", "NAME=secret/path . Declares Job.Run.Secrets[NAME].  You can call more than once")
 	flags.Var(&theJob.labels, "label", "Location=xxx; Owner=yyy")
 	flags.StringVar(&theJob.user, "user", "root", "user to run as")
 	flags.StringVar(&theJob.cmd, "cmd", "", "Command to run")
@@ -232,9 +481,12 @@ func (theJob *JobCreateRuntime) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
 
 // Validate - validate the the structure can be used to create a job
 func (theJob *JobCreateRuntime) Validate() error {
-	if theJob.JobID == "" {
+	if theJob.file != "" {
+		// the spec file is validated once flags are applied
+		return nil
+	} else if theJob.JobID == "" {
 		return errors.New("Missing JobId")
-	} else if theJob.cmd == "" && theJob.dockerImage == "" {
+	} else if theJob.cmd == "" && theJob.dockerImage == "" && theJob.image == "" {
 		return errors.New("Need command or docker image")
 	} else if theJob.cpus <= 0.0 || theJob.mem <= 0 || theJob.disk <= 0 {
 		return errors.New("cpus, memory, and disk must all be > 0")
@@ -276,8 +528,7 @@ func (theJob *JobCreateRuntime) Parse(args []string) (exec CommandExec, err erro
 	} else if err = theJob.Validate(); err != nil {
 		panic(err)
 	}
-	theJob.job, err = theJob.JobCreateConfig.makeJob()
-	if err != nil {
+	if err = theJob.jobFromFlags(flags); err != nil {
 		return nil, err
 	}
 	if theJob.runNow {
@@ -290,7 +541,7 @@ func (theJob *JobCreateRuntime) Parse(args []string) (exec CommandExec, err erro
 // Execute - create and execute a job
 func (theJob *JobRunNow) Execute(runtime *Runtime) (interface{}, error) {
 	log.Debugf("JobCreateRuntime.Execute %+v", runtime)
-	_, err := runtime.client.CreateJob(theJob.job)
+	_, err := createJobAndSchedules(runtime, theJob.job)
 	if err != nil {
 		return nil, err
 
@@ -301,18 +552,39 @@ func (theJob *JobRunNow) Execute(runtime *Runtime) (interface{}, error) {
 // Execute - create a job
 func (theJob *JobCreateRuntime) Execute(runtime *Runtime) (interface{}, error) {
 	log.Debugf("JobCreateRuntime.Execute %+v", runtime)
-	return runtime.client.CreateJob(theJob.job)
+	return createJobAndSchedules(runtime, theJob.job)
 }
 
 // JobDelete - Implement CommandParse and CommandExecute
-// DELETE /v1/jobs/$jobId
-type JobDelete JobID
+// DELETE /v1/jobs/$jobId, for each matching job with --selector
+type JobDelete struct {
+	JobID
+	BulkSelector
+}
+
+// FlagSet - job-id or a selector
+func (theJob *JobDelete) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theJob.JobID.FlagSet(flags)
+	theJob.BulkSelector.FlagSet(flags, "Delete every matching job instead of --job-id")
+	return flags
+}
+
+// Validate - exactly one of job-id or selector
+func (theJob *JobDelete) Validate() error {
+	if theJob.Selected() {
+		if theJob.JobID != "" {
+			return errors.New("one of --job-id or --selector, not both")
+		}
+		return nil
+	}
+	return theJob.JobID.Validate()
+}
 
 // Usage - CommandParse implementation/
 func (theJob *JobDelete) Usage(writer io.Writer) {
 	fmt.Fprintf(writer, "job delete:\n")
 	flags := flag.NewFlagSet("job delete", flag.ExitOnError)
-	(*JobID)(theJob).FlagSet(flags)
+	theJob.FlagSet(flags)
 	flags.SetOutput(writer)
 	flags.PrintDefaults()
 }
@@ -321,7 +593,7 @@ func (theJob *JobDelete) Usage(writer io.Writer) {
 //  - CommandParse implementation
 func (theJob *JobDelete) Parse(args []string) (exec CommandExec, err error) {
 	flags := flag.NewFlagSet("job delete", flag.ExitOnError)
-	(*JobID)(theJob).FlagSet(flags)
+	theJob.FlagSet(flags)
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -333,16 +605,21 @@ func (theJob *JobDelete) Parse(args []string) (exec CommandExec, err error) {
 	}()
 	if err = flags.Parse(args); err != nil {
 		panic(err)
-	} else if err = (*JobID)(theJob).Validate(); err != nil {
+	} else if err = theJob.Validate(); err != nil {
 		panic(err)
 	} else {
 		return theJob, nil
 	}
 }
 
-// Execute - delete the job
+// Execute - delete the job, or each matching job
 func (theJob *JobDelete) Execute(runtime *Runtime) (interface{}, error) {
-	return runtime.client.DeleteJob((string)(*theJob))
+	if !theJob.Selected() {
+		return runtime.client.DeleteJob(string(theJob.JobID))
+	}
+	return theJob.ForEach(runtime, func(jobID string) (interface{}, error) {
+		return runtime.client.DeleteJob(jobID)
+	})
 }
 
 // JobGet - Get a job via command line.
@@ -384,23 +661,81 @@ func (theJob *JobGet) Execute(runtime *Runtime) (interface{}, error) {
 	return runtime.client.GetJob(string(*theJob))
 }
 
-// JobList - no arg type to list all the jobs in the system via command line
+// JobFingerprint - print the fingerprint of a job's spec
+//   - Implements CommandParse & CommandExecute interfaces
+//   - GET /v1/jobs/$jobId
+type JobFingerprint JobID
+
+// Usage - CommandParse implementation
+func (theJob *JobFingerprint) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("job fingerprint", flag.ExitOnError)
+	(*JobID)(theJob).FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - the command line flags
+func (theJob *JobFingerprint) Parse(args []string) (exec CommandExec, err error) {
+	if _, err = (*JobGet)(theJob).Parse(args); err != nil {
+		return nil, err
+	}
+	return theJob, nil
+}
+
+// Execute - get the job from metronome and hash it
+func (theJob *JobFingerprint) Execute(runtime *Runtime) (interface{}, error) {
+	job, err := runtime.client.GetJob(string(*theJob))
+	if err != nil {
+		return nil, err
+	}
+	return job.Fingerprint()
+}
+
+// JobList - list the jobs in the system via command line, optionally those matching a label selector
 //  - Implements CommandParse/CommandExecute interfaces
 //  - GET /v1/jobs
-type JobList int
+type JobList struct {
+	selector LabelSelector
+}
+
+// FlagSet - selector
+func (theJob *JobList) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.Var(&theJob.selector, "selector", "Label selector e.g. 'owner=zeus,env!=dev,tier in (batch,etl)' . Only the matching jobs")
+	return flags
+}
 
 // Usage - CommandParse implementation
 func (theJob *JobList) Usage(writer io.Writer) {
-	fmt.Fprintf(writer, "job ls\n\tList all jobs\n")
+	fmt.Fprintf(writer, "job ls [--selector SELECTOR]\n\tList all jobs\n")
+	flags := flag.NewFlagSet("job ls", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
 }
 
-// Parse - nothing to parse.  Implements CommandParse
-func (theJob *JobList) Parse([]string) (CommandExec, error) {
+// Parse - Implements CommandParse
+func (theJob *JobList) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("job ls", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	}
 	return theJob, nil
 }
 
 // Execute - get the jobs from Metronome
 func (theJob *JobList) Execute(runtime *Runtime) (interface{}, error) {
+	if len(theJob.selector) > 0 {
+		return met.SelectJobs(runtime.client, met.Selector(theJob.selector))
+	}
 	jobs, err := runtime.client.Jobs()
 	if err != nil {
 		return nil, err
@@ -420,6 +755,7 @@ func (theJob *JobUpdate) Usage(writer io.Writer) {
 	flags := flag.NewFlagSet("job update", flag.ExitOnError)
 	// must cast to JobRuntime or go chooses JobId.Flagset...
 	(*JobCreateRuntime)(theJob).FlagSet(flags)
+	theJob.ifMatchFlag(flags)
 	flags.SetOutput(writer)
 	flags.PrintDefaults()
 }
@@ -430,6 +766,7 @@ func (theJob *JobUpdate) Parse(args []string) (_ CommandExec, err error) {
 	theJob.disableRunNow = true
 	flags := flag.NewFlagSet("job update", flag.ExitOnError)
 	(*JobCreateRuntime)(theJob).FlagSet(flags)
+	theJob.ifMatchFlag(flags)
 	defer func() {
 		if r := recover(); r != nil {
 			buf := new(bytes.Buffer)
@@ -442,17 +779,228 @@ func (theJob *JobUpdate) Parse(args []string) (_ CommandExec, err error) {
 		panic(err)
 	} else if err = (*JobCreateRuntime)(theJob).Validate(); err != nil {
 		panic(err)
+	} else if err = (*JobCreateRuntime)(theJob).jobFromFlags(flags); err != nil {
+		return nil, err
+	}
+	return theJob, nil
+}
+
+func (theJob *JobUpdate) ifMatchFlag(flags *flag.FlagSet) {
+	flags.StringVar(&theJob.ifMatch, "if-match", "", "Only update if the job's fingerprint (see `job fingerprint`) still matches")
+}
+
+// Execute - implement CommandExec
+func (theJob *JobUpdate) Execute(runtime *Runtime) (interface{}, error) {
+	var result interface{}
+	var err error
+	if theJob.ifMatch != "" {
+		result, err = runtime.client.UpdateJobIfMatch(string(theJob.JobID), theJob.job.Spec(), theJob.ifMatch)
 	} else {
-		theJob.job, err = theJob.JobCreateConfig.makeJob()
-		if err != nil {
+		result, err = runtime.client.UpdateJob(string(theJob.JobID), theJob.job.Spec())
+	}
+	if err != nil || len(theJob.job.Schedules) == 0 {
+		return result, err
+	}
+	if err = syncSchedules(runtime, theJob.job); err != nil {
+		return nil, err
+	}
+	return runtime.client.GetJob(string(theJob.JobID))
+}
+
+// JobPatch - change selected fields of an existing job
+//  - Implements CommandParse/CommandExecute
+//  - GET /v1/jobs/$jobId, apply --merge-patch then each --set then each --unset, PUT /v1/jobs/$jobId
+type JobPatch struct {
+	JobID
+	sets       PathValueList
+	unsets     PathList
+	mergePatch string
+	patch      []byte
+	retries    int
+}
+
+// FlagSet - the job and the edits
+func (theJob *JobPatch) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theJob.JobID.FlagSet(flags)
+	flags.Var(&theJob.sets, "set", "path=value e.g. run.cpus=2 or run.env.FOO=bar.  value is json, or a string when not.  You can call more than once")
+	flags.Var(&theJob.unsets, "unset", "path e.g. run.env.FOO .  Removes the field.  You can call more than once")
+	flags.StringVar(&theJob.mergePatch, "merge-patch", "", "RFC 7386 json merge patch file applied before --set/--unset")
+	flags.IntVar(&theJob.retries, "retries", 0, "If the job changes while patching, re-apply the edits to the new version up to this many times")
+	return flags
+}
+
+// Validate - need a job and something to change
+func (theJob *JobPatch) Validate() error {
+	if err := theJob.JobID.Validate(); err != nil {
+		return err
+	}
+	if len(theJob.sets) == 0 && len(theJob.unsets) == 0 && theJob.mergePatch == "" {
+		return errors.New("Need at least one of --set, --unset or --merge-patch")
+	}
+	return nil
+}
+
+// Usage - CommandParse implementation
+func (theJob *JobPatch) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("job patch", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - CommandParse implementation.  Reads the merge patch file
+func (theJob *JobPatch) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("job patch", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theJob.Validate(); err != nil {
+		panic(err)
+	}
+	if theJob.mergePatch != "" {
+		if theJob.patch, err = ioutil.ReadFile(theJob.mergePatch); err != nil {
 			return nil, err
 		}
-		return theJob, nil
+	}
+	return theJob, nil
+}
 
+// apply - the edits, in --merge-patch, --set, --unset order
+func (theJob *JobPatch) apply(job *met.Job) error {
+	if theJob.patch != nil {
+		if err := job.ApplyMergePatch(theJob.patch); err != nil {
+			return fmt.Errorf("%s: %s", theJob.mergePatch, err.Error())
+		}
+	}
+	for _, pv := range theJob.sets {
+		if err := job.Set(pv.Path, pv.Value); err != nil {
+			return err
+		}
+	}
+	for _, path := range theJob.unsets {
+		if err := job.Unset(path); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-// Execute - implement CommandExec
-func (theJob *JobUpdate) Execute(runtime *Runtime) (interface{}, error) {
-	return runtime.client.UpdateJob(string(theJob.JobID), theJob.job)
+// Execute - CommandExec implementation
+func (theJob *JobPatch) Execute(runtime *Runtime) (interface{}, error) {
+	if theJob.retries < 0 {
+		return nil, errors.New("retries must be >= 0")
+	}
+	return runtime.client.PatchJobRetry(string(theJob.JobID), theJob.retries+1, theJob.apply)
+}
+
+// JobValidate - check a Job spec file against Metronome's schema without calling metronome
+//  - Implements CommandParse/CommandExecute/CommandLocal
+//  - Non-zero exit when the spec is invalid so it can gate CI
+type JobValidate struct {
+	file    string
+	overlay string
+	job     met.Job
+}
+
+// FlagSet - the spec file to check
+func (theJob *JobValidate) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar(&theJob.file, "file", "", "Job spec file, json or yaml, '-' for stdin")
+	flags.StringVar(&theJob.overlay, "overlay", "", "NAME of the overlay in the spec's overlays member to merge over the base spec e.g. prod")
+	return flags
+}
+
+// Validate - a spec file is required
+func (theJob *JobValidate) Validate() error {
+	if theJob.file == "" {
+		return errors.New("file required")
+	}
+	return nil
+}
+
+// Usage - CommandParse implementation
+func (theJob *JobValidate) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("job validate", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - read and decode the spec file
+func (theJob *JobValidate) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("job validate", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theJob.Validate(); err != nil {
+		panic(err)
+	}
+	job, err := readJobSpec(theJob.file, theJob.overlay)
+	if err != nil {
+		return nil, err
+	}
+	theJob.job = *job
+	return theJob, nil
+}
+
+// Local - CommandLocal implementation.  Validation never needs the cluster
+func (theJob *JobValidate) Local() bool {
+	return true
+}
+
+// Execute - validate the decoded job
+func (theJob *JobValidate) Execute(runtime *Runtime) (interface{}, error) {
+	if err := theJob.job.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %s", theJob.file, err.Error())
+	}
+	return fmt.Sprintf("%s: job %s is valid", theJob.file, theJob.job.ID), nil
+}
+
+// JobRender - print a Job spec file with its overlay merged in, as it would be sent to metronome
+//  - Implements CommandParse/CommandExecute/CommandLocal
+type JobRender JobValidate
+
+// Usage - CommandParse implementation
+func (theJob *JobRender) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("job render", flag.ExitOnError)
+	(*JobValidate)(theJob).FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - read the spec file and resolve the overlay
+func (theJob *JobRender) Parse(args []string) (exec CommandExec, err error) {
+	if _, err = (*JobValidate)(theJob).Parse(args); err != nil {
+		return nil, err
+	}
+	return theJob, nil
+}
+
+// Local - CommandLocal implementation.  Rendering never needs the cluster
+func (theJob *JobRender) Local() bool {
+	return true
+}
+
+// Execute - the merged job as canonical json
+func (theJob *JobRender) Execute(runtime *Runtime) (interface{}, error) {
+	spec, err := met.CanonicalJob(&theJob.job)
+	if err != nil {
+		return nil, err
+	}
+	return Text(string(spec) + "\n"), nil
 }
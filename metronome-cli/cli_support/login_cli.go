@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	log "github.com/behance/go-logrus"
+)
+
+// stdin - shared so a username and password piped in on two lines are both read
+var stdin = bufio.NewReader(os.Stdin)
+
+// stdinIsTerminal - whether to prompt, or just read what was piped in.  Asks stty since /dev/null is a character device too
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0 && stty("-g") == nil
+}
+
+// readLine - a line of stdin without its newline, prompting on stderr when stdin is a terminal
+func readLine(prompt string) (string, error) {
+	if stdinIsTerminal() {
+		fmt.Fprint(os.Stderr, prompt)
+	}
+	line, err := stdin.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// readPassword - like readLine but a terminal doesn't echo what is typed
+func readPassword(prompt string) (string, error) {
+	if !stdinIsTerminal() {
+		return readLine(prompt)
+	}
+	if stty("-echo") == nil {
+		defer func() {
+			stty("echo")
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+	return readLine(prompt)
+}
+
+// stty - change the terminal's settings
+func stty(setting string) error {
+	cmd := exec.Command("stty", setting)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
+// loginContext - the context login and logout cache the token in
+func loginContext(runtime *Runtime) (*Context, error) {
+	if runtime.context == nil || runtime.context.Name == "" {
+		return nil, errors.New("no context to keep the token in; create one with 'config set-context NAME --url URL --use'")
+	}
+	return runtime.context, nil
+}
+
+// loginAndSave - exchange the credentials for a token and cache it in the context
+func loginAndSave(runtime *Runtime, ctx *Context, user string, password string) (*met.LoginToken, error) {
+	loginURL, err := ctx.LoginEndpoint(runtime.config.URL)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("logging in to %s as %s", loginURL, user)
+	token, err := met.Login(runtime.config, loginURL, user, password)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Auth, ctx.User = AuthLogin, user
+	ctx.SetLoginToken(token)
+	if err = runtime.cliConfig.Save(runtime.configFile); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Login - exchange a DC/OS username and password for a token cached in the context
+//  - Implements CommandParse/CommandExec/CommandLocal
+//  - POST /acs/api/v1/auth/login of the cluster, or the context's login-url
+type Login struct {
+	user     string
+	loginURL string
+}
+
+// FlagSet - the username and login endpoint
+func (theLogin *Login) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar(&theLogin.user, "username", "", "DC/OS username.  Default the context's user, else prompted for")
+	flags.StringVar(&theLogin.loginURL, "login-url", "", "Login endpoint, kept in the context.  Default the cluster's "+met.ACSLoginPath)
+	return flags
+}
+
+// Usage - CommandParse implementation
+func (theLogin *Login) Usage(writer io.Writer) {
+	fmt.Fprintln(writer, `
+login [--username USER] [--login-url URL]
+	  Prompt for a password, or read the username and password lines from stdin, and cache the token in the context.
+	  Commands then use the token and log in again with it when it expires`)
+	flags := flag.NewFlagSet("login", flag.ExitOnError)
+	theLogin.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - CommandParse implementation
+func (theLogin *Login) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("login", flag.ExitOnError)
+	theLogin.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	}
+	return theLogin, nil
+}
+
+// Execute - read the credentials, log in and save the token
+func (theLogin *Login) Execute(runtime *Runtime) (interface{}, error) {
+	ctx, err := loginContext(runtime)
+	if err != nil {
+		return nil, err
+	}
+	if theLogin.loginURL != "" {
+		ctx.LoginURL = theLogin.loginURL
+	}
+	user := theLogin.user
+	if user == "" {
+		user = ctx.User
+	}
+	if user == "" {
+		if user, err = readLine("Username: "); err != nil {
+			return nil, fmt.Errorf("reading the username: %s", err.Error())
+		}
+	}
+	if user == "" {
+		return nil, errors.New("username required")
+	}
+	password, err := readPassword(fmt.Sprintf("Password for %s: ", user))
+	if err != nil {
+		return nil, fmt.Errorf("reading the password: %s", err.Error())
+	}
+	token, err := loginAndSave(runtime, ctx, user, password)
+	if err != nil {
+		return nil, err
+	}
+	if token.Expires.IsZero() {
+		return fmt.Sprintf("logged in to context %s as %s", ctx.Name, user), nil
+	}
+	return fmt.Sprintf("logged in to context %s as %s, token expires %s", ctx.Name, user, token.Expires.Local().Format(time.RFC3339)), nil
+}
+
+// Local - CommandLocal implementation.  Login talks to the cluster's login endpoint, not metronome
+func (theLogin *Login) Local() bool {
+	return true
+}
+
+// Logout - forget the token cached in the context
+//  - Implements CommandParse/CommandExec/CommandLocal
+type Logout int
+
+// Usage - CommandParse implementation
+func (theLogout *Logout) Usage(writer io.Writer) {
+	fmt.Fprintln(writer, `
+logout
+	  Remove the token login cached in the context`)
+}
+
+// Parse - nothing to parse
+func (theLogout *Logout) Parse([]string) (CommandExec, error) {
+	return theLogout, nil
+}
+
+// Execute - clear the token and save the config file
+func (theLogout *Logout) Execute(runtime *Runtime) (interface{}, error) {
+	ctx, err := loginContext(runtime)
+	if err != nil {
+		return nil, err
+	}
+	ctx.SetLoginToken(nil)
+	if err = runtime.cliConfig.Save(runtime.configFile); err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("logged out of context %s", ctx.Name), nil
+}
+
+// Local - CommandLocal implementation
+func (theLogout *Logout) Local() bool {
+	return true
+}
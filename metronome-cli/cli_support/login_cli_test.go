@@ -0,0 +1,199 @@
+package cli_test
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	cli "github.com/adobe-platform/go-metronome/metronome-cli/cli_support"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Login", func() {
+	var (
+		server *ghttp.Server
+		dir    string
+		file   string
+		saved  map[string]string
+	)
+	jsonHeader := http.Header{"Content-Type": {"application/json"}}
+	envs := []string{cli.EnvConfig, cli.EnvContext, cli.EnvURL, cli.EnvToken, cli.EnvUser, cli.EnvPassword, cli.EnvOutput}
+	jwt := func(expires time.Time) string {
+		claims := `{"uid":"zeus","exp":` + strconv.FormatInt(expires.Unix(), 10) + `}`
+		return "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".c2ln"
+	}
+	writeConfig := func(config *cli.CLIConfig) {
+		Expect(config.Save(file)).To(Succeed())
+	}
+	readConfig := func() *cli.CLIConfig {
+		config, err := cli.LoadCLIConfig(file)
+		Expect(err).NotTo(HaveOccurred())
+		return config
+	}
+	parse := func(args ...string) *cli.Runtime {
+		runtime := &cli.Runtime{}
+		_, err := runtime.Parse(args)
+		Expect(err).NotTo(HaveOccurred())
+		return runtime
+	}
+	acs := func(password string, token string) http.HandlerFunc {
+		return ghttp.CombineHandlers(
+			ghttp.VerifyRequest("POST", met.ACSLoginPath),
+			ghttp.VerifyJSON(`{"uid":"zeus","password":"`+password+`"}`),
+			ghttp.RespondWith(http.StatusOK, `{"token":"`+token+`"}`, jsonHeader),
+		)
+	}
+	listing := func(token string, status int) http.HandlerFunc {
+		return ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", "/v1/jobs"),
+			ghttp.VerifyHeader(http.Header{"Authorization": {"token=" + token}}),
+			ghttp.RespondWith(status, `[]`, jsonHeader),
+		)
+	}
+
+	BeforeEach(func() {
+		saved = make(map[string]string)
+		for _, env := range envs {
+			saved[env] = os.Getenv(env)
+			os.Unsetenv(env)
+		}
+		var err error
+		dir, err = ioutil.TempDir("", "login")
+		Expect(err).NotTo(HaveOccurred())
+		file = filepath.Join(dir, "config.yaml")
+		os.Setenv(cli.EnvConfig, file)
+		server = ghttp.NewServer()
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+		for env, value := range saved {
+			if value == "" {
+				os.Unsetenv(env)
+			} else {
+				os.Setenv(env, value)
+			}
+		}
+	})
+
+	It("Logs in with piped credentials and caches the token in the context", func() {
+		writeConfig(&cli.CLIConfig{CurrentContext: "dev", Contexts: []*cli.Context{{Name: "dev", URLs: []string{server.URL()}}}})
+		expires := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+		token := jwt(expires)
+		server.AppendHandlers(acs("secret", token))
+		cli.SetStdin(strings.NewReader("zeus\nsecret\n"))
+
+		runtime := parse()
+		exec, err := new(cli.Login).Parse(nil)
+		Expect(err).NotTo(HaveOccurred())
+		out, err := exec.Execute(runtime)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(HavePrefix("logged in to context dev as zeus, token expires "))
+
+		ctx := readConfig().Context("dev")
+		Expect(ctx.Auth).To(Equal(cli.AuthLogin))
+		Expect(ctx.User).To(Equal("zeus"))
+		Expect(ctx.Token).To(Equal(token))
+		Expect(ctx.LoginToken().Expires).To(Equal(expires))
+
+		out, err = new(cli.Logout).Execute(parse())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("logged out of context dev"))
+		ctx = readConfig().Context("dev")
+		Expect(ctx.Token).To(BeEmpty())
+		Expect(ctx.TokenExpiry).To(BeEmpty())
+		Expect(ctx.User).To(Equal("zeus"))
+	})
+
+	It("Needs a context to keep the token in", func() {
+		_, err := new(cli.Logout).Execute(parse())
+		Expect(err).To(MatchError(HavePrefix("no context to keep the token in")))
+	})
+
+	It("Takes settings from the flags, else the environment, else the context", func() {
+		writeConfig(&cli.CLIConfig{CurrentContext: "dev", Contexts: []*cli.Context{
+			{Name: "dev", URLs: []string{"http://dev:9000"}, Auth: cli.AuthToken, Token: "from-context", Output: cli.OutputJSON},
+			{Name: "prod", URLs: []string{"http://prod:9000"}, Auth: cli.AuthBasic, User: "ops", Password: "pw"},
+		}})
+		config := parse().Config()
+		Expect(config.URL).To(Equal("http://dev:9000"))
+		Expect(config.AuthToken).To(Equal("token=from-context"))
+
+		os.Setenv(cli.EnvURL, "http://env:9000")
+		os.Setenv(cli.EnvToken, "from-env")
+		config = parse().Config()
+		Expect(config.URL).To(Equal("http://env:9000"))
+		Expect(config.AuthToken).To(Equal("token=from-env"))
+
+		config = parse("--metronome-url", "http://flag:9000", "--authorization", "from-flag").Config()
+		Expect(config.URL).To(Equal("http://flag:9000"))
+		Expect(config.AuthToken).To(Equal("token=from-flag"))
+
+		// credentials come together from one place: a user flag doesn't pick up the environment's token
+		config = parse("--user", "me", "--password", "mine").Config()
+		Expect(config.AuthToken).To(BeEmpty())
+		Expect(config.User).To(Equal("me"))
+
+		os.Unsetenv(cli.EnvURL)
+		os.Unsetenv(cli.EnvToken)
+		os.Setenv(cli.EnvContext, "prod")
+		config = parse().Config()
+		Expect(config.URL).To(Equal("http://prod:9000"))
+		Expect(config.AuthToken).To(BeEmpty())
+		Expect(config.User).To(Equal("ops"))
+
+		config = parse("--context", "dev").Config()
+		Expect(config.URL).To(Equal("http://dev:9000"))
+	})
+
+	It("Logs in again on connect when the cached token expired", func() {
+		fresh := jwt(time.Now().Add(time.Hour))
+		writeConfig(&cli.CLIConfig{CurrentContext: "dev", Contexts: []*cli.Context{{
+			Name: "dev", URLs: []string{server.URL()}, Auth: cli.AuthLogin, User: "zeus", Password: "secret",
+			Token: "stale", TokenExpiry: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339),
+		}}})
+		server.AppendHandlers(acs("secret", fresh), listing(fresh, http.StatusOK))
+		Expect(parse().Connect()).To(Succeed())
+		Expect(readConfig().Context("dev").Token).To(Equal(fresh))
+	})
+
+	It("Checks the expiry of a token cached in a context without an auth method", func() {
+		fresh := jwt(time.Now().Add(time.Hour))
+		writeConfig(&cli.CLIConfig{CurrentContext: "dev", Contexts: []*cli.Context{{
+			Name: "dev", URLs: []string{server.URL()}, User: "zeus", Password: "secret",
+			Token: "stale", TokenExpiry: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339),
+		}}})
+		server.AppendHandlers(acs("secret", fresh), listing(fresh, http.StatusOK))
+		Expect(parse().Connect()).To(Succeed())
+	})
+
+	It("Logs in again when metronome refuses the cached token", func() {
+		cached, fresh := jwt(time.Now().Add(time.Hour)), jwt(time.Now().Add(2*time.Hour))
+		writeConfig(&cli.CLIConfig{CurrentContext: "dev", Contexts: []*cli.Context{{
+			Name: "dev", URLs: []string{server.URL()}, Auth: cli.AuthLogin, User: "zeus", Password: "secret",
+			Token: cached, TokenExpiry: time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		}}})
+		server.AppendHandlers(listing(cached, http.StatusUnauthorized), acs("secret", fresh), listing(fresh, http.StatusOK))
+		Expect(parse().Connect()).To(Succeed())
+		Expect(readConfig().Context("dev").Token).To(Equal(fresh))
+	})
+
+	It("Asks for a login when the token expired and there is no password", func() {
+		writeConfig(&cli.CLIConfig{CurrentContext: "dev", Contexts: []*cli.Context{{
+			Name: "dev", URLs: []string{server.URL()}, Auth: cli.AuthLogin, User: "zeus",
+			Token: "stale", TokenExpiry: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339),
+		}}})
+		Expect(parse().Connect()).To(MatchError("the token of context dev expired or is missing; run 'login'"))
+		Expect(server.ReceivedRequests()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Output formats for the global -o option
+const (
+	OutputTable      = "table"
+	OutputWide       = "wide"
+	OutputJSON       = "json"
+	OutputYAML       = "yaml"
+	OutputJSONPath   = "jsonpath="
+	OutputGoTemplate = "go-template="
+)
+
+// Printer - writes a CommandExec result in the -o format.  Text results are always written as is
+type Printer struct {
+	format   string
+	path     []pathSegment
+	template *template.Template
+}
+
+// NewPrinter - the printer for an -o value: table, wide, json, yaml, jsonpath=TEMPLATE or go-template=TEMPLATE
+func NewPrinter(output string) (*Printer, error) {
+	printer := &Printer{format: output}
+	switch {
+	case output == OutputTable, output == OutputWide, output == OutputJSON, output == OutputYAML:
+	case strings.HasPrefix(output, OutputJSONPath):
+		printer.format = OutputJSONPath
+		path, err := parseJSONPath(strings.TrimPrefix(output, OutputJSONPath))
+		if err != nil {
+			return nil, fmt.Errorf("jsonpath: %s", err.Error())
+		}
+		printer.path = path
+	case strings.HasPrefix(output, OutputGoTemplate):
+		printer.format = OutputGoTemplate
+		tmpl, err := template.New("output").Parse(strings.TrimPrefix(output, OutputGoTemplate))
+		if err != nil {
+			return nil, fmt.Errorf("go-template: %s", err.Error())
+		}
+		printer.template = tmpl
+	default:
+		return nil, fmt.Errorf("output '%s' should be one of table, wide, json, yaml, jsonpath=... or go-template=...", output)
+	}
+	return printer, nil
+}
+
+// Print - write `result` to `writer`.  A table falls back to json for results without columns
+func (printer *Printer) Print(writer io.Writer, result interface{}) error {
+	if text, ok := result.(Text); ok {
+		_, err := io.WriteString(writer, string(text))
+		return err
+	}
+	switch printer.format {
+	case OutputTable, OutputWide:
+		if table, ok := resultTable(result, printer.format == OutputWide); ok {
+			_, err := io.WriteString(writer, table)
+			return err
+		}
+	case OutputYAML:
+		doc, err := genericValue(result)
+		if err != nil {
+			return err
+		}
+		out, err := yaml.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(out)
+		return err
+	case OutputJSONPath:
+		doc, err := genericValue(result)
+		if err != nil {
+			return err
+		}
+		out, err := evalJSONPath(printer.path, doc)
+		if err != nil {
+			return err
+		}
+		return writeLine(writer, out)
+	case OutputGoTemplate:
+		doc, err := genericValue(result)
+		if err != nil {
+			return err
+		}
+		buf := new(bytes.Buffer)
+		if err = printer.template.Execute(buf, doc); err != nil {
+			return err
+		}
+		return writeLine(writer, buf.String())
+	}
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeLine(writer, string(out))
+}
+
+// writeLine - `out` ending in a newline
+func writeLine(writer io.Writer, out string) error {
+	if out != "" && !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err := io.WriteString(writer, out)
+	return err
+}
+
+// genericValue - `result` as maps, slices and scalars via its json encoding, so templates see the api's field names
+func genericValue(result interface{}) (interface{}, error) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err = json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// pathSegment - literal text, or a path into the result: field names, indexes and "*" for every item
+type pathSegment struct {
+	literal string
+	steps   []string
+}
+
+// parseJSONPath - a kubectl style template, without range, e.g. '{.id}' or '{[*].id}{"\n"}'.
+//  Text outside braces is copied, {"..."} is a quoted literal and {.a.b[0].c} or {$.a[*]} a path
+func parseJSONPath(text string) ([]pathSegment, error) {
+	var segments []pathSegment
+	for text != "" {
+		open := strings.Index(text, "{")
+		if open < 0 {
+			segments = append(segments, pathSegment{literal: text})
+			break
+		}
+		if open > 0 {
+			segments = append(segments, pathSegment{literal: text[:open]})
+		}
+		end := strings.Index(text[open:], "}")
+		if end < 0 {
+			return nil, errors.New("unclosed {")
+		}
+		expr := strings.TrimSpace(text[open+1 : open+end])
+		text = text[open+end+1:]
+		if strings.HasPrefix(expr, `"`) {
+			literal, err := strconv.Unquote(expr)
+			if err != nil {
+				return nil, fmt.Errorf("bad literal %s", expr)
+			}
+			segments = append(segments, pathSegment{literal: literal})
+			continue
+		}
+		steps, err := parsePathSteps(expr)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, pathSegment{steps: steps})
+	}
+	return segments, nil
+}
+
+// parsePathSteps - .a.b[0][*] as "a", "b", "0", "*"
+func parsePathSteps(expr string) ([]string, error) {
+	expr = strings.TrimPrefix(expr, "$")
+	if expr != "" && expr[0] != '.' && expr[0] != '[' {
+		return nil, fmt.Errorf("path '%s' should start with . or $", expr)
+	}
+	steps := make([]string, 0)
+	for expr != "" {
+		switch expr[0] {
+		case '.':
+			expr = expr[1:]
+			end := strings.IndexAny(expr, ".[")
+			if end < 0 {
+				end = len(expr)
+			}
+			if end > 0 {
+				steps = append(steps, expr[:end])
+			}
+			expr = expr[end:]
+		case '[':
+			end := strings.Index(expr, "]")
+			if end < 0 {
+				return nil, errors.New("unclosed [")
+			}
+			steps = append(steps, strings.Trim(expr[1:end], `'"`))
+			expr = expr[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected '%s'", expr)
+		}
+	}
+	return steps, nil
+}
+
+// evalJSONPath - the template applied to `doc`.  A path matching several values prints them space separated
+func evalJSONPath(segments []pathSegment, doc interface{}) (string, error) {
+	buf := new(bytes.Buffer)
+	for _, segment := range segments {
+		if segment.steps == nil {
+			buf.WriteString(segment.literal)
+			continue
+		}
+		values := walkPath([]interface{}{doc}, segment.steps)
+		for i, value := range values {
+			if i > 0 {
+				buf.WriteString(" ")
+			}
+			if s, ok := value.(string); ok {
+				buf.WriteString(s)
+				continue
+			}
+			raw, err := json.Marshal(value)
+			if err != nil {
+				return "", err
+			}
+			buf.Write(raw)
+		}
+	}
+	return buf.String(), nil
+}
+
+// walkPath - the values reached from each of `values` by `steps`.  Missing fields are skipped
+func walkPath(values []interface{}, steps []string) []interface{} {
+	for _, step := range steps {
+		next := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				if step == "*" {
+					for _, key := range sortedKeys(v) {
+						next = append(next, v[key])
+					}
+				} else if item, ok := v[step]; ok {
+					next = append(next, item)
+				}
+			case []interface{}:
+				if step == "*" {
+					next = append(next, v...)
+				} else if index, err := strconv.Atoi(step); err == nil {
+					if index < 0 {
+						index += len(v)
+					}
+					if index >= 0 && index < len(v) {
+						next = append(next, v[index])
+					}
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
+	met "github.com/adobe-platform/go-metronome/metronome"
 	log "github.com/behance/go-logrus"
 )
 
@@ -18,10 +20,12 @@ type RunsTopLevel JobTopLevel
 
 // Usage - CommandParse implementation
 func (theRun *RunsTopLevel) Usage(writer io.Writer) {
-	fmt.Fprintf(writer, "run {start|stop|ls|get} <options>:\n")
+	fmt.Fprintf(writer, "run {start|at|cleanup|stop|ls|get} <options>:\n")
 	fmt.Fprintln(writer, `
-	  start <options>  | Start a Job that has a schedule.
-	  stop  <options>  | Stop a Job
+	  start <options>  | Start a Job, or the Jobs matching a label selector.
+	  at <options>     | Run a Job once at a given time.
+	  cleanup          | Remove the one-time schedules of 'run at' that are done.
+	  stop  <options>  | Stop a Job run, or every active run of the Jobs matching a label selector
 	  ls               | Status a Job -- currently only returns 'ACTIVE' jobs
 	  get <options>    | Get a Job run status.
 
@@ -59,6 +63,10 @@ func (theRun *RunsTopLevel) Parse(args []string) (exec CommandExec, err error) {
 		theRun.task = CommandParse(new(RunStatusJob))
 	case "start":
 		theRun.task = CommandParse(new(RunStartJob))
+	case "at":
+		theRun.task = CommandParse(new(RunAt))
+	case "cleanup":
+		theRun.task = CommandParse(new(RunCleanup))
 	case "stop":
 		theRun.task = CommandParse(new(RunStopJob))
 	case "help", "--help":
@@ -119,14 +127,34 @@ func (theRun *RunLs) Execute(runtime *Runtime) (interface{}, error) {
 	return runtime.client.Runs(string(*theRun), 0)
 }
 
-// RunStartJob - cli actuator to run POST /v1/jobs/$jobId/runs
-//   - Really same as JobID but uses different flags
-type RunStartJob JobID
+// RunStartJob - cli actuator to run POST /v1/jobs/$jobId/runs, for each matching job with --selector
+type RunStartJob struct {
+	JobID
+	BulkSelector
+}
+
+// FlagSet - job-id or a selector
+func (theRun *RunStartJob) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theRun.JobID.FlagSet(flags)
+	theRun.BulkSelector.FlagSet(flags, "Start every matching job instead of --job-id")
+	return flags
+}
+
+// Validate - exactly one of job-id or selector
+func (theRun *RunStartJob) Validate() error {
+	if theRun.Selected() {
+		if theRun.JobID != "" {
+			return errors.New("one of --job-id or --selector, not both")
+		}
+		return nil
+	}
+	return theRun.JobID.Validate()
+}
 
 // Usage - Start the job usage
 func (theRun *RunStartJob) Usage(writer io.Writer) {
 	flags := flag.NewFlagSet("run start", flag.ExitOnError)
-	(*JobID)(theRun).FlagSet(flags)
+	theRun.FlagSet(flags)
 	flags.SetOutput(writer)
 	flags.PrintDefaults()
 }
@@ -134,7 +162,7 @@ func (theRun *RunStartJob) Usage(writer io.Writer) {
 // Parse - Parse the flags
 func (theRun *RunStartJob) Parse(args []string) (_ CommandExec, err error) {
 	flags := flag.NewFlagSet("run start", flag.ExitOnError)
-	(*JobID)(theRun).FlagSet(flags)
+	theRun.FlagSet(flags)
 	defer func() {
 		if r := recover(); r != nil {
 			buf := new(bytes.Buffer)
@@ -145,7 +173,7 @@ func (theRun *RunStartJob) Parse(args []string) (_ CommandExec, err error) {
 	}()
 	if err = flags.Parse(args); err != nil {
 		panic(err)
-	} else if err = (*JobID)(theRun).Validate(); err != nil {
+	} else if err = theRun.Validate(); err != nil {
 		panic(err)
 	} else {
 		return theRun, nil
@@ -154,7 +182,12 @@ func (theRun *RunStartJob) Parse(args []string) (_ CommandExec, err error) {
 
 // Execute - the api against Metronome
 func (theRun *RunStartJob) Execute(runtime *Runtime) (interface{}, error) {
-	return runtime.client.StartJob(string(*theRun))
+	if !theRun.Selected() {
+		return runtime.client.StartJob(string(theRun.JobID))
+	}
+	return theRun.ForEach(runtime, func(jobID string) (interface{}, error) {
+		return runtime.client.StartJob(jobID)
+	})
 }
 
 // RunStatusJob - cli actuator that runs `GET  /v1/jobs/$jobId/runs/$runId`
@@ -219,27 +252,45 @@ func (theRun *RunStatusJob) Execute(runtime *Runtime) (interface{}, error) {
 	return runtime.client.StatusJob(string(theRun.JobID), string(theRun.RunID))
 }
 
-// RunStopJob - cli structure facilitating POST /v1/jobs/$jobId/runs/$runId/action/stop
+// RunStopJob - cli structure facilitating POST /v1/jobs/$jobId/runs/$runId/actions/stop
 //  - implements both CommandParse and CommandExecute interfaces
-//  - light-weight override of run status cli structure (same args,flags)
-//  - used via cli to capture, execute:
-type RunStopJob RunStatusJob
+//  - job-id and run-id like run status, or a selector to stop every active run of the matching jobs
+type RunStopJob struct {
+	RunStatusJob
+	BulkSelector
+}
+
+// FlagSet - job-id and run-id, or a selector
+func (theRun *RunStopJob) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theRun.RunStatusJob.FlagSet(flags)
+	theRun.BulkSelector.FlagSet(flags, "Stop every active run of the matching jobs instead of --job-id/--run-id")
+	return flags
+}
+
+// Validate - job-id and run-id, or only a selector
+func (theRun *RunStopJob) Validate() error {
+	if theRun.Selected() {
+		if theRun.JobID != "" || theRun.RunID != "" {
+			return errors.New("--selector can't be used with --job-id or --run-id")
+		}
+		return nil
+	}
+	return theRun.RunStatusJob.Validate()
+}
 
 // Usage - implementation of cli usage needed to RunStatusJob
 func (theRun *RunStopJob) Usage(writer io.Writer) {
 	flags := flag.NewFlagSet("run stop", flag.ExitOnError)
-	(*RunStatusJob)(theRun).FlagSet(flags)
+	theRun.FlagSet(flags)
 	flags.SetOutput(writer)
 	flags.PrintDefaults()
 }
 
-// Parse - takes cli arguments and assures valid flags are passed to get stop a job (job-id,run-id)
-//   - uses RunStatusJob to process flags but uses itself as the CommandExecute implementation
-//   - Implements CommandParse & CommandExecut
-//   - uses runstatus flags but returns itself as the CommandExecute implementation
+// Parse - takes cli arguments and assures valid flags are passed to get stop a job (job-id,run-id or selector)
+//   - Implements CommandParse & CommandExecute
 func (theRun *RunStopJob) Parse(args []string) (_ CommandExec, err error) {
 	flags := flag.NewFlagSet("run stop", flag.ExitOnError)
-	(*RunStatusJob)(theRun).FlagSet(flags)
+	theRun.FlagSet(flags)
 	defer func() {
 		if r := recover(); r != nil {
 			buf := new(bytes.Buffer)
@@ -251,13 +302,189 @@ func (theRun *RunStopJob) Parse(args []string) (_ CommandExec, err error) {
 
 	if err = flags.Parse(args); err != nil {
 		panic(err)
-	} else if err = (*RunStatusJob)(theRun).Validate(); err != nil {
+	} else if err = theRun.Validate(); err != nil {
 		panic(err)
 	}
 	return theRun, nil
 }
 
-// Execute - executes POST /v1/jobs/$jobId/runs/$runId/action/stop
+// Execute - executes POST /v1/jobs/$jobId/runs/$runId/actions/stop for the run, or each active run of the matching jobs
 func (theRun *RunStopJob) Execute(runtime *Runtime) (interface{}, error) {
-	return runtime.client.StopJob(string(theRun.JobID), string(theRun.RunID))
+	if !theRun.Selected() {
+		return runtime.client.StopJob(string(theRun.JobID), string(theRun.RunID))
+	}
+	return theRun.ForEach(runtime, func(jobID string) (interface{}, error) {
+		runs, err := runtime.client.RunLs(jobID)
+		if err != nil {
+			return nil, err
+		}
+		stopped := make([]string, 0, len(*runs))
+		for _, run := range *runs {
+			if _, err := runtime.client.StopJob(jobID, run.ID); err != nil {
+				return stopped, fmt.Errorf("run %s: %s", run.ID, err.Error())
+			}
+			stopped = append(stopped, run.ID)
+		}
+		return stopped, nil
+	})
+}
+
+// RunAt - run a job once at a given time via a one-time schedule
+//  - Implements CommandParse/CommandExec
+//  - POST /v1/jobs/$jobId/schedules then, unless --no-cleanup, DELETE /v1/jobs/$jobId/schedules/$scheduleId once its deadline passes
+type RunAt struct {
+	JobID
+	at        string
+	noCleanup bool
+	when      time.Time
+}
+
+// FlagSet - job-id, time and whether to stay to remove the schedule
+func (theRun *RunAt) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theRun.JobID.FlagSet(flags)
+	flags.StringVar(&theRun.at, "time", "", "When to run: an RFC3339 time or +DURATION from now e.g. +90m.  Rounded up to the minute")
+	flags.BoolVar(&theRun.noCleanup, "no-cleanup", false, "Return once the schedule is added instead of waiting for its deadline to remove it.  'run cleanup' or a later 'run at' for the job removes it")
+	return flags
+}
+
+// Validate - a job and a time to come
+func (theRun *RunAt) Validate() error {
+	if err := theRun.JobID.Validate(); err != nil {
+		return err
+	}
+	if theRun.at == "" {
+		return errors.New("time required")
+	}
+	when, err := parseWhen(theRun.at, time.Now())
+	if err != nil {
+		return fmt.Errorf("time: %s", err.Error())
+	}
+	theRun.when = when
+	if !theRun.when.After(time.Now()) {
+		return errors.New("time must be in the future")
+	}
+	return nil
+}
+
+// Usage - CommandParse implementation
+func (theRun *RunAt) Usage(writer io.Writer) {
+	fmt.Fprintln(writer, `
+run at --job-id ID --time WHEN [--no-cleanup]
+	  Adds a one-time UTC schedule, waits for its starting deadline to pass and removes it.  Cron has no year, so a schedule
+	  left in place fires again on the same date next year: with --no-cleanup keep 'run cleanup --interval 1m' running,
+	  or run 'run cleanup' from cron`)
+	flags := flag.NewFlagSet("run at", flag.ExitOnError)
+	theRun.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - CommandParse implementation
+func (theRun *RunAt) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("run at", flag.ExitOnError)
+	theRun.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theRun.Validate(); err != nil {
+		panic(err)
+	}
+	return theRun, nil
+}
+
+// Execute - add the one-time schedule and, unless --no-cleanup, wait to remove it.  Returns the schedule
+func (theRun *RunAt) Execute(runtime *Runtime) (interface{}, error) {
+	sched, err := runtime.client.RunAt(string(theRun.JobID), theRun.when)
+	if err != nil {
+		return nil, err
+	}
+	if theRun.noCleanup {
+		log.Warnf("schedule %s stays until 'run cleanup' or another 'run at' for %s removes it; left in place it fires again next year", sched.ID, theRun.JobID)
+		return sched, nil
+	}
+	fires, _ := met.OneTimeAt(sched)
+	log.Infof("schedule %s fires at %s; waiting %ds past it to remove it (--no-cleanup to return now)", sched.ID, fires.Format(time.RFC3339), sched.StartingDeadlineSeconds)
+	if err = met.AwaitRunAt(runtime.client, string(theRun.JobID), sched); err != nil {
+		return sched, fmt.Errorf("schedule %s fired but wasn't removed, 'run cleanup' removes it: %s", sched.ID, err.Error())
+	}
+	return sched, nil
+}
+
+// RunCleanup - remove the done one-time schedules 'run at' left behind on every job
+//  - Implements CommandParse/CommandExec
+//  - GET /v1/jobs and /v1/jobs/$jobId/schedules then DELETE /v1/jobs/$jobId/schedules/$scheduleId
+type RunCleanup struct {
+	interval time.Duration
+}
+
+// FlagSet - how often to sweep
+func (theRun *RunCleanup) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.DurationVar(&theRun.interval, "interval", 0, "Keep sweeping every interval until killed e.g. 1m.  Default sweep once")
+	return flags
+}
+
+// Validate - the interval can't be negative
+func (theRun *RunCleanup) Validate() error {
+	if theRun.interval < 0 {
+		return errors.New("interval must be >= 0")
+	}
+	return nil
+}
+
+// Usage - CommandParse implementation
+func (theRun *RunCleanup) Usage(writer io.Writer) {
+	fmt.Fprintln(writer, `
+run cleanup [--interval DURATION]
+	  Delete every job's 'run at' schedules whose starting deadline has passed, so they don't fire again next year`)
+	flags := flag.NewFlagSet("run cleanup", flag.ExitOnError)
+	theRun.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - CommandParse implementation
+func (theRun *RunCleanup) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("run cleanup", flag.ExitOnError)
+	theRun.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theRun.Validate(); err != nil {
+		panic(err)
+	}
+	return theRun, nil
+}
+
+// Execute - sweep once, or every --interval until killed.  Returns the deleted schedules as jobId/scheduleId
+func (theRun *RunCleanup) Execute(runtime *Runtime) (interface{}, error) {
+	for {
+		deleted, err := met.SweepRunAt(runtime.client)
+		for _, id := range deleted {
+			log.Infof("run cleanup: deleted %s", id)
+		}
+		if theRun.interval == 0 {
+			if deleted == nil {
+				deleted = []string{}
+			}
+			return deleted, err
+		}
+		if err != nil {
+			log.Errorf("run cleanup: %s", err.Error())
+		}
+		time.Sleep(theRun.interval)
+	}
 }
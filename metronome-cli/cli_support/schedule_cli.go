@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	met "github.com/adobe-platform/go-metronome/metronome"
 	log "github.com/behance/go-logrus"
@@ -36,13 +37,19 @@ type SchedTopLevel struct {
 
 // Usage - schedule toplevel usage
 func (theSchedule *SchedTopLevel) Usage(writer io.Writer) {
-	fmt.Fprintf(writer, "schedule {create|delete|update|get|ls}  \n")
+	fmt.Fprintf(writer, "schedule {create|delete|update|get|ls|next|calendar|forecast|spread|pause|resume}  \n")
 	fmt.Fprintln(writer, `
 	  create  <options>  | Create a Schedule for a Job
 	  delete  <options>  | Delete a Schedule for a Job
 	  update  <options>  | Update a Schedule for a Job
 	  get     <options>  | Get a single Schedule for a Job
-	  ls                 | Get all Schedules for a Job
+	  ls      <options>  | Get all Schedules for a Job, or the Jobs matching a label selector
+	  next    <options>  | When a Schedule (or a cron expression) fires next
+	  calendar <options> | What every Job's Schedules run in a time window
+	  forecast <options> | When running Jobs are expected to ask for more than the cluster has
+	  spread  <options>  | Move on-the-hour Schedules to a minute hashed from the Job id
+	  pause   <options>  | Disable a Schedule, all of a Job's or those of Jobs matching a selector
+	  resume  <options>  | Enable them again
 	`)
 }
 
@@ -85,6 +92,21 @@ func (theSchedule *SchedTopLevel) Parse(args []string) (exec CommandExec, err er
 	case "update":
 		// PUT /v1/jobs/$jobId/schedules/$scheduleId
 		theSchedule.task = CommandParse(new(JobSchedUpdate))
+	case "next":
+		// GET /v1/jobs/$jobId/schedules/$scheduleId or offline with --cron
+		theSchedule.task = CommandParse(new(JobSchedNext))
+	case "calendar":
+		// GET /v1/jobs and /v1/jobs/$jobId/schedules
+		theSchedule.task = CommandParse(new(SchedCalendar))
+	case "forecast":
+		// GET /v1/jobs and /v1/jobs/$jobId?embed=history&embed=schedules
+		theSchedule.task = CommandParse(new(SchedForecast))
+	case "spread":
+		// GET /v1/jobs then PUT /v1/jobs/$jobId/schedules/$scheduleId
+		theSchedule.task = CommandParse(new(SchedSpread))
+	case "pause", "resume":
+		// GET then PUT /v1/jobs/$jobId/schedules/$scheduleId
+		theSchedule.task = CommandParse(&SchedToggle{action: theSchedule.subcommand, enabled: theSchedule.subcommand == "resume"})
 	case "help", "--help":
 		panic(errors.New("Please help"))
 	default:
@@ -206,12 +228,34 @@ func (theSched *JobSchedDelete) Execute(runtime *Runtime) (interface{}, error) {
 }
 
 // JobScheduleList - cli structure to list a job's schedules -> GET /v1/jobs/$jobId/schedules
-type JobScheduleList JobID
+//  - with --selector, the schedules of each matching job
+type JobScheduleList struct {
+	JobID
+	BulkSelector
+}
+
+// FlagSet - job-id or a selector
+func (theSched *JobScheduleList) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theSched.JobID.FlagSet(flags)
+	theSched.BulkSelector.FlagSet(flags, "The schedules of every matching job instead of --job-id")
+	return flags
+}
+
+// Validate - exactly one of job-id or selector
+func (theSched *JobScheduleList) Validate() error {
+	if theSched.Selected() {
+		if theSched.JobID != "" {
+			return errors.New("one of --job-id or --selector, not both")
+		}
+		return nil
+	}
+	return theSched.JobID.Validate()
+}
 
 // Usage - flags come for job id
 func (theSched *JobScheduleList) Usage(writer io.Writer) {
 	flags := flag.NewFlagSet("schedule ls", flag.ExitOnError)
-	(*JobID)(theSched).FlagSet(flags)
+	theSched.FlagSet(flags)
 	flags.SetOutput(writer)
 	flags.PrintDefaults()
 
@@ -220,7 +264,7 @@ func (theSched *JobScheduleList) Usage(writer io.Writer) {
 // Parse - flags parsed as with JobID but returns self as CommandExecutor on success
 func (theSched *JobScheduleList) Parse(args []string) (_ CommandExec, err error) {
 	flags := flag.NewFlagSet("schedule ls", flag.ExitOnError)
-	(*JobID)(theSched).FlagSet(flags)
+	theSched.FlagSet(flags)
 	defer func() {
 		if r := recover(); r != nil {
 			buf := new(bytes.Buffer)
@@ -231,18 +275,22 @@ func (theSched *JobScheduleList) Parse(args []string) (_ CommandExec, err error)
 	}()
 	if err = flags.Parse(args); err != nil {
 		panic(err)
-	} else if err = (*JobID)(theSched).Validate(); err != nil {
+	} else if err = theSched.Validate(); err != nil {
 		panic(err)
 	} else {
 		return theSched, nil
 	}
-	//	return theSched, nil
 }
 
 // Execute - implement CommandExec
 //  - Runs GET /v1/jobs/$jobId/schedules
 func (theSched *JobScheduleList) Execute(runtime *Runtime) (interface{}, error) {
-	return runtime.client.Schedules(string(*theSched))
+	if !theSched.Selected() {
+		return runtime.client.Schedules(string(theSched.JobID))
+	}
+	return theSched.ForEach(runtime, func(jobID string) (interface{}, error) {
+		return runtime.client.Schedules(jobID)
+	})
 }
 
 // JobScheduleCreate - cli implementation of CommandParse,CommandExec to run -> POST /v1/jobs/$jobId/schedules
@@ -345,11 +393,281 @@ func (theSched *JobSched) Validate() error {
 		return errors.New("Missing SchedId in JobScheduleCreate")
 	} else if theSched.Schedule.Cron == "" {
 		return errors.New("Missing Cron in JobScheduleCreate")
-	} else if !In(theSched.Schedule.ConcurrencyPolicy, []string{"ALLOW", "FORBID", "REPLACE"}) {
-		return errors.New("Missing concurrency policy")
-	} else if theSched.Schedule.StartingDeadlineSeconds < 2 {
-		return errors.New("-starting-deadline-seconds must be > 1")
 	}
 
+	return theSched.Schedule.Validate()
+}
+
+// JobSchedNext - when a schedule fires next
+//    GET /v1/jobs/$jobId/schedules/$scheduleId unless --cron is given, then it works offline
+type JobSchedNext struct {
+	JobSchedBase
+	schedule met.Schedule
+	count    int
+	from     string
+}
+
+// FlagSet - job-id/sched-id or cron/tz, plus how many times from when
+func (theSched *JobSchedNext) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theSched.JobSchedBase.FlagSet(flags)
+	flags.StringVar(&theSched.schedule.Cron, "cron", "", "Cron expression to check instead of a job's schedule")
+	flags.StringVar(&theSched.schedule.Timezone, "tz", "", "Time zone for --cron.  Default UTC")
+	flags.IntVar(&theSched.count, "count", 10, "How many fire times")
+	flags.StringVar(&theSched.from, "from", "", "RFC3339 time to start from.  Default now")
+	return flags
+}
+
+// Validate - either a schedule to fetch or a cron expression
+func (theSched *JobSchedNext) Validate() error {
+	if theSched.count < 1 {
+		return errors.New("count must be >= 1")
+	}
+	if theSched.from != "" {
+		if _, err := time.Parse(time.RFC3339, theSched.from); err != nil {
+			return fmt.Errorf("from: %s", err.Error())
+		}
+	}
+	if theSched.schedule.Cron != "" {
+		return nil
+	}
+	return theSched.JobSchedBase.Validate()
+}
+
+// Usage - CommandParse implementation
+func (theSched *JobSchedNext) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("schedule next", flag.ExitOnError)
+	theSched.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - CommandParse implementation
+func (theSched *JobSchedNext) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("schedule next", flag.ExitOnError)
+	theSched.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theSched.Validate(); err != nil {
+		panic(err)
+	}
+	return theSched, nil
+}
+
+// Local - CommandLocal implementation.  --cron needs no cluster
+func (theSched *JobSchedNext) Local() bool {
+	return theSched.schedule.Cron != ""
+}
+
+// Execute - the fire times, RFC3339 in the schedule's time zone
+func (theSched *JobSchedNext) Execute(runtime *Runtime) (interface{}, error) {
+	sched := &theSched.schedule
+	if sched.Cron == "" {
+		var err error
+		if sched, err = runtime.client.GetSchedule(string(theSched.JobID), string(theSched.SchedID)); err != nil {
+			return nil, err
+		}
+	}
+	from := time.Now()
+	if theSched.from != "" {
+		from, _ = time.Parse(time.RFC3339, theSched.from)
+	}
+	times, err := sched.NextRuns(from, theSched.count)
+	if err != nil {
+		return nil, err
+	}
+	next := make([]string, 0, len(times))
+	for _, t := range times {
+		next = append(next, t.Format(time.RFC3339))
+	}
+	return next, nil
+}
+
+// SchedSpread - move schedules firing on the hour to a minute hashed from their job's id, like an H minute
+//  - Implements CommandParse/CommandExec
+//  - GET /v1/jobs then for each matching job GET /v1/jobs/$jobId/schedules and PUT /v1/jobs/$jobId/schedules/$scheduleId
+type SchedSpread struct {
+	BulkSelector
+	dryRun bool
+}
+
+// FlagSet - selector and dry-run
+func (theSched *SchedSpread) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theSched.BulkSelector.FlagSet(flags, "The jobs whose schedules to spread")
+	flags.BoolVar(&theSched.dryRun, "dry-run", false, "Show the changes without making them")
+	return flags
+}
+
+// Validate - a selector is required so every job isn't rewritten by accident
+func (theSched *SchedSpread) Validate() error {
+	if !theSched.Selected() {
+		return errors.New("selector required")
+	}
 	return nil
 }
+
+// Usage - CommandParse implementation
+func (theSched *SchedSpread) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("schedule spread", flag.ExitOnError)
+	theSched.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - CommandParse implementation
+func (theSched *SchedSpread) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("schedule spread", flag.ExitOnError)
+	theSched.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theSched.Validate(); err != nil {
+		panic(err)
+	}
+	return theSched, nil
+}
+
+// Execute - rewrite the on-the-hour schedules of the selected jobs.  Returns the changes per job
+func (theSched *SchedSpread) Execute(runtime *Runtime) (interface{}, error) {
+	return theSched.ForEach(runtime, func(jobID string) (interface{}, error) {
+		scheds, err := runtime.client.Schedules(jobID)
+		if err != nil {
+			return nil, err
+		}
+		changes := make([]string, 0)
+		for i := range *scheds {
+			sched := &(*scheds)[i]
+			spread, changed, err := sched.Spread(jobID)
+			if err != nil {
+				return changes, fmt.Errorf("schedule %s: %s", sched.ID, err.Error())
+			}
+			if !changed {
+				continue
+			}
+			change := fmt.Sprintf("%s: %s to %s", sched.ID, sched.Cron, spread.Cron)
+			if !theSched.dryRun {
+				spread.NextRunAt = ""
+				if _, err = runtime.client.UpdateSchedule(jobID, sched.ID, spread); err != nil {
+					return changes, fmt.Errorf("%s: %s", change, err.Error())
+				}
+			}
+			changes = append(changes, change)
+		}
+		return changes, nil
+	})
+}
+
+// SchedToggle - `schedule pause` and `schedule resume`: set only enabled on one schedule, every schedule of a job,
+//  or every schedule of the jobs matching a selector
+//  - Implements CommandParse/CommandExec
+//  - GET then PUT /v1/jobs/$jobId/schedules/$scheduleId for each schedule
+type SchedToggle struct {
+	JobSchedBase
+	BulkSelector
+	action  string
+	enabled bool
+}
+
+// FlagSet - job-id with an optional sched-id, or a selector
+func (theSched *SchedToggle) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theSched.JobSchedBase.FlagSet(flags)
+	theSched.BulkSelector.FlagSet(flags, "Every schedule of the matching jobs, instead of --job-id")
+	return flags
+}
+
+// Validate - exactly one of job-id or selector; sched-id only with a job-id
+func (theSched *SchedToggle) Validate() error {
+	if (theSched.JobID == "") == !theSched.Selected() {
+		return errors.New("one of --job-id or --selector required")
+	}
+	if theSched.SchedID != "" && theSched.JobID == "" {
+		return errors.New("sched-id requires job-id")
+	}
+	return nil
+}
+
+// Usage - CommandParse implementation
+func (theSched *SchedToggle) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("schedule "+theSched.action, flag.ExitOnError)
+	theSched.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - CommandParse implementation
+func (theSched *SchedToggle) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("schedule "+theSched.action, flag.ExitOnError)
+	theSched.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theSched.Validate(); err != nil {
+		panic(err)
+	}
+	return theSched, nil
+}
+
+// toggle - set enabled on the job's schedule, or all of its schedules when schedID is empty.
+//  Re-reads each schedule first so nothing but enabled changes.  Returns what happened to each
+func (theSched *SchedToggle) toggle(runtime *Runtime, jobID string, schedID string) ([]string, error) {
+	schedIDs := []string{schedID}
+	if schedID == "" {
+		scheds, err := runtime.client.Schedules(jobID)
+		if err != nil {
+			return nil, err
+		}
+		schedIDs = schedIDs[:0]
+		for _, sched := range *scheds {
+			schedIDs = append(schedIDs, sched.ID)
+		}
+	}
+	done, state := "paused", "paused"
+	if theSched.enabled {
+		done, state = "resumed", "enabled"
+	}
+	results := make([]string, 0, len(schedIDs))
+	for _, id := range schedIDs {
+		key := jobID + "/" + id
+		_, changed, err := runtime.client.SetScheduleEnabled(jobID, id, theSched.enabled)
+		if err != nil {
+			return results, fmt.Errorf("%s: %s", key, err.Error())
+		}
+		if changed {
+			results = append(results, fmt.Sprintf("%s %s", done, key))
+		} else {
+			results = append(results, fmt.Sprintf("%s already %s", key, state))
+		}
+	}
+	return results, nil
+}
+
+// Execute - toggle the schedule(s) of --job-id, or those of each job matching --selector
+func (theSched *SchedToggle) Execute(runtime *Runtime) (interface{}, error) {
+	if theSched.JobID != "" {
+		return theSched.toggle(runtime, string(theSched.JobID), string(theSched.SchedID))
+	}
+	return theSched.ForEach(runtime, func(jobID string) (interface{}, error) {
+		return theSched.toggle(runtime, jobID, "")
+	})
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// StdinSpec - --file value meaning read the spec from stdin
+const StdinSpec = "-"
+
+// readSpec - the raw bytes of `file`, or stdin for "-"
+func readSpec(file string) ([]byte, error) {
+	if file == StdinSpec {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(file)
+}
+
+// specJSON - spec bytes as json.  json passes through; anything else is read as yaml and re-encoded so
+//  the model's own json decoding (operators, mount modes, secret env values...) applies to both
+func specJSON(raw []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return trimmed, nil
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	doc, err := yamlToJSONValue(doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// yamlToJSONValue - yaml.v2 decodes mappings as map[interface{}]interface{} which encoding/json can't marshal
+func yamlToJSONValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			name, ok := key.(string)
+			if !ok {
+				name = fmt.Sprint(key)
+			}
+			converted, err := yamlToJSONValue(item)
+			if err != nil {
+				return nil, err
+			}
+			obj[name] = converted
+		}
+		return obj, nil
+	case []interface{}:
+		for i, item := range v {
+			converted, err := yamlToJSONValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
+
+// readJobSpec - decode a json or yaml job spec from `file` ("-" for stdin) and resolve `overlay` ("" for the base spec)
+func readJobSpec(file string, overlay string) (*met.Job, error) {
+	raw, err := readSpec(file)
+	if err != nil {
+		return nil, err
+	}
+	if raw, err = specJSON(raw); err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err.Error())
+	}
+	var job met.Job
+	if err = json.Unmarshal(raw, &job); err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err.Error())
+	}
+	if err = job.ResolveOverlay(overlay); err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err.Error())
+	}
+	return &job, nil
+}
+
+// createJobAndSchedules - POST the job spec then each embedded schedule.  Returns the job with the created schedules
+func createJobAndSchedules(runtime *Runtime, job *met.Job) (*met.Job, error) {
+	created, err := runtime.client.CreateJob(job.Spec())
+	if err != nil {
+		return nil, err
+	}
+	for _, sched := range job.Schedules {
+		if _, err = runtime.client.CreateSchedule(job.ID, sched); err != nil {
+			return created, fmt.Errorf("job %s created but schedule %s failed: %s", job.ID, sched.ID, err.Error())
+		}
+		created.Schedules = append(created.Schedules, sched)
+	}
+	return created, nil
+}
+
+// syncSchedules - create or update each of the job's embedded schedules.  Schedules missing from the spec are left alone
+func syncSchedules(runtime *Runtime, job *met.Job) error {
+	if len(job.Schedules) == 0 {
+		return nil
+	}
+	existing, err := runtime.client.Schedules(job.ID)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool)
+	for _, sched := range *existing {
+		known[sched.ID] = true
+	}
+	for _, sched := range job.Schedules {
+		if known[sched.ID] {
+			_, err = runtime.client.UpdateSchedule(job.ID, sched.ID, sched)
+		} else {
+			_, err = runtime.client.CreateSchedule(job.ID, sched)
+		}
+		if err != nil {
+			return fmt.Errorf("schedule %s: %s", sched.ID, err.Error())
+		}
+	}
+	return nil
+}
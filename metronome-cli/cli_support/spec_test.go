@@ -0,0 +1,126 @@
+package cli_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	cli "github.com/adobe-platform/go-metronome/metronome-cli/cli_support"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const specYAML = `
+id: reports.nightly
+description: nightly reports
+labels:
+  team: reports
+run:
+  cpus: 0.5
+  mem: 128
+  disk: 0
+  cmd: run-reports
+  env:
+    MODE: full
+    DB_PASSWORD:
+      secret: db
+  secrets:
+    db:
+      source: /reports/db
+  volumes:
+  - containerPath: /data
+    hostPath: /mnt/data
+    mode: RO
+schedules:
+- id: nightly
+  cron: 0 2 * * *
+`
+
+var _ = Describe("Spec files", func() {
+	Describe("specJSON", func() {
+		It("Passes json through", func() {
+			out, err := cli.SpecJSON([]byte(" {\"id\":\"a\"}\n"))
+			Expect(err).To(BeNil())
+			Expect(string(out)).To(Equal(`{"id":"a"}`))
+		})
+
+		It("Converts yaml to the json the models decode", func() {
+			out, err := cli.SpecJSON([]byte(specYAML))
+			Expect(err).To(BeNil())
+			var doc map[string]interface{}
+			Expect(json.Unmarshal(out, &doc)).To(Succeed())
+			Expect(doc["id"]).To(Equal("reports.nightly"))
+			run := doc["run"].(map[string]interface{})
+			Expect(run["cpus"]).To(Equal(0.5))
+			Expect(run["env"].(map[string]interface{})["DB_PASSWORD"]).To(Equal(map[string]interface{}{"secret": "db"}))
+			Expect(run["volumes"].([]interface{})[0].(map[string]interface{})["mode"]).To(Equal("RO"))
+		})
+
+		It("Round trips a job through yaml", func() {
+			fromYAML, err := cli.SpecJSON([]byte(specYAML))
+			Expect(err).To(BeNil())
+			var generic interface{}
+			Expect(json.Unmarshal(fromYAML, &generic)).To(Succeed())
+			again, err := yaml.Marshal(generic)
+			Expect(err).To(BeNil())
+			roundTrip, err := cli.SpecJSON(again)
+			Expect(err).To(BeNil())
+			Expect(roundTrip).To(MatchJSON(fromYAML))
+		})
+
+		It("Turns non-string mapping keys into strings", func() {
+			out, err := cli.SpecJSON([]byte("labels:\n  1: one\n  true: on-call\n"))
+			Expect(err).To(BeNil())
+			Expect(out).To(MatchJSON(`{"labels":{"1":"one","true":"on-call"}}`))
+		})
+
+		It("Rejects malformed yaml", func() {
+			_, err := cli.SpecJSON([]byte("id: a\n  run: [\n"))
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("readJobSpec", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "spec")
+			Expect(err).To(BeNil())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		write := func(name string, content string) string {
+			file := filepath.Join(dir, name)
+			Expect(ioutil.WriteFile(file, []byte(content), 0600)).To(Succeed())
+			return file
+		}
+
+		It("Reads yaml and json specs to the same job", func() {
+			fromYAML, err := cli.ReadJobSpec(write("job.yaml", specYAML), "")
+			Expect(err).To(BeNil())
+			raw, err := json.Marshal(fromYAML)
+			Expect(err).To(BeNil())
+			fromJSON, err := cli.ReadJobSpec(write("job.json", string(raw)), "")
+			Expect(err).To(BeNil())
+			Expect(fromJSON).To(Equal(fromYAML))
+			Expect(fromYAML.Run.Cmd).To(Equal("run-reports"))
+			Expect(fromYAML.Schedules).To(HaveLen(1))
+			Expect(fromYAML.Schedules[0].Cron).To(Equal("0 2 * * *"))
+		})
+
+		It("Names the file in errors", func() {
+			file := write("bad.yaml", "run:\n  cpus: lots\n")
+			_, err := cli.ReadJobSpec(file, "")
+			Expect(err).NotTo(BeNil())
+			Expect(err.Error()).To(HavePrefix(file + ": "))
+			_, err = cli.ReadJobSpec(filepath.Join(dir, "missing.yaml"), "")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+})
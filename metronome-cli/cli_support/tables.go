@@ -0,0 +1,275 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+)
+
+// resultTable - `result` as a table for -o table, with more columns for -o wide.  False when it has no columns
+func resultTable(result interface{}, wide bool) (string, bool) {
+	switch v := result.(type) {
+	case *[]met.Job:
+		return jobTable(*v, wide), true
+	case []met.Job:
+		return jobTable(v, wide), true
+	case *met.Job:
+		return jobTable([]met.Job{*v}, wide), true
+	case *[]met.JobStatus:
+		return runTable(*v, wide), true
+	case *met.JobStatus:
+		return runTable([]met.JobStatus{*v}, wide), true
+	case *[]met.Schedule:
+		return scheduleTable(*v, wide), true
+	case *met.Schedule:
+		return scheduleTable([]met.Schedule{*v}, wide), true
+	case []met.BulkResult:
+		return bulkTable(v, wide), true
+	case []ContextSummary:
+		return contextTable(v, wide), true
+	case *json.RawMessage:
+		return metricsTable(*v, wide)
+	case []string:
+		return linesTable(v), true
+	case string:
+		return v + "\n", true
+	case *string:
+		return *v + "\n", true
+	}
+	return "", false
+}
+
+// newTable - a tabwriter into a buffer with the column headers written
+func newTable(buf *bytes.Buffer, headers ...string) *tabwriter.Writer {
+	tw := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	return tw
+}
+
+// jobTable - id, resources, schedules and run counts.  Wide adds the command or image, labels and description
+func jobTable(jobs []met.Job, wide bool) string {
+	buf := new(bytes.Buffer)
+	headers := []string{"ID", "CPUS", "MEM", "DISK", "SCHEDULES", "ACTIVE", "SUCCESS", "FAILURE"}
+	if wide {
+		headers = append(headers, "CMD/IMAGE", "LABELS", "DESCRIPTION")
+	}
+	tw := newTable(buf, headers...)
+	for _, job := range jobs {
+		var cpus float64
+		var mem, disk int
+		var what string
+		if job.Run != nil {
+			cpus, mem, disk, what = job.Run.Cpus, job.Run.Mem, job.Run.Disk, job.Run.Cmd
+			if job.Run.Docker != nil {
+				what = job.Run.Docker.Image
+			} else if job.Run.Ucr != nil {
+				what = job.Run.Ucr.Image.ID
+			}
+		}
+		success, failure := "-", "-"
+		if job.HistorySummary != nil {
+			success, failure = fmt.Sprint(job.HistorySummary.SuccessCount), fmt.Sprint(job.HistorySummary.FailureCount)
+		} else if job.History != nil {
+			success, failure = fmt.Sprint(job.History.SuccessCount), fmt.Sprint(job.History.FailureCount)
+		}
+		row := []string{job.ID, fmt.Sprint(cpus), fmt.Sprint(mem), fmt.Sprint(disk), fmt.Sprint(len(job.Schedules)), fmt.Sprint(len(job.ActiveRuns)), success, failure}
+		if wide {
+			row = append(row, what, labelText(job.Labels), job.Description)
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	tw.Flush()
+	return buf.String()
+}
+
+// labelText - k=v,k=v sorted by key
+func labelText(labels *met.Labels) string {
+	if labels == nil || len(*labels) == 0 {
+		return "-"
+	}
+	keys := make([]string, 0, len(*labels))
+	for key := range *labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+(*labels)[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// runTable - run id, job, status and times.  Wide adds the tasks
+func runTable(runs []met.JobStatus, wide bool) string {
+	buf := new(bytes.Buffer)
+	headers := []string{"ID", "JOB", "STATUS", "CREATED", "COMPLETED"}
+	if wide {
+		headers = append(headers, "TASKS")
+	}
+	tw := newTable(buf, headers...)
+	for _, run := range runs {
+		completed := "-"
+		if run.CompletedAt != nil {
+			completed = fmt.Sprint(run.CompletedAt)
+		}
+		row := []string{run.ID, run.JobID, run.Status, run.CreatedAt, completed}
+		if wide {
+			tasks := make([]string, 0, len(run.Tasks))
+			for _, task := range run.Tasks {
+				tasks = append(tasks, fmt.Sprintf("%s(%s)", task.ID, task.Status))
+			}
+			row = append(row, strings.Join(tasks, " "))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	tw.Flush()
+	return buf.String()
+}
+
+// scheduleTable - id, cron, zone, enabled and next run.  Wide adds the concurrency policy and deadline
+func scheduleTable(scheds []met.Schedule, wide bool) string {
+	buf := new(bytes.Buffer)
+	headers := []string{"ID", "CRON", "TIMEZONE", "ENABLED", "NEXT RUN"}
+	if wide {
+		headers = append(headers, "CONCURRENCY", "DEADLINE")
+	}
+	tw := newTable(buf, headers...)
+	for _, sched := range scheds {
+		next := sched.NextRunAt
+		if next == "" {
+			next = "-"
+		}
+		row := []string{sched.ID, sched.Cron, sched.Timezone, fmt.Sprint(sched.Enabled), next}
+		if wide {
+			row = append(row, sched.ConcurrencyPolicy, fmt.Sprintf("%ds", sched.StartingDeadlineSeconds))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	tw.Flush()
+	return buf.String()
+}
+
+// bulkTable - each job of a bulk action, ok or its error.  Wide adds the result as json
+func bulkTable(results []met.BulkResult, wide bool) string {
+	buf := new(bytes.Buffer)
+	headers := []string{"JOB", "RESULT"}
+	if wide {
+		headers = append(headers, "DETAIL")
+	}
+	tw := newTable(buf, headers...)
+	for _, result := range results {
+		outcome := "ok"
+		if lines, ok := result.Result.([]string); ok && len(lines) > 0 {
+			outcome = strings.Join(lines, "; ")
+		}
+		if result.Error != "" && outcome == "ok" {
+			outcome = "error: " + result.Error
+		} else if result.Error != "" {
+			outcome += "; error: " + result.Error
+		}
+		row := []string{result.JobID, outcome}
+		if wide {
+			detail, _ := json.Marshal(result.Result)
+			row = append(row, string(detail))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	tw.Flush()
+	return buf.String()
+}
+
+// contextTable - * for the current context, name, urls and auth.  Wide adds the tls settings, output and token expiry
+func contextTable(contexts []ContextSummary, wide bool) string {
+	buf := new(bytes.Buffer)
+	headers := []string{"CURRENT", "NAME", "URLS", "AUTH"}
+	if wide {
+		headers = append(headers, "INSECURE", "CA FILE", "OUTPUT", "TOKEN EXPIRES")
+	}
+	tw := newTable(buf, headers...)
+	for _, ctx := range contexts {
+		current := ""
+		if ctx.Current {
+			current = "*"
+		}
+		row := []string{current, ctx.Name, strings.Join(ctx.URLs, ","), ctx.Auth}
+		if wide {
+			row = append(row, fmt.Sprint(ctx.Insecure), ctx.CAFile, ctx.Output, ctx.Expires)
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	tw.Flush()
+	return buf.String()
+}
+
+// metricsTable - one line per metric of GET /v1/metrics: type, name and its value or count.  Wide adds every field
+func metricsTable(raw json.RawMessage, wide bool) (string, bool) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return "", false
+	}
+	buf := new(bytes.Buffer)
+	headers := []string{"TYPE", "NAME", "VALUE"}
+	if wide {
+		headers = append(headers, "FIELDS")
+	}
+	tw := newTable(buf, headers...)
+	for _, kind := range sortedKeys(doc) {
+		metrics, ok := doc[kind].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, name := range sortedKeys(metrics) {
+			fields, _ := metrics[name].(map[string]interface{})
+			value, ok := fields["value"]
+			if !ok {
+				value = fields["count"]
+			}
+			row := []string{strings.TrimSuffix(kind, "s"), name, scalarText(value)}
+			if wide {
+				pairs := make([]string, 0, len(fields))
+				for _, field := range sortedKeys(fields) {
+					pairs = append(pairs, field+"="+scalarText(fields[field]))
+				}
+				row = append(row, strings.Join(pairs, " "))
+			}
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+	}
+	tw.Flush()
+	return buf.String(), true
+}
+
+// linesTable - one string per line
+func linesTable(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// scalarText - a decoded json value as text
+func scalarText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case string:
+		return v
+	}
+	raw, _ := json.Marshal(value)
+	return string(raw)
+}
+
+// sortedKeys - the keys of a decoded json object in order
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,5 +1,11 @@
 package cli
 
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
 // In - checks whether the string is in the array
 func In(val string, targ []string) bool {
 	for _, cur := range targ {
@@ -9,3 +15,29 @@ func In(val string, targ []string) bool {
 	}
 	return false
 }
+
+// HasResult - whether there is something to print: not nil, nor a nil pointer, slice or map.  Commands acting on many
+//  jobs return what they did along with the error of what failed
+func HasResult(result interface{}) bool {
+	if result == nil {
+		return false
+	}
+	value := reflect.ValueOf(result)
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return !value.IsNil()
+	}
+	return true
+}
+
+// parseWhen - an RFC3339 time, or +DURATION (e.g. +90m) from `now`
+func parseWhen(text string, now time.Time) (time.Time, error) {
+	if strings.HasPrefix(text, "+") {
+		delay, err := time.ParseDuration(text[1:])
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(delay), nil
+	}
+	return time.Parse(time.RFC3339, text)
+}
@@ -6,8 +6,6 @@ import (
 	"strings"
 
 	log "github.com/behance/go-logrus"
-	//"errors"
-	"encoding/json"
 
 	cli "github.com/adobe-platform/go-metronome/metronome-cli/cli_support"
 )
@@ -24,6 +22,13 @@ func init() {
 		"schedule": cli.CommandParse(new(cli.SchedTopLevel)),
 		"metrics":  cli.CommandParse(new(cli.Metrics)),
 		"ping":     cli.CommandParse(new(cli.Ping)),
+		"apply":    cli.CommandParse(new(cli.Apply)),
+		"blackout": cli.CommandParse(new(cli.BlackoutTopLevel)),
+		"freeze":   cli.CommandParse(new(cli.Freeze)),
+		"thaw":     cli.CommandParse(new(cli.Thaw)),
+		"config":   cli.CommandParse(new(cli.ConfigTopLevel)),
+		"login":    cli.CommandParse(new(cli.Login)),
+		"logout":   cli.CommandParse(new(cli.Logout)),
 	}
 }
 
@@ -37,6 +42,13 @@ func usage(msg string) {
 		"schedule",
 		"metrics",
 		"ping",
+		"apply",
+		"blackout",
+		"freeze",
+		"thaw",
+		"config",
+		"login",
+		"logout",
 	}
 	fmt.Fprintf(os.Stderr, `USAGE
 
@@ -106,28 +118,20 @@ func main() {
 		} else if executor, err := commands[action].Parse(executorArgs); err != nil {
 			log.Fatalf("%s failed because %+v", action, err)
 		} else {
+			if local, ok := executor.(cli.CommandLocal); !ok || !local.Local() {
+				if err := runtime.Connect(); err != nil {
+					usage(err.Error())
+				}
+			}
 			if result, err2 := executor.Execute(runtime); err2 != nil {
+				if cli.HasResult(result) {
+					runtime.Print(os.Stdout, result)
+				}
 				log.Fatalf("action %s execution failed because %+v", action, err2)
 			} else {
 				log.Debugf("Result type: %T", result)
-
-				switch result.(type) {
-				case json.RawMessage:
-					var f interface{}
-					by := result.(json.RawMessage)
-					if err := json.Unmarshal(by, &f); err != nil {
-						log.Infof(string(by))
-					} else {
-						if b2, err2 := json.MarshalIndent(f, "", "  "); err2 != nil {
-							log.Infof(string(by))
-						} else {
-							log.Infof(string(b2))
-						}
-					}
-				default:
-					if bb, err7 := json.MarshalIndent(result, "", "  "); err7 == nil {
-						log.Infof("result %s\n", (string(bb)))
-					}
+				if err := runtime.Print(os.Stdout, result); err != nil {
+					log.Fatalf("action %s output failed because %+v", action, err)
 				}
 			}
 		}
@@ -0,0 +1,197 @@
+package metronome
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Plan actions
+const (
+	PlanCreate = "create"
+	PlanUpdate = "update"
+	PlanDelete = "delete"
+)
+
+// PlanStep - one change needed to make metronome match the desired jobs.  Schedule is nil for a job step
+type PlanStep struct {
+	Action   string
+	JobID    string
+	Job      *Job
+	Schedule *Schedule
+}
+
+// String - e.g. "create schedule nightly.report/nightly"
+func (step *PlanStep) String() string {
+	if step.Schedule != nil {
+		return fmt.Sprintf("%s schedule %s/%s", step.Action, step.JobID, step.Schedule.ID)
+	}
+	return fmt.Sprintf("%s job %s", step.Action, step.JobID)
+}
+
+// Plan - steps in dependency order.  A job is created/updated before its schedules; schedules are deleted before their job
+type Plan []*PlanStep
+
+// CurrentJobs - every job in metronome with its schedules
+func CurrentJobs(client Metronome) ([]*Job, error) {
+	jobs, err := client.Jobs()
+	if err != nil {
+		return nil, err
+	}
+	current := make([]*Job, 0, len(*jobs))
+	for i := range *jobs {
+		job := &(*jobs)[i]
+		scheds, err := client.Schedules(job.ID)
+		if err != nil {
+			return nil, fmt.Errorf("schedules of %s: %s", job.ID, err.Error())
+		}
+		job.Schedules = nil
+		for j := range *scheds {
+			job.Schedules = append(job.Schedules, &(*scheds)[j])
+		}
+		current = append(current, job)
+	}
+	return current, nil
+}
+
+func scheduleIndex(job *Job) (map[string]*Schedule, error) {
+	index := make(map[string]*Schedule)
+	if job == nil {
+		return index, nil
+	}
+	for _, sched := range job.Schedules {
+		if _, dup := index[sched.ID]; dup {
+			return nil, fmt.Errorf("job %s has schedule %s twice", job.ID, sched.ID)
+		}
+		index[sched.ID] = sched
+	}
+	return index, nil
+}
+
+// NewPlan - the steps taking `current` to `desired`.  Jobs and schedules are compared with DiffJobs/DiffSchedules so defaults metronome fills in don't cause updates.
+//  A desired job owns its schedules: ones it doesn't list are deleted.
+//  Current jobs missing from desired are deleted only when prune says so; a nil prune never deletes jobs
+func NewPlan(desired []*Job, current []*Job, prune func(*Job) bool) (Plan, error) {
+	currentByID := make(map[string]*Job)
+	for _, job := range current {
+		currentByID[job.ID] = job
+	}
+	wanted := make(map[string]bool)
+	sorted := make([]*Job, len(desired))
+	copy(sorted, desired)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	var plan Plan
+	for _, job := range sorted {
+		if wanted[job.ID] {
+			return nil, fmt.Errorf("job %s is declared twice", job.ID)
+		}
+		wanted[job.ID] = true
+		have := currentByID[job.ID]
+		if have == nil {
+			plan = append(plan, &PlanStep{Action: PlanCreate, JobID: job.ID, Job: job})
+		} else {
+			changes, err := DiffJobs(have.Spec(), job.Spec())
+			if err != nil {
+				return nil, err
+			}
+			if len(changes) > 0 {
+				plan = append(plan, &PlanStep{Action: PlanUpdate, JobID: job.ID, Job: job})
+			}
+		}
+		wantScheds, err := scheduleIndex(job)
+		if err != nil {
+			return nil, err
+		}
+		haveScheds, err := scheduleIndex(have)
+		if err != nil {
+			return nil, err
+		}
+		for _, declared := range job.Schedules {
+			// compare what metronome will hold: H tokens expanded
+			sched, err := declared.Expanded(job.ID)
+			if err != nil {
+				return nil, fmt.Errorf("job %s schedule %s: %s", job.ID, declared.ID, err.Error())
+			}
+			existing, ok := haveScheds[sched.ID]
+			if !ok {
+				plan = append(plan, &PlanStep{Action: PlanCreate, JobID: job.ID, Schedule: sched})
+				continue
+			}
+			changes, err := DiffSchedules(existing, sched)
+			if err != nil {
+				return nil, err
+			}
+			if len(changes) > 0 {
+				plan = append(plan, &PlanStep{Action: PlanUpdate, JobID: job.ID, Schedule: sched})
+			}
+		}
+		if have != nil {
+			for _, sched := range have.Schedules {
+				if _, ok := wantScheds[sched.ID]; !ok {
+					plan = append(plan, &PlanStep{Action: PlanDelete, JobID: job.ID, Schedule: sched})
+				}
+			}
+		}
+	}
+	if prune == nil {
+		return plan, nil
+	}
+	sortedCurrent := make([]*Job, len(current))
+	copy(sortedCurrent, current)
+	sort.Slice(sortedCurrent, func(i, j int) bool { return sortedCurrent[i].ID < sortedCurrent[j].ID })
+	for _, job := range sortedCurrent {
+		if wanted[job.ID] || !prune(job) {
+			continue
+		}
+		for _, sched := range job.Schedules {
+			plan = append(plan, &PlanStep{Action: PlanDelete, JobID: job.ID, Schedule: sched})
+		}
+		plan = append(plan, &PlanStep{Action: PlanDelete, JobID: job.ID, Job: job})
+	}
+	return plan, nil
+}
+
+func (step *PlanStep) execute(client Metronome) (err error) {
+	switch {
+	case step.Schedule != nil && step.Action == PlanCreate:
+		_, err = client.CreateSchedule(step.JobID, step.Schedule)
+	case step.Schedule != nil && step.Action == PlanUpdate:
+		_, err = client.UpdateSchedule(step.JobID, step.Schedule.ID, step.Schedule)
+	case step.Schedule != nil && step.Action == PlanDelete:
+		_, err = client.DeleteSchedule(step.JobID, step.Schedule.ID)
+	case step.Action == PlanCreate:
+		_, err = client.CreateJob(step.Job.Spec())
+	case step.Action == PlanUpdate:
+		_, err = client.UpdateJob(step.JobID, step.Job.Spec())
+	case step.Action == PlanDelete:
+		_, err = client.DeleteJob(step.JobID)
+	default:
+		err = fmt.Errorf("unknown plan action %s", step.Action)
+	}
+	return err
+}
+
+// Execute - run the steps in order.  When a job's create/update fails its schedule steps are skipped.
+//  `report`, if not nil, is called after every step with its error (nil on success).  Returns an error if any step failed or was skipped
+func (plan Plan) Execute(client Metronome, report func(step *PlanStep, err error)) error {
+	failedJobs := make(map[string]bool)
+	failed := 0
+	for _, step := range plan {
+		var err error
+		if step.Schedule != nil && failedJobs[step.JobID] {
+			err = fmt.Errorf("skipped: job %s failed", step.JobID)
+		} else if err = step.execute(client); err != nil && step.Schedule == nil {
+			failedJobs[step.JobID] = true
+		}
+		if err != nil {
+			failed++
+		}
+		if report != nil {
+			report(step, err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d steps failed", failed, len(plan))
+	}
+	return nil
+}
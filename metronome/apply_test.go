@@ -0,0 +1,158 @@
+package metronome_test
+
+import (
+	"encoding/json"
+	"net/http"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Apply", func() {
+	parse := func(doc string) *Job {
+		var job Job
+		Expect(json.Unmarshal([]byte(doc), &job)).To(Succeed())
+		return &job
+	}
+	steps := func(plan Plan) []string {
+		var out []string
+		for _, step := range plan {
+			out = append(out, step.String())
+		}
+		return out
+	}
+	const same = `{"id":"same","run":{"cmd":"true","cpus":1,"mem":64,"disk":0},"schedules":[{"id":"keep","cron":"0 * * * *"}]}`
+
+	Describe("NewPlan", func() {
+		It("Creates missing jobs before their schedules and leaves matching ones alone", func() {
+			desired := []*Job{
+				parse(`{"id":"new","run":{"cmd":"true","cpus":1,"mem":64,"disk":0},"schedules":[{"id":"nightly","cron":"0 2 * * *"}]}`),
+				parse(same),
+			}
+			current := []*Job{parse(same)}
+			current[0].Schedules[0].NextRunAt = "2026-01-01T00:00:00.000+0000"
+			plan, err := NewPlan(desired, current, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(steps(plan)).To(Equal([]string{"create job new", "create schedule new/nightly"}))
+		})
+
+		It("Updates changed jobs and schedules and deletes unlisted schedules", func() {
+			desired := []*Job{parse(`{"id":"same","run":{"cmd":"false","cpus":1,"mem":64,"disk":0},"schedules":[{"id":"keep","cron":"5 * * * *"}]}`)}
+			current := []*Job{parse(same)}
+			current[0].Schedules = append(current[0].Schedules, &Schedule{ID: "old"})
+			plan, err := NewPlan(desired, current, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(steps(plan)).To(Equal([]string{"update job same", "update schedule same/keep", "delete schedule same/old"}))
+		})
+
+		It("Creates schedules that leave out enabled as enabled", func() {
+			desired := parse(`{"id":"new","run":{"cmd":"true","cpus":1,"mem":64,"disk":0},"schedules":[{"id":"on","cron":"0 2 * * *"},{"id":"off","cron":"0 3 * * *","enabled":false}]}`)
+			Expect(desired.Schedules[0].Enabled).To(BeTrue())
+			Expect(desired.Schedules[1].Enabled).To(BeFalse())
+			plan, err := NewPlan([]*Job{desired}, nil, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(plan).To(HaveLen(3))
+			Expect(plan[1].Schedule.Enabled).To(BeTrue())
+			Expect(plan[2].Schedule.Enabled).To(BeFalse())
+
+			live := parse(same)
+			live.Schedules[0].Enabled = true
+			plan, err = NewPlan([]*Job{parse(same)}, []*Job{live}, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(plan).To(BeEmpty())
+		})
+
+		It("Doesn't update for defaults metronome filled in", func() {
+			live := parse(same)
+			live.SetLabel(Labels{}).Run.SetRestart(&Restart{Policy: "NEVER"})
+			live.Schedules[0].Timezone = "UTC"
+			live.Schedules[0].ConcurrencyPolicy = "ALLOW"
+			plan, err := NewPlan([]*Job{parse(same)}, []*Job{live}, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(plan).To(BeEmpty())
+		})
+
+		It("Prunes only what the selector allows, schedules first", func() {
+			current := []*Job{
+				parse(`{"id":"mine","labels":{"team":"a"},"run":{"cmd":"true","cpus":1,"mem":64,"disk":0},"schedules":[{"id":"s","cron":"* * * * *"}]}`),
+				parse(`{"id":"theirs","labels":{"team":"b"},"run":{"cmd":"true","cpus":1,"mem":64,"disk":0}}`),
+			}
+			plan, err := NewPlan(nil, current, func(job *Job) bool {
+				return (*job.GetLabels())["team"] == "a"
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(steps(plan)).To(Equal([]string{"delete schedule mine/s", "delete job mine"}))
+		})
+
+		It("Compares schedules with H tokens expanded for the job", func() {
+			desired := parse(`{"id":"same","run":{"cmd":"true","cpus":1,"mem":64,"disk":0},"schedules":[{"id":"keep","cron":"H * * * *"}]}`)
+			expanded, err := desired.Schedules[0].Expanded("same")
+			Expect(err).ShouldNot(HaveOccurred())
+			live := parse(same)
+			live.Schedules[0].Cron = expanded.Cron
+			plan, err := NewPlan([]*Job{desired}, []*Job{live}, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(plan).To(BeEmpty())
+
+			plan, err = NewPlan([]*Job{desired}, []*Job{parse(same)}, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(steps(plan)).To(Equal([]string{"update schedule same/keep"}))
+			Expect(plan[0].Schedule.Cron).To(Equal(expanded.Cron))
+			Expect(desired.Schedules[0].Cron).To(Equal("H * * * *"))
+		})
+
+		It("Rejects a job declared twice", func() {
+			_, err := NewPlan([]*Job{parse(same), parse(same)}, nil, nil)
+			Expect(err).To(MatchError("job same is declared twice"))
+		})
+	})
+
+	Describe("Execute", func() {
+		var (
+			server *ghttp.Server
+			client Metronome
+		)
+		jsonHeader := http.Header{"Content-Type": {"application/json"}}
+
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+			server.AppendHandlers(ghttp.VerifyRequest("GET", "/v1/jobs"))
+			client, _ = NewClient(Config{URL: server.URL(), RequestTimeout: 5})
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Skips the schedules of a job that failed and reports the failures", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/v1/jobs"),
+					ghttp.RespondWith(http.StatusUnprocessableEntity, `{"message":"nope"}`, jsonHeader),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/v1/jobs/gone/schedules/s"),
+					ghttp.RespondWith(http.StatusOK, `{}`, jsonHeader),
+				),
+			)
+			job := parse(`{"id":"bad","run":{"cmd":"true","cpus":1,"mem":64,"disk":0},"schedules":[{"id":"s","cron":"* * * * *"}]}`)
+			plan := Plan{
+				{Action: PlanCreate, JobID: "bad", Job: job},
+				{Action: PlanCreate, JobID: "bad", Schedule: job.Schedules[0]},
+				{Action: PlanDelete, JobID: "gone", Schedule: &Schedule{ID: "s"}},
+			}
+			var failed []string
+			err := plan.Execute(client, func(step *PlanStep, err error) {
+				if err != nil {
+					failed = append(failed, step.String())
+				}
+			})
+			Expect(err).To(MatchError("2 of 3 steps failed"))
+			Expect(failed).To(Equal([]string{"create job bad", "create schedule bad/s"}))
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+	})
+})
@@ -0,0 +1,274 @@
+package metronome
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adobe-platform/go-metronome/metronome/cron"
+	log "github.com/behance/go-logrus"
+)
+
+// Blackout - a window during which the schedules of matching jobs are disabled.  Either a date range (start/end) or
+//  recurring: open `duration` from each time `cron` fires in `timezone` e.g.
+//  {"name": "friday-maintenance", "cron": "0 22 * * FRI", "duration": "4h", "timezone": "Europe/Berlin", "selector": "team=reports,env!=dev"}
+//  The selector is a label selector as ParseSelector reads it, or a {"label": "value"} object.  An empty selector matches every job
+type Blackout struct {
+	Name     string    `json:"name"`
+	Start    time.Time `json:"start,omitempty"`
+	End      time.Time `json:"end,omitempty"`
+	Cron     string    `json:"cron,omitempty"`
+	Duration string    `json:"duration,omitempty"`
+	Timezone string    `json:"timezone,omitempty"`
+	Selector Selector  `json:"selector,omitempty"`
+}
+
+// BlackoutFile - how blackouts are written down: {"blackouts": [...]}
+type BlackoutFile struct {
+	Blackouts []*Blackout `json:"blackouts"`
+}
+
+// ParseBlackouts - decode and validate a json BlackoutFile
+func ParseBlackouts(raw []byte) ([]*Blackout, error) {
+	var file BlackoutFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for i, blackout := range file.Blackouts {
+		if err := blackout.Validate(); err != nil {
+			return nil, fmt.Errorf("blackouts[%d]: %s", i, err.Error())
+		}
+		if names[blackout.Name] {
+			return nil, fmt.Errorf("blackouts[%d]: name %s is used twice", i, blackout.Name)
+		}
+		names[blackout.Name] = true
+	}
+	return file.Blackouts, nil
+}
+
+// Validate - a name and either a date range or a cron expression with a duration
+func (blackout *Blackout) Validate() error {
+	if blackout.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	ranged := !blackout.Start.IsZero() || !blackout.End.IsZero()
+	recurring := blackout.Cron != "" || blackout.Duration != ""
+	switch {
+	case ranged == recurring:
+		return fmt.Errorf("%s: needs either start/end or cron/duration", blackout.Name)
+	case ranged && !blackout.End.After(blackout.Start):
+		return fmt.Errorf("%s: end must be after start", blackout.Name)
+	case ranged:
+		return nil
+	}
+	if err := cron.Validate(blackout.Cron); err != nil {
+		return fmt.Errorf("%s: %s", blackout.Name, err.Error())
+	}
+	if d, err := time.ParseDuration(blackout.Duration); err != nil || d <= 0 {
+		return fmt.Errorf("%s: duration '%s' must be a positive duration e.g. 4h", blackout.Name, blackout.Duration)
+	}
+	if _, err := blackout.location(); err != nil {
+		return fmt.Errorf("%s: %s", blackout.Name, err.Error())
+	}
+	return nil
+}
+
+func (blackout *Blackout) location() (*time.Location, error) {
+	if blackout.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(blackout.Timezone)
+}
+
+// Active - the window is open at `now`
+func (blackout *Blackout) Active(now time.Time) (bool, error) {
+	if blackout.Cron == "" {
+		return !now.Before(blackout.Start) && now.Before(blackout.End), nil
+	}
+	sched, err := cron.Parse(blackout.Cron)
+	if err != nil {
+		return false, err
+	}
+	loc, err := blackout.location()
+	if err != nil {
+		return false, err
+	}
+	d, err := time.ParseDuration(blackout.Duration)
+	if err != nil {
+		return false, err
+	}
+	// the last opening no more than `d` ago
+	opened := sched.Next(now.Add(-d), loc)
+	return !opened.IsZero() && !opened.After(now), nil
+}
+
+// Matches - the job's labels meet the selector
+func (blackout *Blackout) Matches(job *Job) bool {
+	return blackout.Selector.Matches(job.Labels)
+}
+
+// PausedSchedule - bookkeeping for a schedule a blackout disabled
+type PausedSchedule struct {
+	JobID      string   `json:"jobId"`
+	ScheduleID string   `json:"scheduleId"`
+	WasEnabled bool     `json:"wasEnabled"`
+	Blackouts  []string `json:"blackouts"`
+}
+
+// BlackoutState - the schedules blackouts hold paused, keyed by job-id/schedule-id.  Persisted between controller runs
+type BlackoutState struct {
+	Paused map[string]*PausedSchedule `json:"paused"`
+}
+
+// LoadBlackoutState - read the state `file`.  A missing file is an empty state
+func LoadBlackoutState(file string) (*BlackoutState, error) {
+	state := &BlackoutState{Paused: make(map[string]*PausedSchedule)}
+	raw, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return state, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(raw, state); err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err.Error())
+	}
+	if state.Paused == nil {
+		state.Paused = make(map[string]*PausedSchedule)
+	}
+	return state, nil
+}
+
+// Save - write the state to `file` atomically
+func (state *BlackoutState) Save(file string) error {
+	raw, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(append(raw, '\n')); err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), file)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// BlackoutController - disables the schedules of jobs matching an open blackout and restores their previous Enabled when
+//  every window holding them has closed.  Its state is saved before each schedule is disabled, so a controller
+//  restarted at any point still knows what to restore
+type BlackoutController struct {
+	Client    Metronome
+	Blackouts []*Blackout
+	StateFile string
+	// DryRun - report the changes without making them or saving state
+	DryRun bool
+}
+
+// Reconcile - bring schedules in line with the blackouts open at `now`.  Returns what was done
+func (controller *BlackoutController) Reconcile(now time.Time) ([]string, error) {
+	state, err := LoadBlackoutState(controller.StateFile)
+	if err != nil {
+		return nil, err
+	}
+	var open []*Blackout
+	for _, blackout := range controller.Blackouts {
+		active, err := blackout.Active(now)
+		if err != nil {
+			return nil, fmt.Errorf("blackout %s: %s", blackout.Name, err.Error())
+		}
+		if active {
+			open = append(open, blackout)
+		}
+	}
+	jobs, err := CurrentJobs(controller.Client)
+	if err != nil {
+		return nil, err
+	}
+	var actions []string
+	seen := make(map[string]bool)
+	for _, job := range jobs {
+		var holding []string
+		for _, blackout := range open {
+			if blackout.Matches(job) {
+				holding = append(holding, blackout.Name)
+			}
+		}
+		for _, sched := range job.Schedules {
+			key := job.ID + "/" + sched.ID
+			seen[key] = true
+			paused := state.Paused[key]
+			switch {
+			case len(holding) > 0:
+				if paused == nil {
+					paused = &PausedSchedule{JobID: job.ID, ScheduleID: sched.ID, WasEnabled: sched.Enabled}
+					state.Paused[key] = paused
+				}
+				paused.Blackouts = holding
+				if !sched.Enabled {
+					continue
+				}
+				if err = controller.save(state); err != nil {
+					return actions, err
+				}
+				if err = controller.setEnabled(job.ID, sched, false); err != nil {
+					return actions, err
+				}
+				actions = append(actions, fmt.Sprintf("disable %s (%s)", key, strings.Join(holding, ",")))
+			case paused != nil:
+				if sched.Enabled != paused.WasEnabled {
+					if err = controller.setEnabled(job.ID, sched, paused.WasEnabled); err != nil {
+						return actions, err
+					}
+				}
+				delete(state.Paused, key)
+				if err = controller.save(state); err != nil {
+					return actions, err
+				}
+				actions = append(actions, fmt.Sprintf("restore %s enabled=%t", key, paused.WasEnabled))
+			}
+		}
+	}
+	// schedules deleted while paused have nothing to restore
+	for key := range state.Paused {
+		if !seen[key] {
+			delete(state.Paused, key)
+			actions = append(actions, fmt.Sprintf("forget %s: no longer exists", key))
+		}
+	}
+	return actions, controller.save(state)
+}
+
+func (controller *BlackoutController) save(state *BlackoutState) error {
+	if controller.DryRun {
+		return nil
+	}
+	return state.Save(controller.StateFile)
+}
+
+func (controller *BlackoutController) setEnabled(jobID string, sched *Schedule, enabled bool) error {
+	if controller.DryRun {
+		return nil
+	}
+	update := *sched
+	update.Enabled = enabled
+	update.NextRunAt = ""
+	log.Debugf("blackout: %s/%s enabled=%t", jobID, sched.ID, enabled)
+	if _, err := controller.Client.UpdateSchedule(jobID, sched.ID, &update); err != nil {
+		return fmt.Errorf("schedule %s/%s: %s", jobID, sched.ID, err.Error())
+	}
+	return nil
+}
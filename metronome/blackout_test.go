@@ -0,0 +1,197 @@
+package metronome_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Blackout", func() {
+	utc := func(text string) time.Time {
+		t, err := time.Parse(time.RFC3339, text)
+		Expect(err).ShouldNot(HaveOccurred())
+		return t
+	}
+
+	Describe("Windows", func() {
+		It("Opens between start and end", func() {
+			blackout := &Blackout{Name: "holidays", Start: utc("2026-12-24T00:00:00Z"), End: utc("2026-12-27T00:00:00Z")}
+			Expect(blackout.Validate()).To(Succeed())
+			Expect(blackout.Active(utc("2026-12-23T23:59:00Z"))).To(BeFalse())
+			Expect(blackout.Active(utc("2026-12-24T00:00:00Z"))).To(BeTrue())
+			Expect(blackout.Active(utc("2026-12-27T00:00:00Z"))).To(BeFalse())
+		})
+
+		It("Opens for a duration each time the cron fires in its zone", func() {
+			blackout := &Blackout{Name: "friday", Cron: "0 22 * * FRI", Duration: "4h", Timezone: "Europe/Berlin"}
+			Expect(blackout.Validate()).To(Succeed())
+			// Friday 2026-10-16 22:00 in Berlin is 20:00 UTC
+			Expect(blackout.Active(utc("2026-10-16T19:59:00Z"))).To(BeFalse())
+			Expect(blackout.Active(utc("2026-10-16T20:00:00Z"))).To(BeTrue())
+			Expect(blackout.Active(utc("2026-10-16T23:59:00Z"))).To(BeTrue())
+			Expect(blackout.Active(utc("2026-10-17T00:00:00Z"))).To(BeFalse())
+		})
+
+		It("Parses and validates a blackout file", func() {
+			blackouts, err := ParseBlackouts([]byte(`{"blackouts":[{"name":"a","start":"2026-12-24T00:00:00Z","end":"2026-12-27T00:00:00Z","selector":{"team":"x"}}]}`))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(blackouts[0].Selector).To(Equal(Selector{{Key: "team", Operator: SelectorEquals, Values: []string{"x"}}}))
+			for _, doc := range []string{
+				`{"blackouts":[{"start":"2026-12-24T00:00:00Z","end":"2026-12-27T00:00:00Z"}]}`,
+				`{"blackouts":[{"name":"a","start":"2026-12-24T00:00:00Z","end":"2026-12-20T00:00:00Z"}]}`,
+				`{"blackouts":[{"name":"a","cron":"0 22 * * FRI"}]}`,
+				`{"blackouts":[{"name":"a","cron":"0 22 * * FRI","duration":"4h","start":"2026-12-24T00:00:00Z"}]}`,
+				`{"blackouts":[{"name":"a","cron":"0 22 * *","duration":"4h"}]}`,
+				`{"blackouts":[{"name":"a","cron":"0 22 * * *","duration":"4h","timezone":"Mars/Olympus"}]}`,
+				`{"blackouts":[{"name":"a","cron":"0 22 * * *","duration":"4h"},{"name":"a","cron":"0 1 * * *","duration":"1h"}]}`,
+				`{"blackouts":[{"name":"a","cron":"0 22 * * *","duration":"4h","selector":"team in (a"}]}`,
+				`{"blackouts":[{"name":"a","cron":"0 22 * * *","duration":"4h","selector":["team"]}]}`,
+			} {
+				_, err := ParseBlackouts([]byte(doc))
+				Expect(err).Should(HaveOccurred(), doc)
+			}
+		})
+	})
+
+	It("Matches jobs with a label selector", func() {
+		blackouts, err := ParseBlackouts([]byte(`{"blackouts":[{"name":"a","cron":"0 22 * * *","duration":"4h","selector":"team in (a,b),env!=dev"}]}`))
+		Expect(err).ShouldNot(HaveOccurred())
+		job := func(labels Labels) *Job { return &Job{ID: "report", Labels: &labels} }
+		Expect(blackouts[0].Matches(job(Labels{"team": "a"}))).To(BeTrue())
+		Expect(blackouts[0].Matches(job(Labels{"team": "b", "env": "prod"}))).To(BeTrue())
+		Expect(blackouts[0].Matches(job(Labels{"team": "a", "env": "dev"}))).To(BeFalse())
+		Expect(blackouts[0].Matches(&Job{ID: "unlabelled"})).To(BeFalse())
+		Expect((&Blackout{Name: "all"}).Matches(&Job{ID: "unlabelled"})).To(BeTrue())
+	})
+
+	Describe("BlackoutController", func() {
+		var (
+			server     *ghttp.Server
+			controller *BlackoutController
+			dir        string
+		)
+		jsonHeader := http.Header{"Content-Type": {"application/json"}}
+		jobs := `[{"id":"report","labels":{"team":"a"},"run":{"cmd":"true","cpus":1,"mem":64,"disk":0}},{"id":"other","labels":{"team":"b"},"run":{"cmd":"true","cpus":1,"mem":64,"disk":0}}]`
+		sched := func(id string, enabled bool) Schedule {
+			return Schedule{ID: id, Cron: "0 * * * *", ConcurrencyPolicy: "ALLOW", Enabled: enabled, StartingDeadlineSeconds: 60, Timezone: "UTC"}
+		}
+		listing := func() http.HandlerFunc {
+			return ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs"),
+				ghttp.RespondWith(http.StatusOK, jobs, jsonHeader),
+			)
+		}
+		schedules := func(jobID string, status int, scheds ...Schedule) http.HandlerFunc {
+			return ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs/"+jobID+"/schedules"),
+				ghttp.RespondWithJSONEncoded(status, scheds),
+			)
+		}
+		put := func(s Schedule, status int) http.HandlerFunc {
+			return ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/v1/jobs/report/schedules/"+s.ID),
+				ghttp.VerifyJSONRepresenting(s),
+				ghttp.RespondWith(status, `{}`, jsonHeader),
+			)
+		}
+		open := utc("2026-12-25T00:00:00Z")
+		closed := utc("2027-01-02T00:00:00Z")
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "blackout")
+			Expect(err).ShouldNot(HaveOccurred())
+			server = ghttp.NewServer()
+			server.AppendHandlers(ghttp.VerifyRequest("GET", "/v1/jobs"))
+			client, _ := NewClient(Config{URL: server.URL(), RequestTimeout: 5})
+			controller = &BlackoutController{
+				Client:    client,
+				Blackouts: []*Blackout{{Name: "holidays", Start: utc("2026-12-24T00:00:00Z"), End: utc("2026-12-27T00:00:00Z"), Selector: Selector{{Key: "team", Operator: SelectorEquals, Values: []string{"a"}}}}},
+				StateFile: filepath.Join(dir, "state.json"),
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+			os.RemoveAll(dir)
+		})
+
+		It("Disables matching schedules and restores exactly what they were", func() {
+			server.AppendHandlers(
+				listing(),
+				schedules("report", http.StatusOK, sched("on", true), sched("off", false)),
+				schedules("other", http.StatusOK, sched("theirs", true)),
+				put(sched("on", false), http.StatusOK),
+			)
+			actions, err := controller.Reconcile(open)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(actions).To(Equal([]string{"disable report/on (holidays)"}))
+			state, err := LoadBlackoutState(controller.StateFile)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(state.Paused).To(HaveLen(2))
+			Expect(state.Paused["report/on"].WasEnabled).To(BeTrue())
+			Expect(state.Paused["report/off"].WasEnabled).To(BeFalse())
+
+			server.AppendHandlers(
+				listing(),
+				schedules("report", http.StatusOK, sched("on", false), sched("off", false)),
+				schedules("other", http.StatusOK, sched("theirs", true)),
+				put(sched("on", true), http.StatusOK),
+			)
+			actions, err = controller.Reconcile(closed)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(actions).To(Equal([]string{"restore report/on enabled=true", "restore report/off enabled=false"}))
+			state, err = LoadBlackoutState(controller.StateFile)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(state.Paused).To(BeEmpty())
+			Expect(server.ReceivedRequests()).To(HaveLen(9))
+		})
+
+		It("Remembers the previous state before disabling so a failed or interrupted run can resume", func() {
+			server.AppendHandlers(
+				listing(),
+				schedules("report", http.StatusOK, sched("on", true)),
+				schedules("other", http.StatusOK),
+				put(sched("on", false), http.StatusInternalServerError),
+			)
+			_, err := controller.Reconcile(open)
+			Expect(err).Should(HaveOccurred())
+			state, err := LoadBlackoutState(controller.StateFile)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(state.Paused["report/on"].WasEnabled).To(BeTrue())
+
+			// the retry sees the schedule still enabled and finishes the job
+			server.AppendHandlers(
+				listing(),
+				schedules("report", http.StatusOK, sched("on", true)),
+				schedules("other", http.StatusOK),
+				put(sched("on", false), http.StatusOK),
+			)
+			actions, err := controller.Reconcile(open)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(actions).To(Equal([]string{"disable report/on (holidays)"}))
+		})
+
+		It("Changes nothing on a dry run", func() {
+			controller.DryRun = true
+			server.AppendHandlers(
+				listing(),
+				schedules("report", http.StatusOK, sched("on", true)),
+				schedules("other", http.StatusOK),
+			)
+			actions, err := controller.Reconcile(open)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(actions).To(Equal([]string{"disable report/on (holidays)"}))
+			_, err = os.Stat(controller.StateFile)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+})
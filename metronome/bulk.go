@@ -0,0 +1,61 @@
+package metronome
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DefaultParallelism - jobs acted on at once by ForEachJob when no limit is given
+const DefaultParallelism = 4
+
+// BulkResult - the outcome of a bulk action for one job
+type BulkResult struct {
+	JobID  string      `json:"jobId"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// ForEachJob - call `action` for each job id with at most `workers` calls at once.
+//  Results are in jobIDs order and keep what an action returned even with its error, e.g. the runs stopped before one
+//  failed; failed counts the actions that returned an error
+func ForEachJob(jobIDs []string, workers int, action func(jobID string) (interface{}, error)) (results []BulkResult, failed int) {
+	if workers < 1 {
+		workers = DefaultParallelism
+	}
+	results = make([]BulkResult, len(jobIDs))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(jobIDs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				results[i].JobID = jobIDs[i]
+				result, err := action(jobIDs[i])
+				results[i].Result = result
+				if err != nil {
+					results[i].Error = err.Error()
+				}
+			}
+		}()
+	}
+	for i := range jobIDs {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	return results, failed
+}
+
+// BulkError - some of a bulk action's jobs failed
+func BulkError(results []BulkResult, failed int) error {
+	if failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d jobs failed", failed, len(results))
+}
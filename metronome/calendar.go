@@ -0,0 +1,65 @@
+package metronome
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/adobe-platform/go-metronome/metronome/cron"
+)
+
+// Firing - one time a job's schedule fires, with the resources the job asks for
+type Firing struct {
+	JobID      string    `json:"jobId"`
+	ScheduleID string    `json:"scheduleId"`
+	At         time.Time `json:"at"`
+	Timezone   string    `json:"timezone"`
+	Cpus       float64   `json:"cpus"`
+	Mem        int       `json:"mem"`
+	Disk       int       `json:"disk"`
+}
+
+// MaxFirings - the most firings ExpandSchedules produces before giving up, e.g. a per-minute schedule over a few months
+const MaxFirings = 100000
+
+// ExpandSchedules - every time the enabled schedules of `jobs` fire in [from, to), sorted by time then job and schedule id.
+//  Each schedule is expanded in its own time zone; At is in that zone.  More than MaxFirings is an error
+func ExpandSchedules(jobs []*Job, from time.Time, to time.Time) ([]Firing, error) {
+	var firings []Firing
+	for _, job := range jobs {
+		for _, sched := range job.Schedules {
+			if !sched.Enabled {
+				continue
+			}
+			loc, err := sched.Location()
+			if err != nil {
+				return nil, fmt.Errorf("job %s schedule %s: %s", job.ID, sched.ID, err.Error())
+			}
+			parsed, err := cron.Parse(sched.Cron)
+			if err != nil {
+				return nil, fmt.Errorf("job %s schedule %s: %s", job.ID, sched.ID, err.Error())
+			}
+			for at := parsed.Next(from.Add(-time.Nanosecond), loc); !at.IsZero() && at.Before(to); at = parsed.Next(at, loc) {
+				firing := Firing{JobID: job.ID, ScheduleID: sched.ID, At: at, Timezone: loc.String()}
+				if job.Run != nil {
+					firing.Cpus, firing.Mem, firing.Disk = job.Run.Cpus, job.Run.Mem, job.Run.Disk
+				}
+				if len(firings) == MaxFirings {
+					return nil, fmt.Errorf("more than %d firings from %s to %s; narrow the range or the jobs", MaxFirings, from.Format(time.RFC3339), to.Format(time.RFC3339))
+				}
+				firings = append(firings, firing)
+			}
+		}
+	}
+	sort.SliceStable(firings, func(i, j int) bool {
+		a, b := firings[i], firings[j]
+		if !a.At.Equal(b.At) {
+			return a.At.Before(b.At)
+		}
+		if a.JobID != b.JobID {
+			return a.JobID < b.JobID
+		}
+		return a.ScheduleID < b.ScheduleID
+	})
+	return firings, nil
+}
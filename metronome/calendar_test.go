@@ -0,0 +1,62 @@
+package metronome_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Calendar", func() {
+	parse := func(doc string) *Job {
+		var job Job
+		Expect(json.Unmarshal([]byte(doc), &job)).To(Succeed())
+		return &job
+	}
+	jobs := []*Job{
+		parse(`{"id":"report","run":{"cmd":"true","cpus":0.5,"mem":128,"disk":0},"schedules":[
+			{"id":"hourly","cron":"0 * * * *","enabled":true,"timezone":"UTC"},
+			{"id":"off","cron":"* * * * *","enabled":false}]}`),
+		parse(`{"id":"backup","run":{"cmd":"true","cpus":2,"mem":1024,"disk":0},"schedules":[
+			{"id":"nightly","cron":"0 22 * * *","enabled":true,"timezone":"America/New_York"}]}`),
+	}
+	from := time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 19, 4, 0, 0, 0, time.UTC)
+
+	It("Expands enabled schedules in [from, to) sorted by time then job", func() {
+		firings, err := ExpandSchedules(jobs, from, to)
+		Expect(err).ShouldNot(HaveOccurred())
+		var got []string
+		for _, firing := range firings {
+			got = append(got, firing.At.UTC().Format("15:04")+" "+firing.JobID+"/"+firing.ScheduleID)
+		}
+		Expect(got).To(Equal([]string{"01:00 report/hourly", "02:00 backup/nightly", "02:00 report/hourly", "03:00 report/hourly"}))
+	})
+
+	It("Reports times in the schedule's zone with the job's resources", func() {
+		firings, err := ExpandSchedules(jobs, from, to)
+		Expect(err).ShouldNot(HaveOccurred())
+		backup := firings[1]
+		Expect(backup.Timezone).To(Equal("America/New_York"))
+		Expect(backup.At.Hour()).To(Equal(22))
+		Expect(backup.Cpus).To(Equal(2.0))
+		Expect(backup.Mem).To(Equal(1024))
+	})
+
+	It("Gives up past MaxFirings", func() {
+		busy := parse(`{"id":"busy","run":{"cmd":"true","cpus":1,"mem":64,"disk":0},"schedules":[{"id":"s","cron":"* * * * *","enabled":true}]}`)
+		_, err := ExpandSchedules([]*Job{busy}, from, from.Add(MaxFirings*time.Minute))
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = ExpandSchedules([]*Job{busy}, from, from.Add((MaxFirings+1)*time.Minute))
+		Expect(err).Should(MatchError(ContainSubstring("more than 100000 firings")))
+	})
+
+	It("Fails on a bad time zone", func() {
+		bad := parse(`{"id":"bad","run":{"cmd":"true","cpus":1,"mem":64,"disk":0},"schedules":[{"id":"s","cron":"* * * * *","enabled":true,"timezone":"Mars/Olympus"}]}`)
+		_, err := ExpandSchedules([]*Job{bad}, from, to)
+		Expect(err).Should(HaveOccurred())
+	})
+})
@@ -3,6 +3,7 @@ package metronome
 import (
 	"bytes"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"sync"
 	"time"
 
 	log "github.com/behance/go-logrus"
@@ -37,6 +39,12 @@ type Metronome interface {
 	Jobs() (*[]Job, error)
 	// PUT /v1/jobs/$jobId
 	UpdateJob(jobID string, job *Job) (interface{}, error)
+	// PUT /v1/jobs/$jobId unless the job's Fingerprint changed
+	UpdateJobIfMatch(jobID string, job *Job, fingerprint string) (interface{}, error)
+	// GET then PUT /v1/jobs/$jobId applying `mutate` in between
+	PatchJob(jobID string, mutate func(*Job) error) (*Job, error)
+	// PatchJob re-applying `mutate` on conflict, up to `attempts` times
+	PatchJobRetry(jobID string, attempts int, mutate func(*Job) error) (*Job, error)
 	//
 	// schedules
 	// GET /v1/jobs/$jobId/runs
@@ -44,6 +52,8 @@ type Metronome interface {
 	//   - since is milliseconds from epoch
 
 	Runs(jobID string, statusSince int64) (*Job, error)
+	// GET /v1/jobs/$jobId/runs - the active runs
+	RunLs(jobID string) (*[]JobStatus, error)
 	// POST /v1/jobs/$jobId/runs
 	StartJob(jobID string) (interface{}, error)
 	// GET /v1/jobs/$jobId/runs/$runId
@@ -64,6 +74,12 @@ type Metronome interface {
 	DeleteSchedule(jobID string, schedID string) (interface{}, error)
 	// PUT /v1/jobs/$jobId/schedules/$scheduleId
 	UpdateSchedule(jobID string, schedID string, sched *Schedule) (interface{}, error)
+	// GET then PUT /v1/jobs/$jobId/schedules/$scheduleId changing only enabled
+	SetScheduleEnabled(jobID string, schedID string, enabled bool) (*Schedule, bool, error)
+	// POST /v1/jobs/$jobId/schedules adding a one-time schedule firing at `at`
+	RunAt(jobID string, at time.Time) (*Schedule, error)
+	// DELETE /v1/jobs/$jobId/schedules/$scheduleId for one-time schedules past their deadline
+	CleanupRunAt(jobID string) ([]string, error)
 
 	//  GET  /v1/metrics
 	Metrics() (interface{}, error)
@@ -81,36 +97,68 @@ type Client struct {
 	url    *url.URL
 	config Config
 	http   *http.Client
+	// auth - guards config.AuthToken and TokenExpires, which RefreshToken replaces while other requests are in flight
+	auth sync.Mutex
+}
+
+// newTransport - proxy from the environment and tls verified per config
+func newTransport(config Config) (http.RoundTripper, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.AllowUnverifiedTLS,
+	}
+	if config.CAFile != "" {
+		pem, err := ioutil.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s: no PEM certificates", config.CAFile)
+		}
+	}
+	return &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}, nil
 }
 
 // NewClient returns a new  client, initialzed with the provided config
+//  The first of config's Endpoints that answers becomes the client's URL
 func NewClient(config Config) (Metronome, error) {
-	client := new(Client)
 	log.Debugf("NewClient started %+v", config)
-	var err error
-	client.url, err = url.Parse(config.URL)
+	PTransport, err := newTransport(config)
 	if err != nil {
 		return nil, err
 	}
-	client.config = config
-	var PTransport http.RoundTripper = &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: config.AllowUnverifiedTLS,
-		},
+	endpoints := config.Endpoints()
+	if len(endpoints) == 0 {
+		return nil, errors.New("metronome url required")
 	}
-
-	client.http = &http.Client{
-		Timeout:   (time.Duration(config.RequestTimeout) * time.Second),
-		Transport: PTransport,
+	var failures []string
+	for _, endpoint := range endpoints {
+		client := new(Client)
+		client.url, err = url.Parse(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		client.config = config
+		client.config.URL = endpoint
+		client.http = &http.Client{
+			Timeout:   (time.Duration(config.RequestTimeout) * time.Second),
+			Transport: PTransport,
+		}
+		// Verify you can reach metronome
+		if _, err = client.Jobs(); err != nil {
+			log.Debugf("metronome at %s: %s", endpoint, err.Error())
+			failures = append(failures, err.Error())
+			continue
+		}
+		return client, nil
 	}
-	// Verify you can reach metronome
-	_, err = client.Jobs()
-	if err != nil {
-		return nil, errors.New("Could not reach metronome cluster: " + err.Error())
+	if len(failures) == 1 {
+		return nil, errors.New("Could not reach metronome cluster: " + failures[0])
 	}
-
-	return client, nil
+	return nil, fmt.Errorf("Could not reach metronome cluster at any of %v: %s", endpoints, strings.Join(failures, "; "))
 }
 
 func (client *Client) apiGet(uri string, queryParams map[string][]string, result interface{}) (status int, err error) {
@@ -231,9 +279,41 @@ func (client *Client) applyRequestHeaders(request *http.Request) {
 	if client.config.User != "" && client.config.Pw != "" {
 		request.SetBasicAuth(client.config.User, client.config.Pw)
 	}
-	if client.config.AuthToken != "" {
-		request.Header.Add("Authorization", client.config.AuthToken)
+	if token := client.authToken(); token != "" {
+		request.Header.Add("Authorization", token)
+	}
+}
+
+// authToken - the Authorization header value
+func (client *Client) authToken() string {
+	client.auth.Lock()
+	defer client.auth.Unlock()
+	return client.config.AuthToken
+}
+
+// expiring - the token is about to expire and can be refreshed
+func (client *Client) expiring(now time.Time) bool {
+	client.auth.Lock()
+	defer client.auth.Unlock()
+	expires := client.config.TokenExpires
+	return client.config.RefreshToken != nil && !expires.IsZero() && !now.Add(RefreshMargin).Before(expires)
+}
+
+// refreshToken - replace the token `stale` using config.RefreshToken, unless a request running at the same time already has
+func (client *Client) refreshToken(stale string) error {
+	client.auth.Lock()
+	defer client.auth.Unlock()
+	if client.config.AuthToken != stale {
+		return nil
+	}
+	log.Debugf("refreshing the token for %s", client.config.URL)
+	token, err := client.config.RefreshToken()
+	if err != nil {
+		return fmt.Errorf("refreshing the token: %s", err.Error())
 	}
+	client.config.AuthToken = fmt.Sprintf("token=%s", token.Token)
+	client.config.TokenExpires = token.Expires
+	return nil
 }
 
 func (client *Client) newRequest(method string, url *url.URL, body string) (*http.Request, error) {
@@ -252,7 +332,16 @@ func (client *Client) newRequest(method string, url *url.URL, body string) (*htt
 	return request, nil
 }
 
+// httpCall - send the request.  With config.RefreshToken a token about to expire is refreshed first, and a 401
+//  answer refreshes it and sends the request once more, so long running commands outlive their token
 func (client *Client) httpCall(method string, url *url.URL, body string) (int, *http.Response, error) {
+	if client.expiring(time.Now()) {
+		if err := client.refreshToken(client.authToken()); err != nil {
+			return 0, nil, err
+		}
+	}
+	// the token sent, which a 401 says is stale.  Not the Authorization header: it holds basic auth when a user is set too
+	sent := client.authToken()
 	request, err := client.newRequest(method, url, body)
 
 	if err != nil {
@@ -265,6 +354,19 @@ func (client *Client) httpCall(method string, url *url.URL, body string) (int, *
 		return 0, nil, err
 	}
 
+	if response.StatusCode == http.StatusUnauthorized && client.config.RefreshToken != nil {
+		response.Body.Close()
+		if err = client.refreshToken(sent); err != nil {
+			return 0, nil, err
+		}
+		if request, err = client.newRequest(method, url, body); err != nil {
+			return 0, nil, err
+		}
+		if response, err = client.http.Do(request); err != nil {
+			return 0, nil, err
+		}
+	}
+
 	return response.StatusCode, response, nil
 }
 
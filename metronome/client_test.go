@@ -1,7 +1,10 @@
 package metronome_test
 
 import (
+	"encoding/pem"
+	"io/ioutil"
 	"net/http"
+	"os"
 
 	. "github.com/adobe-platform/go-metronome/metronome"
 
@@ -66,5 +69,44 @@ var _ = Describe("Client", func() {
 			_, err := NewClient(config_stub)
 			Expect(err).To(MatchError("Could not reach metronome cluster: 500 Internal Server Error"))
 		})
+
+		It("Fails over to the next url", func() {
+			down := ghttp.NewServer()
+			down.AppendHandlers(ghttp.RespondWith(http.StatusServiceUnavailable, nil))
+			defer down.Close()
+			server.AppendHandlers(ghttp.VerifyRequest("GET", "/v1/jobs"))
+
+			config_stub.URL = down.URL()
+			config_stub.URLs = []string{down.URL(), server.URL()}
+			_, err := NewClient(config_stub)
+			Expect(err).To(BeNil())
+			Expect(down.ReceivedRequests()).To(HaveLen(1))
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("Reports every url when none can be reached", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusServiceUnavailable, nil))
+			config_stub.URLs = []string{"http://127.0.0.1:1"}
+			_, err := NewClient(config_stub)
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("Could not reach metronome cluster at any of"))
+			Expect(err.Error()).To(ContainSubstring("503 Service Unavailable"))
+		})
+
+		It("Verifies tls with the certificates in CAFile", func() {
+			tlsServer := ghttp.NewTLSServer()
+			defer tlsServer.Close()
+			tlsServer.AppendHandlers(ghttp.VerifyRequest("GET", "/v1/jobs"))
+			file, err := ioutil.TempFile("", "ca")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.Remove(file.Name())
+			pem.Encode(file, &pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.HTTPTestServer.Certificate().Raw})
+			file.Close()
+
+			_, err = NewClient(Config{URL: tlsServer.URL(), RequestTimeout: 5})
+			Expect(err).Should(HaveOccurred())
+			_, err = NewClient(Config{URL: tlsServer.URL(), RequestTimeout: 5, CAFile: file.Name()})
+			Expect(err).To(BeNil())
+		})
 	})
 })
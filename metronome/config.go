@@ -1,5 +1,7 @@
 package metronome
 
+import "time"
+
 // A Config defines a client configuration
 type Config struct {
 	/* the url for metronome */
@@ -10,10 +12,19 @@ type Config struct {
 	RequestTimeout int
 	/* allow unverified tls (self-signed certs) defaults to false */
 	AllowUnverifiedTLS bool
+	/* more metronome urls tried in order when URL can't be reached */
+	URLs []string
+	/* PEM certificates to verify metronome's tls certificate with, instead of the system's */
+	CAFile string
 
 	AuthToken string
 	User      string
 	Pw        string
+
+	/* when AuthToken stops working, zero when unknown */
+	TokenExpires time.Time
+	/* fetches a new token when AuthToken is about to expire or metronome answers 401, e.g. by logging in again.  nil to never refresh */
+	RefreshToken func() (*LoginToken, error)
 }
 
 // NewDefaultConfig returns a default configuration.
@@ -24,3 +35,16 @@ func NewDefaultConfig() Config {
 		Debug:          false,
 		RequestTimeout: 5}
 }
+
+// Endpoints - URL then URLs, without blanks or repeats
+func (config *Config) Endpoints() []string {
+	endpoints := make([]string, 0, len(config.URLs)+1)
+	seen := make(map[string]bool)
+	for _, endpoint := range append([]string{config.URL}, config.URLs...) {
+		if endpoint != "" && !seen[endpoint] {
+			seen[endpoint] = true
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
@@ -1,9 +1,106 @@
 package metronome
 
+import (
+	"errors"
+	"fmt"
+)
+
 // A Container defines a metronome container
+//
+// Deprecated: never referenced by Run.  Use Run.Docker or Run.Ucr
 type Container struct {
 	Type    string              `json:"type,omitempty"`
 	Image   string              `json:"image,omitempty"`
 	Network string              `json:"network,omitempty"`
 	Volumes []map[string]string `json:"volumes,omitempty"`
 }
+
+// Image kinds understood by the Universal Container Runtime
+const (
+	ImageKindDocker = "docker"
+	ImageKindAppc   = "appc"
+)
+
+var errRuntimeViol = errors.New("docker and ucr are mutually exclusive")
+
+// PullConfig - registry credentials for a UCR image, read from the Run.Secrets entry `Secret`
+type PullConfig struct {
+	Secret string `json:"secret"`
+
+	Extra Extra `json:"-"`
+}
+
+// UcrImage - the image a UCR container runs
+type UcrImage struct {
+	ID         string      `json:"id"`
+	Kind       string      `json:"kind,omitempty"`
+	ForcePull  bool        `json:"forcePull,omitempty"`
+	PullConfig *PullConfig `json:"pullConfig,omitempty"`
+
+	Extra Extra `json:"-"`
+}
+
+// Ucr - Universal Container Runtime container spec.  Mutually exclusive with Run.Docker
+type Ucr struct {
+	Image      UcrImage `json:"image"`
+	Privileged bool     `json:"privileged,omitempty"`
+	Volumes    []Volume `json:"volumes,omitempty"`
+
+	Extra Extra `json:"-"`
+}
+
+// NewUcr - create a UCR container for `image` of `kind` (docker or appc)
+func NewUcr(image string, kind string) (*Ucr, error) {
+	if len(image) == 0 {
+		return nil, required("Ucr.image.id")
+	}
+	if kind == "" {
+		kind = ImageKindDocker
+	} else if kind != ImageKindDocker && kind != ImageKindAppc {
+		return nil, fmt.Errorf("Image kind must be '%s' or '%s' not %s", ImageKindDocker, ImageKindAppc, kind)
+	}
+	return &Ucr{Image: UcrImage{ID: image, Kind: kind}}, nil
+}
+
+// GetImage - the image
+func (ucr *Ucr) GetImage() *UcrImage {
+	return &ucr.Image
+}
+
+// SetForcePull - pull the image on every launch
+func (ucr *Ucr) SetForcePull(force bool) *Ucr {
+	ucr.Image.ForcePull = force
+	return ucr
+}
+
+// SetPullSecret - authenticate image pulls with the Run.Secrets entry `secret`
+func (ucr *Ucr) SetPullSecret(secret string) *Ucr {
+	if secret == "" {
+		ucr.Image.PullConfig = nil
+	} else {
+		ucr.Image.PullConfig = &PullConfig{Secret: secret}
+	}
+	return ucr
+}
+
+// IsPrivileged - does the container run privileged
+func (ucr *Ucr) IsPrivileged() bool {
+	return ucr.Privileged
+}
+
+// SetPrivileged - run the container privileged
+func (ucr *Ucr) SetPrivileged(privileged bool) *Ucr {
+	ucr.Privileged = privileged
+	return ucr
+}
+
+// GetVolumes - the container's volume mappings
+func (ucr *Ucr) GetVolumes() []Volume {
+	return ucr.Volumes
+}
+
+// SetVolumes - replace the container's volume mappings
+func (ucr *Ucr) SetVolumes(vols []Volume) *Ucr {
+	ucr.Volumes = vols
+	return ucr
+}
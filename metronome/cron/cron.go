@@ -0,0 +1,264 @@
+// Package cron parses Metronome's 5 field cron dialect and computes when a schedule fires.
+//
+// Fields are minute, hour, day of month, month and day of week.  Each takes `*`, a number, a range `1-5`,
+// a step `*/15`, `1-30/2` or `5/15` (5 to the end in steps of 15), or a comma separated list of those.
+// Months and days of week also take their three letter English names (JAN, MON); day of week 7 is Sunday like 0.
+// As in Vixie cron, when both day of month and day of week are restricted a day matching either fires.
+// Jenkins style H tokens (H, H(0-5), H/15) are not cron; ExpandHash turns them into concrete values first.
+//
+// Fire times are computed on the wall clock of an IANA time zone.  Across a daylight saving change:
+//   - a wall time skipped when clocks go forward fires shifted forward by the length of the gap (02:30 becomes 03:30),
+//     the same resolution the JVM, and so metronome, uses
+//   - a wall time repeated when clocks go back fires once, at its first occurrence
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field - bounds and symbolic names of a single cron field
+type field struct {
+	name  string
+	min   int
+	max   int
+	names []string
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+}
+
+// searchYears - how far Next looks before deciding a schedule never fires.  Long enough for Feb 29 on a given weekday
+const searchYears = 28
+
+// dstWindow - more than any daylight saving shift
+const dstWindow = 3 * time.Hour
+
+// Schedule - a parsed cron expression
+type Schedule struct {
+	expr    string
+	minute  uint64
+	hour    uint64
+	dom     uint64
+	month   uint64
+	dow     uint64
+	domStar bool
+	dowStar bool
+}
+
+// Parse - parse and validate a 5 field cron expression
+func Parse(expr string) (*Schedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("'%s' must have %d fields, found %d", expr, len(fields), len(parts))
+	}
+	var bits [5]uint64
+	for i, part := range parts {
+		var err error
+		if bits[i], err = fields[i].parse(part); err != nil {
+			return nil, err
+		}
+	}
+	sched := &Schedule{
+		expr:    expr,
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(parts[2], "*"),
+		dowStar: strings.HasPrefix(parts[4], "*"),
+	}
+	// 7 is another name for Sunday
+	if sched.dow&(1<<7) != 0 {
+		sched.dow |= 1
+	}
+	return sched, nil
+}
+
+// Validate - nil when `expr` parses
+func Validate(expr string) error {
+	_, err := Parse(expr)
+	return err
+}
+
+// String - the expression as given to Parse
+func (sched *Schedule) String() string {
+	return sched.expr
+}
+
+func (f field) value(tok string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(tok, name) {
+			return f.min + i, nil
+		}
+	}
+	val, err := strconv.Atoi(tok)
+	if err != nil {
+		return 0, fmt.Errorf("%s: '%s' is not a number", f.name, tok)
+	}
+	if val < f.min || val > f.max {
+		return 0, fmt.Errorf("%s: %d out of range %d-%d", f.name, val, f.min, f.max)
+	}
+	return val, nil
+}
+
+// parse - the set of values `text` selects as a bitset
+func (f field) parse(text string) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(text, ",") {
+		rng, step := item, 1
+		if parts := strings.SplitN(item, "/", 2); len(parts) == 2 {
+			rng = parts[0]
+			var err error
+			if step, err = strconv.Atoi(parts[1]); err != nil || step < 1 {
+				return 0, fmt.Errorf("%s: bad step in '%s'", f.name, item)
+			}
+		}
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = f.value(bounds[1]); err != nil {
+					return 0, err
+				}
+				if hi < lo {
+					return 0, fmt.Errorf("%s: bad range '%s'", f.name, rng)
+				}
+			} else if len(rng) < len(item) {
+				// 5/15 runs from 5 to the end of the field
+				hi = f.max
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+// dayMatches - the day of month/day of week rule: both must match unless both are restricted, then either will do
+func (sched *Schedule) dayMatches(year int, month time.Month, day int) bool {
+	if !has(sched.month, int(month)) {
+		return false
+	}
+	domOK := has(sched.dom, day)
+	dowOK := has(sched.dow, int(time.Date(year, month, day, 12, 0, 0, 0, time.UTC).Weekday()))
+	if sched.domStar || sched.dowStar {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+// offsetAt - the zone offset in seconds in effect at `t` in `loc`
+func offsetAt(t time.Time, loc *time.Location) int {
+	_, offset := t.In(loc).Zone()
+	return offset
+}
+
+// resolve - the instant the wall clock time reads y-m-d h:min in `loc`.  See the package comment for daylight saving changes
+func resolve(year int, month time.Month, day int, hour int, min int, loc *time.Location) time.Time {
+	wall := time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	before := offsetAt(wall.Add(-24*time.Hour), loc)
+	after := offsetAt(wall.Add(24*time.Hour), loc)
+	var first time.Time
+	for _, offset := range []int{before, after} {
+		t := wall.Add(-time.Duration(offset) * time.Second)
+		local := t.In(loc)
+		if local.Hour() != hour || local.Minute() != min || local.Day() != day {
+			continue
+		}
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+	}
+	if first.IsZero() {
+		// skipped by a spring forward: read the wall clock with the offset from before the change
+		first = wall.Add(-time.Duration(before) * time.Second)
+	}
+	return first.In(loc)
+}
+
+// nearTransition - whether the zone's offset changes within dstWindow of `t`
+func nearTransition(t time.Time, loc *time.Location) bool {
+	return offsetAt(t.Add(-dstWindow), loc) != offsetAt(t.Add(dstWindow), loc)
+}
+
+// Next - the first time strictly after `after` the schedule fires in `loc`, or the zero time if it never does (e.g. 30 2 *)
+func (sched *Schedule) Next(after time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	// wall times skipped by a spring forward fire shifted past the gap, so they can come after later wall times.
+	// Near a change start a little before `after` and look a little past the first match for an earlier instant
+	start := after.In(loc)
+	if nearTransition(after, loc) {
+		start = start.Add(-dstWindow)
+	}
+	start = start.Truncate(time.Minute)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	end := day.AddDate(searchYears, 0, 0)
+	var best, bestWall time.Time
+	for first := true; day.Before(end); day, first = day.AddDate(0, 0, 1), false {
+		if !best.IsZero() && day.Sub(bestWall) > dstWindow {
+			break
+		}
+		if !sched.dayMatches(day.Year(), day.Month(), day.Day()) {
+			continue
+		}
+		for hour := 0; hour < 24; hour++ {
+			if !has(sched.hour, hour) || first && hour < start.Hour() {
+				continue
+			}
+			for min := 0; min < 60; min++ {
+				if !has(sched.minute, min) || first && hour == start.Hour() && min < start.Minute() {
+					continue
+				}
+				wall := day.Add(time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute)
+				if !best.IsZero() && wall.Sub(bestWall) > dstWindow {
+					return best
+				}
+				if t := resolve(day.Year(), day.Month(), day.Day(), hour, min, loc); t.After(after) && (best.IsZero() || t.Before(best)) {
+					if best.IsZero() && !nearTransition(t, loc) {
+						return t
+					}
+					best = t
+					if bestWall.IsZero() {
+						bestWall = wall
+					}
+				}
+			}
+		}
+	}
+	return best
+}
+
+// NextN - the next `n` fire times after `after` in `loc`.  Shorter than n if the schedule stops firing
+func (sched *Schedule) NextN(after time.Time, loc *time.Location, n int) []time.Time {
+	times := make([]time.Time, 0, n)
+	for len(times) < n {
+		next := sched.Next(after, loc)
+		if next.IsZero() {
+			break
+		}
+		times = append(times, next)
+		after = next
+	}
+	return times
+}
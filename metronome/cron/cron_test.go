@@ -0,0 +1,134 @@
+package cron_test
+
+import (
+	"time"
+
+	. "github.com/adobe-platform/go-metronome/metronome/cron"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cron", func() {
+	utc := func(text string) time.Time {
+		t, err := time.Parse(time.RFC3339, text)
+		Expect(err).ShouldNot(HaveOccurred())
+		return t
+	}
+	parse := func(expr string) *Schedule {
+		sched, err := Parse(expr)
+		Expect(err).ShouldNot(HaveOccurred(), expr)
+		return sched
+	}
+	asUTC := func(times ...time.Time) []time.Time {
+		for i := range times {
+			times[i] = times[i].UTC()
+		}
+		return times
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		panic(err)
+	}
+
+	Describe("Parse", func() {
+		It("Accepts names, ranges, lists and steps", func() {
+			for _, expr := range []string{"*/2 * * * *", "0 0 1 JAN,jul MON-FRI", "0-30/5 9-17 * * 1-5", "5/15 * * * 7", "0 12 1,15 * *"} {
+				Expect(Validate(expr)).To(Succeed(), expr)
+			}
+		})
+
+		It("Rejects malformed expressions", func() {
+			for expr, msg := range map[string]string{
+				"* * * *":       "'* * * *' must have 5 fields, found 4",
+				"*/0 * * * *":   "minute: bad step in '*/0'",
+				"* 24 * * *":    "hour: 24 out of range 0-23",
+				"* * 0 * *":     "day of month: 0 out of range 1-31",
+				"* * * * 8":     "day of week: 8 out of range 0-7",
+				"5-1 * * * *":   "minute: bad range '5-1'",
+				"a * * * *":     "minute: 'a' is not a number",
+				"* * * FOO *":   "month: 'FOO' is not a number",
+				"* * * * * 201": "'* * * * * 201' must have 5 fields, found 6",
+			} {
+				Expect(Validate(expr)).To(MatchError(msg), expr)
+			}
+		})
+	})
+
+	Describe("Next", func() {
+		It("Finds the next minute strictly after", func() {
+			sched := parse("*/15 * * * *")
+			Expect(sched.Next(utc("2026-10-18T10:07:30Z"), time.UTC)).To(Equal(utc("2026-10-18T10:15:00Z")))
+			Expect(sched.Next(utc("2026-10-18T10:15:00Z"), time.UTC)).To(Equal(utc("2026-10-18T10:30:00Z")))
+		})
+
+		It("Lists the next n", func() {
+			times := parse("0 9 * * MON-FRI").NextN(utc("2026-10-16T12:00:00Z"), time.UTC, 3)
+			Expect(times).To(Equal([]time.Time{utc("2026-10-19T09:00:00Z"), utc("2026-10-20T09:00:00Z"), utc("2026-10-21T09:00:00Z")}))
+		})
+
+		It("Fires on either day of month or day of week when both are restricted", func() {
+			times := parse("0 0 13 * FRI").NextN(utc("2026-11-01T00:00:00Z"), time.UTC, 3)
+			Expect(times).To(Equal([]time.Time{utc("2026-11-06T00:00:00Z"), utc("2026-11-13T00:00:00Z"), utc("2026-11-20T00:00:00Z")}))
+			Expect(parse("0 0 * * 7").Next(utc("2026-10-18T12:00:00Z"), time.UTC)).To(Equal(utc("2026-10-25T00:00:00Z")))
+		})
+
+		It("Handles leap days and impossible dates", func() {
+			Expect(parse("0 0 29 2 *").Next(utc("2026-10-18T00:00:00Z"), time.UTC)).To(Equal(utc("2028-02-29T00:00:00Z")))
+			Expect(parse("0 0 30 2 *").Next(utc("2026-10-18T00:00:00Z"), time.UTC).IsZero()).To(BeTrue())
+			Expect(parse("0 0 30 2 *").NextN(utc("2026-10-18T00:00:00Z"), time.UTC, 5)).To(BeEmpty())
+		})
+
+		It("Uses the wall clock of the time zone", func() {
+			next := parse("0 9 * * *").Next(utc("2026-10-18T12:00:00Z"), newYork)
+			Expect(next).To(BeTemporally("==", utc("2026-10-18T13:00:00Z")))
+			Expect(next.Location()).To(Equal(newYork))
+			Expect(parse("0 9 * * *").Next(utc("2026-12-18T12:00:00Z"), newYork)).To(BeTemporally("==", utc("2026-12-18T14:00:00Z")))
+		})
+
+		It("Shifts wall times skipped by a spring forward past the gap", func() {
+			// 2026-03-08 02:00 EST jumps to 03:00 EDT
+			Expect(asUTC(parse("30 2 * * *").NextN(utc("2026-03-07T12:00:00Z"), newYork, 2)...)).To(Equal([]time.Time{
+				utc("2026-03-08T07:30:00Z"), // 03:30 EDT
+				utc("2026-03-09T06:30:00Z"), // 02:30 EDT
+			}))
+			Expect(asUTC(parse("0,30 2-3 * * *").NextN(utc("2026-03-08T06:00:00Z"), newYork, 3)...)).To(Equal([]time.Time{
+				utc("2026-03-08T07:00:00Z"), // 02:00 and 03:00 are both 03:00 EDT
+				utc("2026-03-08T07:30:00Z"),
+				utc("2026-03-09T06:00:00Z"),
+			}))
+			Expect(parse("30 2 * * *").Next(utc("2026-03-08T07:10:00Z"), newYork)).To(BeTemporally("==", utc("2026-03-08T07:30:00Z")))
+		})
+
+		It("Fires a wall time repeated by a fall back once", func() {
+			// 2026-11-01 02:00 EDT falls back to 01:00 EST
+			Expect(asUTC(parse("30 1 * * *").NextN(utc("2026-10-31T12:00:00Z"), newYork, 2)...)).To(Equal([]time.Time{
+				utc("2026-11-01T05:30:00Z"), // 01:30 EDT
+				utc("2026-11-02T06:30:00Z"), // 01:30 EST
+			}))
+			Expect(asUTC(parse("0 * * * *").NextN(utc("2026-11-01T04:30:00Z"), newYork, 3)...)).To(Equal([]time.Time{
+				utc("2026-11-01T05:00:00Z"), // 01:00 EDT
+				utc("2026-11-01T07:00:00Z"), // 02:00 EST
+				utc("2026-11-01T08:00:00Z"),
+			}))
+			Expect(parse("*/30 * * * *").Next(utc("2026-11-01T06:10:00Z"), newYork)).To(BeTemporally("==", utc("2026-11-01T07:00:00Z")))
+		})
+
+		It("Steps a per-minute schedule minute by minute across the changes", func() {
+			everyMinute := parse("* * * * *")
+			for _, from := range []string{"2026-03-08T04:00:00Z", "2026-06-01T00:00:00Z"} {
+				times := everyMinute.NextN(utc(from), newYork, 8*60)
+				Expect(times).To(HaveLen(8 * 60))
+				for i, next := range times {
+					Expect(next).To(BeTemporally("==", utc(from).Add(time.Duration(i+1)*time.Minute)), from)
+				}
+			}
+			// the repeated 01:xx fires once
+			Expect(asUTC(everyMinute.NextN(utc("2026-11-01T05:58:00Z"), newYork, 3)...)).To(Equal([]time.Time{
+				utc("2026-11-01T05:59:00Z"), // 01:59 EDT
+				utc("2026-11-01T07:00:00Z"), // 02:00 EST
+				utc("2026-11-01T07:01:00Z"),
+			}))
+		})
+	})
+})
@@ -0,0 +1,91 @@
+package cron
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hashRe - Jenkins style hash tokens: H, H(lo-hi), H/step, H(lo-hi)/step
+var hashRe = regexp.MustCompile(`^H(?:\((\d+)-(\d+)\))?(?:/(\d+))?$`)
+
+// hashMax - H picks from a narrower range than the field allows for day of month (every month has a 28th) and day of week (7 is 0)
+var hashMax = map[string]int{"day of month": 28, "day of week": 6}
+
+// HasHash - the expression uses H tokens
+func HasHash(expr string) bool {
+	for _, part := range strings.Fields(expr) {
+		for _, item := range strings.Split(part, ",") {
+			if strings.HasPrefix(item, "H") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ExpandHash - replace H tokens with concrete values picked by hashing `seed`, usually the job id, so the same job always
+//  gets the same minutes and different jobs are spread out.  Repeated tokens in a field, e.g. H,H, hash apart.  In each field:
+//   - H is one value in the field's range, H(lo-hi) one value between lo and hi
+//   - H/step and H(lo-hi)/step run every step from a hashed offset e.g. H/15 becomes 7-59/15
+//  Day of month picks from 1-28.  An expression without H tokens is returned as is
+func ExpandHash(expr string, seed string) (string, error) {
+	if !HasHash(expr) {
+		return expr, nil
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return "", fmt.Errorf("'%s' must have %d fields, found %d", expr, len(fields), len(parts))
+	}
+	for i, part := range parts {
+		items := strings.Split(part, ",")
+		for j, item := range items {
+			if !strings.HasPrefix(item, "H") {
+				continue
+			}
+			value, err := fields[i].hash(item, seed, j)
+			if err != nil {
+				return "", err
+			}
+			items[j] = value
+		}
+		parts[i] = strings.Join(items, ",")
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// hash - the concrete value of an H token, the `index`th item of its field.  The first item hashes the seed alone so
+//  expressions with a single H per field keep the values they always had
+func (f field) hash(token string, seed string, index int) (string, error) {
+	match := hashRe.FindStringSubmatch(token)
+	if match == nil {
+		return "", fmt.Errorf("%s: bad hash token '%s'", f.name, token)
+	}
+	lo, hi := f.min, f.max
+	if max, ok := hashMax[f.name]; ok {
+		hi = max
+	}
+	if match[1] != "" {
+		lo, _ = strconv.Atoi(match[1])
+		hi, _ = strconv.Atoi(match[2])
+		if lo < f.min || hi > f.max || hi < lo {
+			return "", fmt.Errorf("%s: bad range in '%s', must be within %d-%d", f.name, token, f.min, f.max)
+		}
+	}
+	h := fnv.New32a()
+	h.Write([]byte(seed + "\x00" + f.name))
+	if index > 0 {
+		h.Write([]byte("\x00" + strconv.Itoa(index)))
+	}
+	sum := int(h.Sum32() & 0x7fffffff)
+	if match[3] == "" {
+		return strconv.Itoa(lo + sum%(hi-lo+1)), nil
+	}
+	step, _ := strconv.Atoi(match[3])
+	if step < 1 || step > hi-lo+1 {
+		return "", fmt.Errorf("%s: bad step in '%s'", f.name, token)
+	}
+	return fmt.Sprintf("%d-%d/%d", lo+sum%step, hi, step), nil
+}
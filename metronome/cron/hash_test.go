@@ -0,0 +1,83 @@
+package cron_test
+
+import (
+	"strconv"
+	"strings"
+
+	. "github.com/adobe-platform/go-metronome/metronome/cron"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hash", func() {
+	expand := func(expr string, seed string) string {
+		out, err := ExpandHash(expr, seed)
+		Expect(err).ShouldNot(HaveOccurred(), expr)
+		Expect(Validate(out)).To(Succeed(), out)
+		return out
+	}
+	field := func(expr string, i int) int {
+		v, err := strconv.Atoi(strings.Fields(expr)[i])
+		Expect(err).ShouldNot(HaveOccurred(), expr)
+		return v
+	}
+
+	It("Leaves expressions without H alone", func() {
+		Expect(HasHash("0 * * * *")).To(BeFalse())
+		Expect(ExpandHash("0 * * * *", "report")).To(Equal("0 * * * *"))
+		Expect(HasHash("H H(0-5) * * *")).To(BeTrue())
+		Expect(HasHash("0,H * * * *")).To(BeTrue())
+	})
+
+	It("Is deterministic per seed and spreads different seeds", func() {
+		Expect(expand("H H(0-5) * * *", "report")).To(Equal(expand("H H(0-5) * * *", "report")))
+		minutes := make(map[int]bool)
+		for i := 0; i < 50; i++ {
+			minutes[field(expand("H * * * *", "job-"+strconv.Itoa(i)), 0)] = true
+		}
+		Expect(len(minutes)).To(BeNumerically(">", 20))
+	})
+
+	It("Keeps values in range", func() {
+		for i := 0; i < 200; i++ {
+			seed := "job-" + strconv.Itoa(i)
+			out := expand("H H(0-5) H * H(1-5)", seed)
+			Expect(field(out, 1)).To(BeNumerically("<=", 5))
+			Expect(field(out, 2)).To(BeNumerically("<=", 28))
+			Expect(field(out, 2)).To(BeNumerically(">=", 1))
+			Expect(field(out, 4)).To(BeNumerically(">=", 1))
+			Expect(field(out, 4)).To(BeNumerically("<=", 5))
+		}
+	})
+
+	It("Expands steps from a hashed offset", func() {
+		out := expand("H/15 H(8-20)/4 * * *", "report")
+		parts := strings.Fields(out)
+		Expect(parts[0]).To(MatchRegexp(`^([0-9]|1[0-4])-59/15$`))
+		Expect(parts[1]).To(MatchRegexp(`^(8|9|10|11)-20/4$`))
+		Expect(expand("1,H 0 * * *", "report")).To(MatchRegexp(`^1,\d+ 0 \* \* \*$`))
+	})
+
+	It("Hashes repeated tokens in a field apart and keeps the first one's value", func() {
+		single := field(expand("H * * * *", "report"), 0)
+		distinct := 0
+		for i := 0; i < 20; i++ {
+			seed := "job-" + strconv.Itoa(i)
+			items := strings.Split(strings.Fields(expand("H(0-29),H(0-29) * * * *", seed))[0], ",")
+			if items[0] != items[1] {
+				distinct++
+			}
+		}
+		Expect(distinct).To(BeNumerically(">", 15))
+		Expect(strings.Split(strings.Fields(expand("H,H * * * *", "report"))[0], ",")[0]).To(Equal(strconv.Itoa(single)))
+	})
+
+	It("Rejects bad tokens", func() {
+		for _, expr := range []string{"H(0-60) * * * *", "H(5-2) * * * *", "Hx * * * *", "H/0 * * * *", "H/61 * * * *", "H * * *"} {
+			_, err := ExpandHash(expr, "report")
+			Expect(err).Should(HaveOccurred(), expr)
+		}
+		Expect(Validate("H * * * *")).ShouldNot(Succeed())
+	})
+})
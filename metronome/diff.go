@@ -0,0 +1,275 @@
+package metronome
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Change operations
+const (
+	ChangeAdd    = "add"
+	ChangeRemove = "remove"
+	ChangeModify = "modify"
+)
+
+// Change - one difference between two jobs.  Path is in FieldError form except schedules, which are keyed by id: schedules.nightly.cron
+type Change struct {
+	Path string      `json:"path"`
+	Op   string      `json:"op"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// String - e.g. "modify run.cpus: 0.5 -> 1"
+func (change Change) String() string {
+	before, _ := json.Marshal(change.Old)
+	after, _ := json.Marshal(change.New)
+	switch change.Op {
+	case ChangeAdd:
+		return fmt.Sprintf("%s %s: %s", change.Op, change.Path, after)
+	case ChangeRemove:
+		return fmt.Sprintf("%s %s: %s", change.Op, change.Path, before)
+	}
+	return fmt.Sprintf("%s %s: %s -> %s", change.Op, change.Path, before, after)
+}
+
+// specDefaults - what metronome fills in when a field is left out.  `*` matches any schedule id
+var specDefaults = map[string]interface{}{
+	"run.disk":                            0,
+	"run.gpus":                            0,
+	"run.maxLaunchDelay":                  3600,
+	"run.taskKillGracePeriodSeconds":      0,
+	"run.restart.policy":                  "NEVER",
+	"run.restart.activeDeadlineSeconds":   0,
+	"run.docker.forcePullImage":           false,
+	"run.docker.privileged":               false,
+	"run.ucr.privileged":                  false,
+	"run.ucr.image.kind":                  ImageKindDocker,
+	"run.ucr.image.forcePull":             false,
+	"schedules.*.concurrencyPolicy":       "ALLOW",
+	"schedules.*.enabled":                 true,
+	"schedules.*.startingDeadlineSeconds": 900,
+	"schedules.*.timezone":                "UTC",
+}
+
+// sameJSON - equal once encoded.  Numbers decoded with UseNumber compare by their text so 60 and 60.0 differ; metronome never sends the latter
+func sameJSON(a interface{}, b interface{}) bool {
+	l, lerr := json.Marshal(a)
+	r, rerr := json.Marshal(b)
+	return lerr == nil && rerr == nil && string(l) == string(r)
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// normalize - drop members that are empty or hold metronome's default so a spec and the job metronome returns for it compare equal
+func normalize(value interface{}, pattern string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, member := range v {
+			childPattern := name
+			if pattern == "schedules" {
+				childPattern = "*"
+			}
+			if pattern != "" {
+				childPattern = pattern + "." + childPattern
+			}
+			member = normalize(member, childPattern)
+			if def, ok := specDefaults[childPattern]; isEmpty(member) || ok && sameJSON(member, def) {
+				delete(v, name)
+			} else {
+				v[name] = member
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalize(item, pattern+"[]")
+		}
+	}
+	return value
+}
+
+// specDoc - the job spec plus its schedules keyed by id, without read-only fields, as a json document
+func specDoc(job *Job) (map[string]interface{}, error) {
+	raw, err := json.Marshal(job.Spec())
+	if err != nil {
+		return nil, err
+	}
+	doc, err := decodeDoc(raw)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("job %s is not a json object", job.ID)
+	}
+	if len(job.Schedules) > 0 {
+		scheds := make(map[string]interface{})
+		for _, sched := range job.Schedules {
+			if _, dup := scheds[sched.ID]; dup {
+				return nil, fmt.Errorf("job %s has schedule %s twice", job.ID, sched.ID)
+			}
+			schedDoc, err := scheduleDoc(sched)
+			if err != nil {
+				return nil, err
+			}
+			scheds[sched.ID] = schedDoc
+		}
+		obj["schedules"] = scheds
+	}
+	return obj, nil
+}
+
+// scheduleDoc - the schedule as a json document without the read-only nextRunAt
+func scheduleDoc(sched *Schedule) (interface{}, error) {
+	spec := *sched
+	spec.NextRunAt = ""
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDoc(raw)
+}
+
+// schedulesAsList - turn schedules keyed by id back into the list metronome takes, sorted by id
+func schedulesAsList(doc map[string]interface{}) {
+	scheds, ok := doc["schedules"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	ids := make([]string, 0, len(scheds))
+	for id := range scheds {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	list := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		sched := scheds[id]
+		// normalizing may have dropped the id along with the defaults; it's the key
+		if obj, ok := sched.(map[string]interface{}); ok {
+			obj["id"] = id
+		} else {
+			sched = map[string]interface{}{"id": id}
+		}
+		list = append(list, sched)
+	}
+	doc["schedules"] = list
+}
+
+// CanonicalJob - the job spec and schedules as indented json with members and schedules sorted.
+//  History, active runs and schedules' nextRunAt are left out so the result can be posted back to metronome
+func CanonicalJob(job *Job) ([]byte, error) {
+	doc, err := specDoc(job)
+	if err != nil {
+		return nil, err
+	}
+	schedulesAsList(doc)
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// NormalizedJob - CanonicalJob without the members that are empty or metronome's default.  Suitable for showing differences
+func NormalizedJob(job *Job) ([]byte, error) {
+	doc, err := specDoc(job)
+	if err != nil {
+		return nil, err
+	}
+	normalize(doc, "")
+	schedulesAsList(doc)
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// diffValue - append the changes taking `before` to `after` under `path`
+func diffValue(changes []Change, path string, before interface{}, after interface{}) []Change {
+	oldObj, oldIsObj := before.(map[string]interface{})
+	newObj, newIsObj := after.(map[string]interface{})
+	if oldIsObj && newIsObj {
+		names := make(map[string]bool)
+		for name := range oldObj {
+			names[name] = true
+		}
+		for name := range newObj {
+			names[name] = true
+		}
+		sorted := make([]string, 0, len(names))
+		for name := range names {
+			sorted = append(sorted, name)
+		}
+		sort.Strings(sorted)
+		for _, name := range sorted {
+			child := name
+			if path != "" {
+				child = path + "." + name
+			}
+			o, inOld := oldObj[name]
+			n, inNew := newObj[name]
+			switch {
+			case !inOld:
+				changes = append(changes, Change{Path: child, Op: ChangeAdd, New: n})
+			case !inNew:
+				changes = append(changes, Change{Path: child, Op: ChangeRemove, Old: o})
+			default:
+				changes = diffValue(changes, child, o, n)
+			}
+		}
+		return changes
+	}
+	oldArr, oldIsArr := before.([]interface{})
+	newArr, newIsArr := after.([]interface{})
+	if oldIsArr && newIsArr {
+		for i := 0; i < len(oldArr) || i < len(newArr); i++ {
+			child := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(oldArr):
+				changes = append(changes, Change{Path: child, Op: ChangeAdd, New: newArr[i]})
+			case i >= len(newArr):
+				changes = append(changes, Change{Path: child, Op: ChangeRemove, Old: oldArr[i]})
+			default:
+				changes = diffValue(changes, child, oldArr[i], newArr[i])
+			}
+		}
+		return changes
+	}
+	if !sameJSON(before, after) {
+		changes = append(changes, Change{Path: path, Op: ChangeModify, Old: before, New: after})
+	}
+	return changes
+}
+
+// DiffJobs - the changes taking job `a` to job `b`, sorted by path.  Empty when they are equivalent.
+//  Defaults metronome fills in are normalized away, read-only fields (history, activeRuns, nextRunAt) ignored and schedules matched by id
+func DiffJobs(a *Job, b *Job) ([]Change, error) {
+	before, err := specDoc(a)
+	if err != nil {
+		return nil, err
+	}
+	after, err := specDoc(b)
+	if err != nil {
+		return nil, err
+	}
+	return diffValue(nil, "", normalize(before, ""), normalize(after, "")), nil
+}
+
+// DiffSchedules - the changes taking schedule `a` to `b` with the same normalization as DiffJobs.  Paths are relative to the schedule
+func DiffSchedules(a *Schedule, b *Schedule) ([]Change, error) {
+	before, err := scheduleDoc(a)
+	if err != nil {
+		return nil, err
+	}
+	after, err := scheduleDoc(b)
+	if err != nil {
+		return nil, err
+	}
+	return diffValue(nil, "", normalize(before, "schedules.*"), normalize(after, "schedules.*")), nil
+}
@@ -0,0 +1,75 @@
+package metronome_test
+
+import (
+	"encoding/json"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff", func() {
+	parse := func(doc string) *Job {
+		var job Job
+		Expect(json.Unmarshal([]byte(doc), &job)).To(Succeed())
+		return &job
+	}
+	// what metronome hands back for `spec`: defaults filled in, history and nextRunAt added
+	const live = `{"id":"diff.job","description":"","labels":{},"run":{"cmd":"true","cpus":0.5,"mem":64,"disk":0,"maxLaunchDelay":3600,"env":{},"artifacts":[],"volumes":[],"placement":{"constraints":[]},"restart":{"policy":"NEVER"}},
+		"schedules":[{"id":"b","cron":"0 2 * * *","concurrencyPolicy":"ALLOW","enabled":true,"startingDeadlineSeconds":900,"timezone":"UTC","nextRunAt":"2026-10-19T02:00:00.000+0000"},{"id":"a","cron":"0 1 * * *"}],
+		"activeRuns":[],"history":{"successCount":3,"failureCount":0,"successfulFinishedRuns":[],"failedFinishedRuns":[]}}`
+	const spec = `{"id":"diff.job","run":{"cmd":"true","cpus":0.5,"mem":64,"maxLaunchDelay":3600,"volumes":[]},"schedules":[{"id":"a","cron":"0 1 * * *"},{"id":"b","cron":"0 2 * * *","enabled":true,"startingDeadlineSeconds":900}]}`
+
+	It("Ignores defaults, read-only fields and schedule order", func() {
+		changes, err := DiffJobs(parse(live), parse(spec))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(changes).To(BeEmpty())
+	})
+
+	It("Reports changes by path with schedules keyed by id", func() {
+		changed := parse(spec)
+		changed.Run.SetCpus(1)
+		changed.Run.SetMaxLaunchDelay(60)
+		changed.Schedules[1].Cron = "0 3 * * *"
+		changed.Schedules = changed.Schedules[1:]
+		changes, err := DiffJobs(parse(live), changed)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(changes).To(HaveLen(4))
+		Expect(changes[0]).To(Equal(Change{Path: "run.cpus", Op: ChangeModify, Old: json.Number("0.5"), New: json.Number("1")}))
+		Expect(changes[1]).To(Equal(Change{Path: "run.maxLaunchDelay", Op: ChangeAdd, New: json.Number("60")}))
+		Expect(changes[2].Path).To(Equal("schedules.a"))
+		Expect(changes[2].Op).To(Equal(ChangeRemove))
+		Expect(changes[3]).To(Equal(Change{Path: "schedules.b.cron", Op: ChangeModify, Old: "0 2 * * *", New: "0 3 * * *"}))
+		Expect(changes[0].String()).To(Equal("modify run.cpus: 0.5 -> 1"))
+	})
+
+	It("Compares array elements by index", func() {
+		a := parse(`{"id":"x","run":{"args":["a","b"],"cpus":1,"mem":64,"disk":0}}`)
+		b := parse(`{"id":"x","run":{"args":["a","c","d"],"cpus":1,"mem":64,"disk":0}}`)
+		changes, err := DiffJobs(a, b)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(changes).To(HaveLen(2))
+		Expect(changes[0].Path).To(Equal("run.args[1]"))
+		Expect(changes[1]).To(Equal(Change{Path: "run.args[2]", Op: ChangeAdd, New: "d"}))
+	})
+
+	It("Diffs schedules on their own", func() {
+		changes, err := DiffSchedules(&Schedule{ID: "s", Cron: "* * * * *", Timezone: "UTC", NextRunAt: "soon"}, &Schedule{ID: "s", Cron: "* * * * *"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(changes).To(BeEmpty())
+	})
+
+	It("Renders canonical and normalized specs", func() {
+		canonical, err := CanonicalJob(parse(live))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(canonical).NotTo(ContainSubstring("history"))
+		Expect(canonical).NotTo(ContainSubstring("nextRunAt"))
+		Expect(string(canonical)).To(MatchRegexp(`(?s)"id": "a".*"id": "b"`))
+		normalized, err := NormalizedJob(parse(live))
+		Expect(err).ShouldNot(HaveOccurred())
+		fromSpec, err := NormalizedJob(parse(spec))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(normalized).To(Equal(fromSpec))
+	})
+})
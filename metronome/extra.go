@@ -0,0 +1,300 @@
+package metronome
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Extra - json members a model does not recognize, keyed by member name.  nil when there were none.
+// Metronome adds fields faster than this library; keeping them means GetJob -> UpdateJob does not silently drop them.
+// Marshalling re-emits them after the known fields, sorted by name.
+type Extra map[string]json.RawMessage
+
+// knownNames - lower cased json member names per struct type.  encoding/json matches members case-insensitively so we do too
+var knownNames sync.Map
+
+func jsonNames(t reflect.Type) map[string]bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if names, ok := knownNames.Load(t); ok {
+		return names.(map[string]bool)
+	}
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		names[strings.ToLower(name)] = true
+	}
+	knownNames.Store(t, names)
+	return names
+}
+
+// unmarshalExtra - decode `raw` into `known` (a pointer to an alias of the model without json methods) and return the members it didn't take
+func unmarshalExtra(raw []byte, known interface{}) (Extra, error) {
+	if err := json.Unmarshal(raw, known); err != nil {
+		return nil, err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return nil, err
+	}
+	names := jsonNames(reflect.TypeOf(known))
+	var extra Extra
+	for name, value := range members {
+		if names[strings.ToLower(name)] {
+			continue
+		}
+		if extra == nil {
+			extra = make(Extra)
+		}
+		extra[name] = value
+	}
+	return extra, nil
+}
+
+// marshalExtra - encode `known` (a pointer to an alias of the model) then append `extra`.  Extra members shadowed by a known field are dropped
+func marshalExtra(known interface{}, extra Extra) ([]byte, error) {
+	out, err := json.Marshal(known)
+	if err != nil || len(extra) == 0 {
+		return out, err
+	}
+	names := jsonNames(reflect.TypeOf(known))
+	keys := make([]string, 0, len(extra))
+	for name := range extra {
+		if !names[strings.ToLower(name)] {
+			keys = append(keys, name)
+		}
+	}
+	sort.Strings(keys)
+
+	buf := bytes.NewBuffer(out[:len(out)-1])
+	empty := len(out) == 2
+	for _, name := range keys {
+		if !empty {
+			buf.WriteByte(',')
+		}
+		empty = false
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		if err := json.Compact(buf, extra[name]); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// The models below keep unknown members in Extra.  Each aliases itself to shed the json methods and defers to marshalExtra/unmarshalExtra
+
+type artifactJSON Artifact
+
+// MarshalJSON - json interface implementation.  Re-emits Extra
+func (theArtifact Artifact) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*artifactJSON)(&theArtifact), theArtifact.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Unknown members land in Extra
+func (theArtifact *Artifact) UnmarshalJSON(raw []byte) (err error) {
+	theArtifact.Extra, err = unmarshalExtra(raw, (*artifactJSON)(theArtifact))
+	return err
+}
+
+type dockerParameterJSON DockerParameter
+
+// MarshalJSON - json interface implementation.  Re-emits Extra
+func (param DockerParameter) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*dockerParameterJSON)(&param), param.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Unknown members land in Extra
+func (param *DockerParameter) UnmarshalJSON(raw []byte) (err error) {
+	param.Extra, err = unmarshalExtra(raw, (*dockerParameterJSON)(param))
+	return err
+}
+
+type dockerJSON Docker
+
+// MarshalJSON - json interface implementation.  Re-emits Extra
+func (docker Docker) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*dockerJSON)(&docker), docker.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Unknown members land in Extra
+func (docker *Docker) UnmarshalJSON(raw []byte) (err error) {
+	docker.Extra, err = unmarshalExtra(raw, (*dockerJSON)(docker))
+	return err
+}
+
+type constraintJSON Constraint
+
+// MarshalJSON - json interface implementation.  Re-emits Extra
+func (theConstraint Constraint) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*constraintJSON)(&theConstraint), theConstraint.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Unknown members land in Extra
+func (theConstraint *Constraint) UnmarshalJSON(raw []byte) (err error) {
+	theConstraint.Extra, err = unmarshalExtra(raw, (*constraintJSON)(theConstraint))
+	return err
+}
+
+type placementJSON Placement
+
+// MarshalJSON - json interface implementation.  Re-emits Extra
+func (thePlacement Placement) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*placementJSON)(&thePlacement), thePlacement.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Unknown members land in Extra
+func (thePlacement *Placement) UnmarshalJSON(raw []byte) (err error) {
+	thePlacement.Extra, err = unmarshalExtra(raw, (*placementJSON)(thePlacement))
+	return err
+}
+
+type volumeJSON Volume
+
+// MarshalJSON - json interface implementation.  Re-emits Extra
+func (vol Volume) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*volumeJSON)(&vol), vol.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Unknown members land in Extra
+func (vol *Volume) UnmarshalJSON(raw []byte) (err error) {
+	vol.Extra, err = unmarshalExtra(raw, (*volumeJSON)(vol))
+	return err
+}
+
+type restartJSON Restart
+
+// MarshalJSON - json interface implementation.  Re-emits Extra
+func (restart Restart) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*restartJSON)(&restart), restart.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Unknown members land in Extra
+func (restart *Restart) UnmarshalJSON(raw []byte) (err error) {
+	restart.Extra, err = unmarshalExtra(raw, (*restartJSON)(restart))
+	return err
+}
+
+type networkJSON Network
+
+// MarshalJSON - json interface implementation.  Re-emits Extra
+func (network Network) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*networkJSON)(&network), network.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Unknown members land in Extra
+func (network *Network) UnmarshalJSON(raw []byte) (err error) {
+	network.Extra, err = unmarshalExtra(raw, (*networkJSON)(network))
+	return err
+}
+
+type secretJSON Secret
+
+// MarshalJSON - json interface implementation.  Re-emits Extra
+func (theSecret Secret) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*secretJSON)(&theSecret), theSecret.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Unknown members land in Extra
+func (theSecret *Secret) UnmarshalJSON(raw []byte) (err error) {
+	theSecret.Extra, err = unmarshalExtra(raw, (*secretJSON)(theSecret))
+	return err
+}
+
+type runJSON Run
+
+// MarshalJSON - json interface implementation.  Re-emits Extra
+func (runner Run) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*runJSON)(&runner), runner.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Unknown members land in Extra
+func (runner *Run) UnmarshalJSON(raw []byte) (err error) {
+	runner.Extra, err = unmarshalExtra(raw, (*runJSON)(runner))
+	return err
+}
+
+type jobJSON Job
+
+// MarshalJSON - json interface implementation.  Re-emits Extra
+func (theJob Job) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*jobJSON)(&theJob), theJob.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Unknown members land in Extra
+func (theJob *Job) UnmarshalJSON(raw []byte) (err error) {
+	theJob.Extra, err = unmarshalExtra(raw, (*jobJSON)(theJob))
+	return err
+}
+
+type scheduleJSON Schedule
+
+// MarshalJSON - json interface implementation.  Re-emits Extra
+func (sched Schedule) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*scheduleJSON)(&sched), sched.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Unknown members land in Extra.
+//  A schedule without `enabled` is enabled, as metronome does, so manifests needn't spell it out
+func (sched *Schedule) UnmarshalJSON(raw []byte) (err error) {
+	sched.Enabled = true
+	sched.Extra, err = unmarshalExtra(raw, (*scheduleJSON)(sched))
+	return err
+}
+
+type pullConfigJSON PullConfig
+
+// MarshalJSON - json interface implementation.  Re-emits Extra
+func (pc PullConfig) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*pullConfigJSON)(&pc), pc.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Unknown members land in Extra
+func (pc *PullConfig) UnmarshalJSON(raw []byte) (err error) {
+	pc.Extra, err = unmarshalExtra(raw, (*pullConfigJSON)(pc))
+	return err
+}
+
+type ucrImageJSON UcrImage
+
+// MarshalJSON - json interface implementation.  Re-emits Extra
+func (image UcrImage) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*ucrImageJSON)(&image), image.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Unknown members land in Extra
+func (image *UcrImage) UnmarshalJSON(raw []byte) (err error) {
+	image.Extra, err = unmarshalExtra(raw, (*ucrImageJSON)(image))
+	return err
+}
+
+type ucrJSON Ucr
+
+// MarshalJSON - json interface implementation.  Re-emits Extra
+func (ucr Ucr) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*ucrJSON)(&ucr), ucr.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Unknown members land in Extra
+func (ucr *Ucr) UnmarshalJSON(raw []byte) (err error) {
+	ucr.Extra, err = unmarshalExtra(raw, (*ucrJSON)(ucr))
+	return err
+}
@@ -0,0 +1,50 @@
+package metronome
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// canonicalSpec - the job spec as json with every object's members sorted so equal specs give equal bytes
+func (theJob *Job) canonicalSpec() ([]byte, error) {
+	raw, err := json.Marshal(theJob.Spec())
+	if err != nil {
+		return nil, err
+	}
+	doc, err := decodeDoc(raw)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// Fingerprint - stable hash (hex sha256) of the job spec.  Read-only fields such as history and schedules are ignored.
+//  Read it with the job and hand it back to UpdateJobIfMatch to make sure nobody changed the job in between
+func (theJob *Job) Fingerprint() (string, error) {
+	canonical, err := theJob.canonicalSpec()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ConflictError - the job changed since the caller read it
+type ConflictError struct {
+	JobID    string
+	Expected string
+	Actual   string
+}
+
+// Error - error interface implementation
+func (conflict *ConflictError) Error() string {
+	return fmt.Sprintf("job %s changed since it was read: expected fingerprint %s found %s", conflict.JobID, conflict.Expected, conflict.Actual)
+}
+
+// IsConflict - is err a ConflictError
+func IsConflict(err error) bool {
+	_, ok := err.(*ConflictError)
+	return ok
+}
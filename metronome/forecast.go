@@ -0,0 +1,202 @@
+package metronome
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// metronomeTimeLayouts - how metronome writes times e.g. 2016-07-15T13:02:59.735+0000
+var metronomeTimeLayouts = []string{"2006-01-02T15:04:05.000-0700", "2006-01-02T15:04:05-0700", time.RFC3339Nano}
+
+func parseMetronomeTime(text string) (time.Time, error) {
+	for _, layout := range metronomeTimeLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time '%s'", text)
+}
+
+// Duration - how long the run took.  false when the run hasn't finished or the times don't parse
+func (status *HistoryStatus) Duration() (time.Duration, bool) {
+	created, err := parseMetronomeTime(status.CreatedAt)
+	if err != nil {
+		return 0, false
+	}
+	finished, err := parseMetronomeTime(status.FinishedAt)
+	if err != nil || finished.Before(created) {
+		return 0, false
+	}
+	return finished.Sub(created), true
+}
+
+// Durations - how long each finished run, successful or failed, took
+func (history *History) Durations() []time.Duration {
+	var durations []time.Duration
+	for _, runs := range [][]HistoryStatus{history.SuccessfulFinishedRuns, history.FailedFinishedRuns} {
+		for i := range runs {
+			if d, ok := runs[i].Duration(); ok {
+				durations = append(durations, d)
+			}
+		}
+	}
+	return durations
+}
+
+// EstimateDuration - the median duration of the job's finished runs, or `fallback` when it has no history.
+//  Jobs() doesn't embed history; GetJob does
+func EstimateDuration(job *Job, fallback time.Duration) time.Duration {
+	if job.History == nil {
+		return fallback
+	}
+	durations := job.History.Durations()
+	if len(durations) == 0 {
+		return fallback
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	return durations[len(durations)/2]
+}
+
+// Resources - cpus, mem (MB) and disk (MB) asked for or available
+type Resources struct {
+	Cpus float64 `json:"cpus"`
+	Mem  int     `json:"mem"`
+	Disk int     `json:"disk"`
+}
+
+// Exceeds - any resource is over `capacity`.  A zero capacity is unlimited
+func (load Resources) Exceeds(capacity Resources) bool {
+	return capacity.Cpus > 0 && load.Cpus > capacity.Cpus ||
+		capacity.Mem > 0 && load.Mem > capacity.Mem ||
+		capacity.Disk > 0 && load.Disk > capacity.Disk
+}
+
+func (load Resources) add(firing Firing, sign int) Resources {
+	return Resources{
+		Cpus: load.Cpus + float64(sign)*firing.Cpus,
+		Mem:  load.Mem + sign*firing.Mem,
+		Disk: load.Disk + sign*firing.Disk,
+	}
+}
+
+func (load Resources) max(other Resources) Resources {
+	if other.Cpus > load.Cpus {
+		load.Cpus = other.Cpus
+	}
+	if other.Mem > load.Mem {
+		load.Mem = other.Mem
+	}
+	if other.Disk > load.Disk {
+		load.Disk = other.Disk
+	}
+	return load
+}
+
+// EstimatedRun - a firing and how long it is expected to run.  The duration is json encoded in seconds
+type EstimatedRun struct {
+	Firing
+	Duration time.Duration `json:"-"`
+}
+
+// MarshalJSON - the firing plus durationSeconds
+func (run EstimatedRun) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Firing
+		DurationSeconds float64 `json:"durationSeconds"`
+	}{run.Firing, run.Duration.Seconds()})
+}
+
+// End - when the run is expected to finish
+func (run EstimatedRun) End() time.Time {
+	return run.At.Add(run.Duration)
+}
+
+// LoadSegment - a stretch of time during which the same runs are expected to be running
+type LoadSegment struct {
+	Start time.Time      `json:"start"`
+	End   time.Time      `json:"end"`
+	Load  Resources      `json:"load"`
+	Runs  []EstimatedRun `json:"runs"`
+}
+
+// ForecastLoad - the expected cluster load in [from, to) from the enabled schedules of `jobs`, as consecutive segments.
+//  Each run lasts EstimateDuration(job, fallback) so runs fired before `from` that are still going count too.
+//  Stretches with nothing running are left out
+func ForecastLoad(jobs []*Job, from time.Time, to time.Time, fallback time.Duration) ([]LoadSegment, error) {
+	durations := make(map[string]time.Duration)
+	longest := fallback
+	for _, job := range jobs {
+		durations[job.ID] = EstimateDuration(job, fallback)
+		if durations[job.ID] > longest {
+			longest = durations[job.ID]
+		}
+	}
+	firings, err := ExpandSchedules(jobs, from.Add(-longest), to)
+	if err != nil {
+		return nil, err
+	}
+	var runs []EstimatedRun
+	edges := map[time.Time]bool{from.UTC(): true}
+	for _, firing := range firings {
+		run := EstimatedRun{Firing: firing, Duration: durations[firing.JobID]}
+		if !run.End().After(from) || run.Duration <= 0 {
+			continue
+		}
+		runs = append(runs, run)
+		edges[run.At.UTC()] = true
+		edges[run.End().UTC()] = true
+	}
+	times := make([]time.Time, 0, len(edges))
+	for t := range edges {
+		if !t.Before(from) && t.Before(to) {
+			times = append(times, t)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	var segments []LoadSegment
+	for i, start := range times {
+		end := to
+		if i+1 < len(times) {
+			end = times[i+1]
+		}
+		segment := LoadSegment{Start: start, End: end}
+		for _, run := range runs {
+			if run.At.Before(end) && run.End().After(start) {
+				segment.Runs = append(segment.Runs, run)
+				segment.Load = segment.Load.add(run.Firing, 1)
+			}
+		}
+		if len(segment.Runs) > 0 {
+			segments = append(segments, segment)
+		}
+	}
+	return segments, nil
+}
+
+// Peaks - the stretches where the load exceeds `capacity`.  Back to back segments over capacity are merged:
+//  the peak's Load is the highest of each resource during it and Runs every run contributing to it
+func Peaks(segments []LoadSegment, capacity Resources) []LoadSegment {
+	var peaks []LoadSegment
+	var seen map[EstimatedRun]bool
+	for _, segment := range segments {
+		if !segment.Load.Exceeds(capacity) {
+			continue
+		}
+		if n := len(peaks); n == 0 || !peaks[n-1].End.Equal(segment.Start) {
+			peaks = append(peaks, LoadSegment{Start: segment.Start})
+			seen = make(map[EstimatedRun]bool)
+		}
+		peak := &peaks[len(peaks)-1]
+		peak.End = segment.End
+		peak.Load = peak.Load.max(segment.Load)
+		for _, run := range segment.Runs {
+			if !seen[run] {
+				seen[run] = true
+				peak.Runs = append(peak.Runs, run)
+			}
+		}
+	}
+	return peaks
+}
@@ -0,0 +1,87 @@
+package metronome_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Forecast", func() {
+	parse := func(doc string) *Job {
+		var job Job
+		Expect(json.Unmarshal([]byte(doc), &job)).To(Succeed())
+		return &job
+	}
+	hourly := `"schedules":[{"id":"hourly","cron":"0 * * * *","enabled":true,"timezone":"UTC"}]`
+	jobs := []*Job{
+		parse(`{"id":"a","run":{"cmd":"true","cpus":1,"mem":100,"disk":0},` + hourly + `,"history":{
+			"successfulFinishedRuns":[
+				{"id":"1","createdAt":"2026-10-01T00:00:00.000+0000","finishedAt":"2026-10-01T00:08:00.000+0000"},
+				{"id":"2","createdAt":"2026-10-01T01:00:00.000+0000","finishedAt":"2026-10-01T01:40:00.000+0000"}],
+			"failedFinishedRuns":[
+				{"id":"3","createdAt":"2026-10-01T02:00:00.000+0000","finishedAt":"2026-10-01T02:10:00.000+0000"},
+				{"id":"4","createdAt":"2026-10-01T03:00:00.000+0000","finishedAt":""}]}}`),
+		parse(`{"id":"b","run":{"cmd":"true","cpus":1,"mem":100,"disk":0},` + hourly + `,"history":{
+			"successfulFinishedRuns":[{"id":"1","createdAt":"2026-10-01T00:00:00.000+0000","finishedAt":"2026-10-01T00:30:00.000+0000"}]}}`),
+		parse(`{"id":"c","run":{"cmd":"true","cpus":1,"mem":100,"disk":0},` + hourly + `}`),
+	}
+	at := func(hour int, min int) time.Time {
+		return time.Date(2026, 10, 19, hour, min, 0, 0, time.UTC)
+	}
+	ids := func(runs []EstimatedRun) []string {
+		var out []string
+		for _, run := range runs {
+			out = append(out, run.JobID)
+		}
+		return out
+	}
+
+	It("Estimates durations from finished runs", func() {
+		Expect(jobs[0].History.Durations()).To(HaveLen(3))
+		Expect(EstimateDuration(jobs[0], time.Minute)).To(Equal(10 * time.Minute))
+		Expect(EstimateDuration(jobs[1], time.Minute)).To(Equal(30 * time.Minute))
+		Expect(EstimateDuration(jobs[2], 5*time.Minute)).To(Equal(5 * time.Minute))
+	})
+
+	It("Sums the load of overlapping runs", func() {
+		segments, err := ForecastLoad(jobs, at(1, 0), at(2, 0), 5*time.Minute)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(segments).To(HaveLen(3))
+		Expect(segments[0].Start).To(BeTemporally("==", at(1, 0)))
+		Expect(segments[0].End).To(BeTemporally("==", at(1, 5)))
+		Expect(segments[0].Load).To(Equal(Resources{Cpus: 3, Mem: 300}))
+		Expect(ids(segments[1].Runs)).To(Equal([]string{"a", "b"}))
+		Expect(segments[2].End).To(BeTemporally("==", at(1, 30)))
+		Expect(ids(segments[2].Runs)).To(Equal([]string{"b"}))
+	})
+
+	It("Counts runs fired before the window that are still going", func() {
+		segments, err := ForecastLoad(jobs, at(1, 20), at(2, 0), 5*time.Minute)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(segments).To(HaveLen(1))
+		Expect(segments[0].Start).To(BeTemporally("==", at(1, 20)))
+		Expect(ids(segments[0].Runs)).To(Equal([]string{"b"}))
+	})
+
+	It("Reports and merges peaks over capacity", func() {
+		segments, err := ForecastLoad(jobs, at(1, 0), at(3, 0), 5*time.Minute)
+		Expect(err).ShouldNot(HaveOccurred())
+		peaks := Peaks(segments, Resources{Cpus: 2.5})
+		Expect(peaks).To(HaveLen(2))
+		Expect(peaks[0].End).To(BeTemporally("==", at(1, 5)))
+		Expect(peaks[1].Start).To(BeTemporally("==", at(2, 0)))
+
+		peaks = Peaks(segments, Resources{Cpus: 1.5})
+		Expect(peaks).To(HaveLen(2))
+		Expect(peaks[0].End).To(BeTemporally("==", at(1, 10)))
+		Expect(peaks[0].Load).To(Equal(Resources{Cpus: 3, Mem: 300}))
+		Expect(ids(peaks[0].Runs)).To(Equal([]string{"a", "b", "c"}))
+
+		Expect(Peaks(segments, Resources{Mem: 300})).To(BeEmpty())
+		Expect(Peaks(segments, Resources{})).To(BeEmpty())
+	})
+})
@@ -0,0 +1,179 @@
+package metronome
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+// FrozenSchedule - a schedule as it was when the snapshot was taken
+type FrozenSchedule struct {
+	JobID    string    `json:"jobId"`
+	Schedule *Schedule `json:"schedule"`
+}
+
+// FreezeSnapshot - every schedule of every job before a freeze.  Thaw re-enables the ones that were enabled
+type FreezeSnapshot struct {
+	TakenAt   time.Time         `json:"takenAt"`
+	Schedules []*FrozenSchedule `json:"schedules"`
+}
+
+// TakeSnapshot - the current schedules of every job
+func TakeSnapshot(client Metronome) (*FreezeSnapshot, error) {
+	jobs, err := CurrentJobs(client)
+	if err != nil {
+		return nil, err
+	}
+	snap := &FreezeSnapshot{TakenAt: time.Now().UTC(), Schedules: make([]*FrozenSchedule, 0)}
+	for _, job := range jobs {
+		for _, sched := range job.Schedules {
+			frozen := *sched
+			frozen.NextRunAt = ""
+			snap.Schedules = append(snap.Schedules, &FrozenSchedule{JobID: job.ID, Schedule: &frozen})
+		}
+	}
+	return snap, nil
+}
+
+// LoadSnapshot - read a snapshot written by Save
+func LoadSnapshot(file string) (*FreezeSnapshot, error) {
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var snap FreezeSnapshot
+	if err = json.Unmarshal(raw, &snap); err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err.Error())
+	}
+	return &snap, nil
+}
+
+// Save - write the snapshot to `file`, which must not exist: a second freeze would snapshot frozen schedules
+func (snap *FreezeSnapshot) Save(file string) error {
+	raw, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = out.Write(append(raw, '\n')); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// Freeze - disable every schedule the snapshot has enabled and, with `stopRuns`, stop every active run.
+//  Save the snapshot first so the freeze can be undone even if it fails part way.  Returns what was done
+func Freeze(client Metronome, snap *FreezeSnapshot, stopRuns bool) ([]string, error) {
+	actions := make([]string, 0)
+	var failed []string
+	for _, frozen := range snap.Schedules {
+		if !frozen.Schedule.Enabled {
+			continue
+		}
+		key := frozen.JobID + "/" + frozen.Schedule.ID
+		update := *frozen.Schedule
+		update.Enabled = false
+		if _, err := client.UpdateSchedule(frozen.JobID, update.ID, &update); err != nil {
+			failed = append(failed, fmt.Sprintf("disable %s: %s", key, err.Error()))
+			continue
+		}
+		actions = append(actions, "disabled "+key)
+	}
+	if stopRuns {
+		all, err := client.Jobs()
+		if err != nil {
+			return actions, err
+		}
+		for _, job := range *all {
+			runs, err := client.RunLs(job.ID)
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("runs of %s: %s", job.ID, err.Error()))
+				continue
+			}
+			for _, run := range *runs {
+				if _, err := client.StopJob(job.ID, run.ID); err != nil {
+					failed = append(failed, fmt.Sprintf("stop %s/%s: %s", job.ID, run.ID, err.Error()))
+					continue
+				}
+				actions = append(actions, fmt.Sprintf("stopped run %s/%s", job.ID, run.ID))
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return actions, fmt.Errorf("%d failures: %v", len(failed), failed)
+	}
+	return actions, nil
+}
+
+// Thaw - put back the enabled of every schedule in the snapshot: re-enable the ones that were enabled, and disable
+//  again those that were disabled but got enabled during the freeze.  Only enabled is changed, so edits made since the
+//  freeze are kept.  Conflicts describe what no longer matches the snapshot: schedules deleted (not recreated),
+//  changed (kept), enabled already, enabled during the freeze, or created since the freeze (left alone)
+func Thaw(client Metronome, snap *FreezeSnapshot) (actions []string, conflicts []string, err error) {
+	jobs, err := CurrentJobs(client)
+	if err != nil {
+		return nil, nil, err
+	}
+	current := make(map[string]*Schedule)
+	for _, job := range jobs {
+		for _, sched := range job.Schedules {
+			current[job.ID+"/"+sched.ID] = sched
+		}
+	}
+	actions = make([]string, 0)
+	var failed []string
+	for _, frozen := range snap.Schedules {
+		key := frozen.JobID + "/" + frozen.Schedule.ID
+		live, ok := current[key]
+		delete(current, key)
+		if !ok {
+			conflicts = append(conflicts, key+": deleted since the freeze, not recreated")
+			continue
+		}
+		if changes, err := DiffSchedules(frozen.Schedule, live); err == nil {
+			for _, change := range changes {
+				if change.Path != "enabled" {
+					conflicts = append(conflicts, fmt.Sprintf("%s: changed since the freeze, keeping %s", key, change))
+				}
+			}
+		}
+		if live.Enabled == frozen.Schedule.Enabled {
+			if live.Enabled {
+				conflicts = append(conflicts, key+": already enabled")
+			}
+			continue
+		}
+		verb, done := "enable", "enabled"
+		if !frozen.Schedule.Enabled {
+			verb, done = "disable", "disabled"
+			conflicts = append(conflicts, key+": enabled during the freeze, disabled as in the snapshot")
+		}
+		update := *live
+		update.Enabled = frozen.Schedule.Enabled
+		update.NextRunAt = ""
+		if _, err := client.UpdateSchedule(frozen.JobID, update.ID, &update); err != nil {
+			failed = append(failed, fmt.Sprintf("%s %s: %s", verb, key, err.Error()))
+			continue
+		}
+		actions = append(actions, done+" "+key)
+	}
+	created := make([]string, 0, len(current))
+	for key := range current {
+		created = append(created, key)
+	}
+	sort.Strings(created)
+	for _, key := range created {
+		conflicts = append(conflicts, key+": created since the freeze, left as is")
+	}
+	if len(failed) > 0 {
+		return actions, conflicts, fmt.Errorf("%d failures: %v", len(failed), failed)
+	}
+	return actions, conflicts, nil
+}
@@ -0,0 +1,148 @@
+package metronome_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Freeze", func() {
+	var (
+		server *ghttp.Server
+		client Metronome
+	)
+	jsonHeader := http.Header{"Content-Type": {"application/json"}}
+	jobs := `[{"id":"report","run":{"cmd":"true","cpus":0.1,"mem":32,"disk":0}}]`
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.AppendHandlers(ghttp.VerifyRequest("GET", "/v1/jobs"))
+		client, _ = NewClient(Config{URL: server.URL(), RequestTimeout: 5})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	listed := func(schedules string) []http.HandlerFunc {
+		return []http.HandlerFunc{
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs"),
+				ghttp.RespondWith(http.StatusOK, jobs, jsonHeader),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs/report/schedules"),
+				ghttp.RespondWith(http.StatusOK, schedules, jsonHeader),
+			),
+		}
+	}
+
+	It("Disables the enabled schedules and stops active runs", func() {
+		server.AppendHandlers(listed(`[
+			{"id":"nightly","cron":"30 2 * * *","enabled":true,"timezone":"UTC","nextRunAt":"2026-10-19T02:30:00.000+0000"},
+			{"id":"manual","cron":"0 0 1 1 *","enabled":false,"timezone":"UTC"}]`)...)
+		snap, err := TakeSnapshot(client)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(snap.Schedules).To(HaveLen(2))
+		Expect(snap.Schedules[0].Schedule.NextRunAt).To(BeEmpty())
+
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/v1/jobs/report/schedules/nightly"),
+				ghttp.VerifyJSON(`{"id":"nightly","cron":"30 2 * * *","concurrencyPolicy":"","enabled":false,"startingDeadlineSeconds":0,"timezone":"UTC"}`),
+				ghttp.RespondWith(http.StatusOK, `{}`, jsonHeader),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs"),
+				ghttp.RespondWith(http.StatusOK, jobs, jsonHeader),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs/report/runs"),
+				ghttp.RespondWith(http.StatusOK, `[{"id":"20261018120000abcde","jobId":"report","status":"ACTIVE"}]`, jsonHeader),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/v1/jobs/report/runs/20261018120000abcde/actions/stop"),
+				ghttp.RespondWith(http.StatusOK, `{}`, jsonHeader),
+			),
+		)
+		actions, err := Freeze(client, snap, true)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(actions).To(Equal([]string{"disabled report/nightly", "stopped run report/20261018120000abcde"}))
+		Expect(server.ReceivedRequests()).To(HaveLen(7))
+	})
+
+	It("Refuses to overwrite a snapshot", func() {
+		dir, err := ioutil.TempDir("", "freeze")
+		Expect(err).ShouldNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		file := filepath.Join(dir, "snap.json")
+		snap := &FreezeSnapshot{Schedules: []*FrozenSchedule{{JobID: "report", Schedule: &Schedule{ID: "nightly", Cron: "30 2 * * *", Enabled: true}}}}
+		Expect(snap.Save(file)).To(Succeed())
+		Expect(snap.Save(file)).ShouldNot(Succeed())
+		loaded, err := LoadSnapshot(file)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(loaded.Schedules[0].Schedule.Cron).To(Equal("30 2 * * *"))
+	})
+
+	It("Thaws what was enabled and reports conflicts", func() {
+		snap := &FreezeSnapshot{Schedules: []*FrozenSchedule{
+			{JobID: "report", Schedule: &Schedule{ID: "nightly", Cron: "30 2 * * *", Enabled: true, Timezone: "UTC"}},
+			{JobID: "report", Schedule: &Schedule{ID: "gone", Cron: "0 3 * * *", Enabled: true, Timezone: "UTC"}},
+			{JobID: "report", Schedule: &Schedule{ID: "manual", Cron: "0 0 1 1 *", Enabled: false, Timezone: "UTC"}},
+			{JobID: "report", Schedule: &Schedule{ID: "adhoc", Cron: "0 0 1 1 *", Enabled: false, Timezone: "UTC"}},
+		}}
+		server.AppendHandlers(listed(`[
+			{"id":"nightly","cron":"45 2 * * *","enabled":false,"timezone":"UTC"},
+			{"id":"manual","cron":"0 0 1 1 *","enabled":false,"timezone":"UTC"},
+			{"id":"adhoc","cron":"0 0 1 1 *","enabled":true,"timezone":"UTC"},
+			{"id":"added","cron":"0 4 * * *","enabled":true,"timezone":"UTC"}]`)...)
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/v1/jobs/report/schedules/nightly"),
+				ghttp.VerifyJSON(`{"id":"nightly","cron":"45 2 * * *","concurrencyPolicy":"","enabled":true,"startingDeadlineSeconds":0,"timezone":"UTC"}`),
+				ghttp.RespondWith(http.StatusOK, `{}`, jsonHeader),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/v1/jobs/report/schedules/adhoc"),
+				ghttp.VerifyJSON(`{"id":"adhoc","cron":"0 0 1 1 *","concurrencyPolicy":"","enabled":false,"startingDeadlineSeconds":0,"timezone":"UTC"}`),
+				ghttp.RespondWith(http.StatusOK, `{}`, jsonHeader),
+			),
+		)
+		actions, conflicts, err := Thaw(client, snap)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(actions).To(Equal([]string{"enabled report/nightly", "disabled report/adhoc"}))
+		Expect(conflicts).To(HaveLen(4))
+		Expect(conflicts[0]).To(HavePrefix("report/nightly: changed since the freeze, keeping "))
+		Expect(conflicts[1]).To(Equal("report/gone: deleted since the freeze, not recreated"))
+		Expect(conflicts[2]).To(Equal("report/adhoc: enabled during the freeze, disabled as in the snapshot"))
+		Expect(conflicts[3]).To(Equal("report/added: created since the freeze, left as is"))
+	})
+
+	It("Returns what it froze along with the failures", func() {
+		snap := &FreezeSnapshot{Schedules: []*FrozenSchedule{
+			{JobID: "report", Schedule: &Schedule{ID: "nightly", Cron: "30 2 * * *", Enabled: true, Timezone: "UTC"}},
+			{JobID: "report", Schedule: &Schedule{ID: "hourly", Cron: "0 * * * *", Enabled: true, Timezone: "UTC"}},
+		}}
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/v1/jobs/report/schedules/nightly"),
+				ghttp.RespondWith(http.StatusOK, `{}`, jsonHeader),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/v1/jobs/report/schedules/hourly"),
+				ghttp.RespondWith(http.StatusInternalServerError, `{"message":"boom"}`, jsonHeader),
+			),
+		)
+		actions, err := Freeze(client, snap, false)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("1 failures: [disable report/hourly: "))
+		Expect(actions).To(Equal([]string{"disabled report/nightly"}))
+	})
+})
@@ -0,0 +1,210 @@
+package metronome
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	duration "github.com/ChannelMeter/iso8601duration"
+)
+
+var (
+	repeatRegex = regexp.MustCompile(`^R(\d*)$`)
+	// the duration library refuses months so they are read here
+	monthsRegex = regexp.MustCompile(`^P(\d+)M$`)
+)
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+// RepeatingInterval - an ISO 8601 repeating interval R[n]/start/duration e.g. R/2026-10-18T09:00:00Z/PT15M
+type RepeatingInterval struct {
+	// Repeat - number of repetitions, -1 when unbounded (R/...)
+	Repeat   int
+	Start    time.Time
+	Duration duration.Duration
+	// Months - the duration in months (P3M).  The duration library doesn't model them
+	Months int
+}
+
+// ParseRepeatingInterval - parse R[n]/start/duration.  The start must be RFC3339
+func ParseRepeatingInterval(isoRep string) (*RepeatingInterval, error) {
+	parts := strings.Split(isoRep, "/")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("'%s' is not a repeating interval R[n]/start/duration", isoRep)
+	}
+	match := repeatRegex.FindStringSubmatch(parts[0])
+	if match == nil {
+		return nil, fmt.Errorf("'%s' must start with R or Rn", isoRep)
+	}
+	ri := &RepeatingInterval{Repeat: -1}
+	if match[1] != "" {
+		ri.Repeat, _ = strconv.Atoi(match[1])
+	}
+	start, err := time.Parse(time.RFC3339, parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("'%s' start must be an RFC3339 time: %s", isoRep, err.Error())
+	}
+	ri.Start = start
+	if m := monthsRegex.FindStringSubmatch(parts[2]); m != nil {
+		ri.Months, _ = strconv.Atoi(m[1])
+	} else {
+		dur, err := duration.FromString(parts[2])
+		// the library's pattern isn't anchored so anything parses as an empty duration
+		if err != nil || !strings.HasPrefix(parts[2], "P") || dur.ToDuration() == 0 {
+			return nil, fmt.Errorf("'%s' has a bad duration '%s'", isoRep, parts[2])
+		}
+		ri.Duration = *dur
+	}
+	return ri, nil
+}
+
+// notCron - the interval can't be represented exactly by cron
+func (ri *RepeatingInterval) notCron(format string, args ...interface{}) error {
+	return fmt.Errorf("can't be represented exactly in cron: "+format, args...)
+}
+
+// Crons - the cron expressions, in UTC, that together fire exactly when the interval does.
+//  Errors explain what can't be represented: a bounded repeat count, a start after `now`, seconds,
+//  or a period that doesn't line up with the calendar (every 7 minutes, every 2 days, every month on the 31st)
+func (ri *RepeatingInterval) Crons(now time.Time) ([]string, error) {
+	if ri.Repeat >= 0 {
+		return nil, ri.notCron("it repeats %d times and cron repeats forever", ri.Repeat)
+	}
+	if ri.Start.After(now) {
+		return nil, ri.notCron("it starts in the future and a cron schedule starts right away")
+	}
+	start := ri.Start.UTC()
+	if start.Second() != 0 || start.Nanosecond() != 0 || ri.Duration.Seconds != 0 {
+		return nil, ri.notCron("cron has minute resolution")
+	}
+	dur := ri.Duration
+	months := ri.Months + 12*dur.Years
+	if months > 0 {
+		if dur.Weeks != 0 || dur.Days != 0 || dur.Hours != 0 || dur.Minutes != 0 {
+			return nil, ri.notCron("it mixes years or months with shorter units")
+		}
+		if 12%months != 0 {
+			return nil, ri.notCron("every %d months doesn't divide a year", months)
+		}
+		if start.Day() > 28 {
+			return nil, ri.notCron("not every month has a day %d", start.Day())
+		}
+		var list []int
+		for m := int(start.Month()) - 1; len(list) < 12/months; m = (m + months) % 12 {
+			list = append(list, m+1)
+		}
+		sort.Ints(list)
+		return []string{fmt.Sprintf("%d %d %d %s *", start.Minute(), start.Hour(), start.Day(), cronList(list, 1, 12))}, nil
+	}
+	period := dur.Weeks*minutesPerWeek + dur.Days*minutesPerDay + dur.Hours*60 + dur.Minutes
+	switch {
+	case period == minutesPerWeek:
+		return []string{fmt.Sprintf("%d %d * * %d", start.Minute(), start.Hour(), int(start.Weekday()))}, nil
+	case period > minutesPerDay && period%minutesPerDay == 0:
+		return nil, ri.notCron("every %d days doesn't line up with months or weeks", period/minutesPerDay)
+	case period > minutesPerDay && period%60 == 0:
+		return nil, ri.notCron("every %d hours is longer than a day and not a whole number of days", period/60)
+	case period > minutesPerDay:
+		return nil, ri.notCron("every %d minutes is longer than a day and not a whole number of days", period)
+	case minutesPerDay%period != 0:
+		return nil, ri.notCron("every %d minutes doesn't divide a day", period)
+	}
+	// fire times within a day, grouped by the hours each minute of the hour fires in
+	phase := (start.Hour()*60 + start.Minute()) % period
+	hoursAt := make(map[int][]int)
+	for at := phase; at < minutesPerDay; at += period {
+		hoursAt[at%60] = append(hoursAt[at%60], at/60)
+	}
+	var minutes []int
+	for minute := range hoursAt {
+		minutes = append(minutes, minute)
+	}
+	sort.Ints(minutes)
+	var order []string
+	minutesFor := make(map[string][]int)
+	for _, minute := range minutes {
+		hours := cronList(hoursAt[minute], 0, 23)
+		if _, seen := minutesFor[hours]; !seen {
+			order = append(order, hours)
+		}
+		minutesFor[hours] = append(minutesFor[hours], minute)
+	}
+	crons := make([]string, 0, len(order))
+	for _, hours := range order {
+		crons = append(crons, fmt.Sprintf("%s %s * * *", cronList(minutesFor[hours], 0, 59), hours))
+	}
+	return crons, nil
+}
+
+// cronList - sorted values of a cron field as `*`, `*/n`, `a-max/n` or `a,b,c`
+func cronList(values []int, min int, max int) string {
+	if len(values) == 1 {
+		return strconv.Itoa(values[0])
+	}
+	step := values[1] - values[0]
+	regular := values[len(values)-1]+step > max
+	for i := 1; regular && i < len(values); i++ {
+		regular = values[i]-values[i-1] == step
+	}
+	switch {
+	case regular && step == 1 && values[0] == min:
+		return "*"
+	case regular && values[0] == min:
+		return fmt.Sprintf("*/%d", step)
+	case regular && values[0]-step < min:
+		return fmt.Sprintf("%d-%d/%d", values[0], max, step)
+	}
+	text := make([]string, len(values))
+	for i, value := range values {
+		text[i] = strconv.Itoa(value)
+	}
+	return strings.Join(text, ",")
+}
+
+// Schedules - the interval as metronome schedules in UTC.  One schedule when a single cron expression will do,
+//  otherwise `id`-1, `id`-2...  See Crons for what can't be converted
+func (ri *RepeatingInterval) Schedules(id string, now time.Time) ([]*Schedule, error) {
+	crons, err := ri.Crons(now)
+	if err != nil {
+		return nil, err
+	}
+	scheds := make([]*Schedule, 0, len(crons))
+	for i, cron := range crons {
+		schedID := id
+		if len(crons) > 1 {
+			schedID = fmt.Sprintf("%s-%d", id, i+1)
+		}
+		scheds = append(scheds, &Schedule{
+			ID:                      schedID,
+			Cron:                    cron,
+			ConcurrencyPolicy:       "ALLOW",
+			Enabled:                 true,
+			StartingDeadlineSeconds: 60,
+			Timezone:                "UTC",
+		})
+	}
+	return scheds, nil
+}
+
+// ConvertIso8601ToCron - convert an ISO 8601 repeating interval R/start/duration to a single cron expression in UTC.
+//  Intervals needing several expressions (e.g. every 90 minutes) are an error; use ParseRepeatingInterval and Schedules for those
+func ConvertIso8601ToCron(isoRep string) (string, error) {
+	ri, err := ParseRepeatingInterval(isoRep)
+	if err != nil {
+		return "", err
+	}
+	crons, err := ri.Crons(time.Now())
+	if err != nil {
+		return "", fmt.Errorf("'%s' %s", isoRep, err.Error())
+	}
+	if len(crons) > 1 {
+		return "", fmt.Errorf("'%s' needs %d cron expressions: %s", isoRep, len(crons), strings.Join(crons, " | "))
+	}
+	return crons[0], nil
+}
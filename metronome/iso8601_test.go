@@ -0,0 +1,115 @@
+package metronome_test
+
+import (
+	"time"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Iso8601", func() {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	crons := func(isoRep string) ([]string, error) {
+		ri, err := ParseRepeatingInterval(isoRep)
+		if err != nil {
+			return nil, err
+		}
+		return ri.Crons(now)
+	}
+
+	It("Parses repeating intervals", func() {
+		ri, err := ParseRepeatingInterval("R5/2026-01-01T09:30:00+02:00/PT15M")
+		Expect(err).To(BeNil())
+		Expect(ri.Repeat).To(Equal(5))
+		Expect(ri.Start).To(BeTemporally("==", time.Date(2026, 1, 1, 7, 30, 0, 0, time.UTC)))
+		Expect(ri.Duration.ToDuration()).To(Equal(15 * time.Minute))
+
+		ri, err = ParseRepeatingInterval("R/2026-01-01T00:00:00Z/P3M")
+		Expect(err).To(BeNil())
+		Expect(ri.Repeat).To(Equal(-1))
+		Expect(ri.Months).To(Equal(3))
+	})
+
+	It("Rejects what isn't a repeating interval", func() {
+		for _, isoRep := range []string{"", "* * * * *", "R/2026-01-01T00:00:00Z", "X/2026-01-01T00:00:00Z/PT1H",
+			"R/2026-01-01/PT1H", "R/2026-01-01T00:00:00Z/1H", "R/2026-01-01T00:00:00Z/PT0M"} {
+			_, err := ParseRepeatingInterval(isoRep)
+			Expect(err).ToNot(BeNil(), isoRep)
+		}
+	})
+
+	It("Converts minute intervals", func() {
+		Expect(crons("R/2026-01-01T00:00:00Z/PT1M")).To(Equal([]string{"* * * * *"}))
+		Expect(crons("R/2026-01-01T00:05:00Z/PT15M")).To(Equal([]string{"5-59/15 * * * *"}))
+		Expect(crons("R/2026-01-01T00:00:00Z/PT20M")).To(Equal([]string{"*/20 * * * *"}))
+	})
+
+	It("Converts hour intervals", func() {
+		Expect(crons("R/2026-01-01T00:30:00Z/PT1H")).To(Equal([]string{"30 * * * *"}))
+		Expect(crons("R/2026-01-01T02:00:00Z/PT6H")).To(Equal([]string{"0 2-23/6 * * *"}))
+		Expect(crons("R/2026-01-01T00:00:00Z/PT1H30M")).To(Equal([]string{"0 */3 * * *", "30 1-23/3 * * *"}))
+		Expect(crons("R/2026-01-01T00:00:00Z/PT90M")).To(Equal([]string{"0 */3 * * *", "30 1-23/3 * * *"}))
+	})
+
+	It("Converts day, week, month and year intervals in UTC", func() {
+		Expect(crons("R/2026-01-01T09:30:00Z/P1D")).To(Equal([]string{"30 9 * * *"}))
+		Expect(crons("R/2026-01-01T09:30:00+02:00/PT24H")).To(Equal([]string{"30 7 * * *"}))
+		Expect(crons("R/2026-01-05T08:00:00Z/P1W")).To(Equal([]string{"0 8 * * 1"}))
+		Expect(crons("R/2026-01-05T08:00:00Z/P7D")).To(Equal([]string{"0 8 * * 1"}))
+		Expect(crons("R/2026-02-15T06:00:00Z/P3M")).To(Equal([]string{"0 6 15 2-12/3 *"}))
+		Expect(crons("R/2026-02-15T06:00:00Z/P1Y")).To(Equal([]string{"0 6 15 2 *"}))
+	})
+
+	It("Explains intervals cron can't represent exactly", func() {
+		for isoRep, reason := range map[string]string{
+			"R5/2026-01-01T00:00:00Z/PT1H":  "repeats 5 times",
+			"R/2027-01-01T00:00:00Z/PT1H":   "starts in the future",
+			"R/2026-01-01T00:00:30Z/PT1H":   "minute resolution",
+			"R/2026-01-01T00:00:00Z/PT90S":  "minute resolution",
+			"R/2026-01-01T00:00:00Z/PT7M":   "7 minutes",
+			"R/2026-01-01T00:00:00Z/P2D":    "2 days",
+			"R/2026-01-01T00:00:00Z/PT36H":  "every 36 hours is longer than a day and not a whole number of days",
+			"R/2026-01-01T00:00:00Z/P1DT1M": "every 1441 minutes is longer than a day",
+			"R/2026-01-01T00:00:00Z/P5M":    "5 months",
+			"R/2026-01-31T00:00:00Z/P1M":    "day 31",
+			"R/2026-01-01T00:00:00Z/P1Y2D":  "mixes",
+		} {
+			_, err := crons(isoRep)
+			Expect(err).ToNot(BeNil(), isoRep)
+			Expect(err.Error()).To(ContainSubstring("can't be represented exactly in cron"), isoRep)
+			Expect(err.Error()).To(ContainSubstring(reason), isoRep)
+		}
+	})
+
+	It("Builds UTC schedules, numbered when there are several", func() {
+		ri, err := ParseRepeatingInterval("R/2026-01-01T00:00:00Z/PT90M")
+		Expect(err).To(BeNil())
+		scheds, err := ri.Schedules("every90", now)
+		Expect(err).To(BeNil())
+		Expect(scheds).To(HaveLen(2))
+		Expect(scheds[0].ID).To(Equal("every90-1"))
+		Expect(scheds[1].ID).To(Equal("every90-2"))
+		Expect(scheds[1].Cron).To(Equal("30 1-23/3 * * *"))
+		Expect(scheds[0].Timezone).To(Equal("UTC"))
+		Expect(scheds[0].Enabled).To(BeTrue())
+		Expect(scheds[0].Validate()).To(Succeed())
+
+		ri, err = ParseRepeatingInterval("R/2026-01-01T09:30:00Z/P1D")
+		Expect(err).To(BeNil())
+		scheds, err = ri.Schedules("daily", now)
+		Expect(err).To(BeNil())
+		Expect(scheds).To(HaveLen(1))
+		Expect(scheds[0].ID).To(Equal("daily"))
+	})
+
+	It("Converts to a single cron expression", func() {
+		Expect(ConvertIso8601ToCron("R/2026-01-01T09:30:00Z/P1D")).To(Equal("30 9 * * *"))
+		_, err := ConvertIso8601ToCron("R/2026-01-01T00:00:00Z/PT90M")
+		Expect(err).ToNot(BeNil())
+		_, err = ConvertIso8601ToCron("PT1H")
+		Expect(err).ToNot(BeNil())
+	})
+})
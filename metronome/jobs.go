@@ -2,15 +2,11 @@ package metronome
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
-	"regexp"
 	"strconv"
-	"strings"
 	"time"
 
-	duration "github.com/ChannelMeter/iso8601duration"
 	log "github.com/behance/go-logrus"
 )
 
@@ -89,6 +85,74 @@ func (client *Client) UpdateJob(jobID string, job *Job) (interface{}, error) {
 	return &msg, nil
 }
 
+// UpdateJobIfMatch - UpdateJob, but only when the job's current Fingerprint is `fingerprint`. Otherwise a *ConflictError and no PUT.
+//  Metronome has no conditional PUT so this re-reads the job first; it narrows the window for lost updates rather than closing it
+func (client *Client) UpdateJobIfMatch(jobID string, job *Job, fingerprint string) (interface{}, error) {
+	var msg json.RawMessage
+	if err := client.putIfMatch(jobID, job, fingerprint, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (client *Client) putIfMatch(jobID string, job *Job, fingerprint string, result interface{}) error {
+	current, err := client.GetJob(jobID)
+	if err != nil {
+		return err
+	}
+	actual, err := current.Fingerprint()
+	if err != nil {
+		return err
+	}
+	if actual != fingerprint {
+		return &ConflictError{JobID: jobID, Expected: fingerprint, Actual: actual}
+	}
+	_, err = client.apiPut(fmt.Sprintf(MetronomeAPIJobUpdate, jobID), nil, job, result)
+	return err
+}
+
+// PatchJob - GET the job, let `mutate` edit its spec then PUT it back.  Only the fields mutate touches change.
+//  The mutated job is validated before the PUT; mutate returning an error aborts without calling metronome.
+//  Fails with a *ConflictError if someone else changed the job meanwhile; see PatchJobRetry
+func (client *Client) PatchJob(jobID string, mutate func(*Job) error) (*Job, error) {
+	return client.PatchJobRetry(jobID, 1, mutate)
+}
+
+// PatchJobRetry - PatchJob making up to `attempts` tries.  On a conflict mutate is re-run against the newly read job,
+//  i.e. the edit is rebased onto the other change.  mutate must only depend on the job it is handed
+func (client *Client) PatchJobRetry(jobID string, attempts int, mutate func(*Job) error) (*Job, error) {
+	for attempt := 1; ; attempt++ {
+		current, err := client.GetJob(jobID)
+		if err != nil {
+			return nil, err
+		}
+		fingerprint, err := current.Fingerprint()
+		if err != nil {
+			return nil, err
+		}
+		job := current.Spec()
+		if err = mutate(job); err != nil {
+			return nil, err
+		}
+		if job.ID != jobID {
+			return nil, fmt.Errorf("PatchJob can't change the job id from %s to %s", jobID, job.ID)
+		}
+		if err = job.Validate(); err != nil {
+			return nil, err
+		}
+		var reply Job
+		err = client.putIfMatch(jobID, job, fingerprint, &reply)
+		if IsConflict(err) && attempt < attempts {
+			log.Debugf("PatchJob %s conflict on attempt %d, rebasing", jobID, attempt)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &reply, nil
+	}
+}
+
 // Runs - get all the 'runs' of a given job
 // GET /v1/jobs/$jobId/runs
 func (client *Client) Runs(jobID string, since int64) (*Job, error) {
@@ -115,6 +179,7 @@ func (client *Client) Runs(jobID string, since int64) (*Job, error) {
 }
 
 // RunLs  - list running jobs - standard
+// GET /v1/jobs/$jobId/runs
 func (client *Client) RunLs(jobID string) (*[]JobStatus, error) {
 	jobs := make([]JobStatus, 0, 0)
 
@@ -163,11 +228,15 @@ func (client *Client) StopJob(jobID string, runID string) (interface{}, error) {
 // Schedules
 //
 
-// CreateSchedule - assign a schedule to a job
+// CreateSchedule - assign a schedule to a job.  H tokens in the cron expression are expanded for the job first, see Schedule.Expanded
 // POST /v1/jobs/$jobId/schedules
 func (client *Client) CreateSchedule(jobID string, sched *Schedule) (interface{}, error) {
 	var msg Schedule //json.RawMessage
 	log.Debugf("client.JobScheduleCreate %s\n", jobID)
+	sched, err := sched.Expanded(jobID)
+	if err != nil {
+		return nil, err
+	}
 	if _, err := client.apiPost(fmt.Sprintf(MetronomeAPIJobScheduleCreate, jobID), nil, sched, &msg); err != nil {
 		return nil, err
 	}
@@ -184,7 +253,7 @@ func (client *Client) GetSchedule(jobID string, schedID string) (*Schedule, erro
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("sched: %+v\n", sched)
+	log.Debugf("sched: %+v", sched)
 	return &sched, err
 
 }
@@ -217,11 +286,34 @@ func (client *Client) DeleteSchedule(jobID string, schedID string) (interface{},
 
 }
 
-// UpdateSchedule - update an existing schedule associated with a job
+// SetScheduleEnabled - enable or disable a schedule leaving every other field as metronome has it.
+//  Returns the schedule and whether it changed; a schedule already in the wanted state isn't updated
+// GET then PUT /v1/jobs/$jobId/schedules/$scheduleId
+func (client *Client) SetScheduleEnabled(jobID string, schedID string, enabled bool) (*Schedule, bool, error) {
+	sched, err := client.GetSchedule(jobID, schedID)
+	if err != nil {
+		return nil, false, err
+	}
+	if sched.Enabled == enabled {
+		return sched, false, nil
+	}
+	sched.Enabled = enabled
+	sched.NextRunAt = ""
+	if _, err = client.UpdateSchedule(jobID, schedID, sched); err != nil {
+		return nil, false, err
+	}
+	return sched, true, nil
+}
+
+// UpdateSchedule - update an existing schedule associated with a job.  H tokens are expanded as in CreateSchedule
 // PUT /v1/jobs/$jobId/schedules/$scheduleId
 func (client *Client) UpdateSchedule(jobID string, schedID string, sched *Schedule) (interface{}, error) {
 	var msg json.RawMessage
-	_, err := client.apiPut(fmt.Sprintf(MetronomeAPIJobScheduleUpdate, jobID, schedID), nil, sched, &msg)
+	sched, err := sched.Expanded(jobID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = client.apiPut(fmt.Sprintf(MetronomeAPIJobScheduleUpdate, jobID, schedID), nil, sched, &msg)
 	if err != nil {
 		bbb, err2 := json.Marshal(msg)
 		if err2 != nil {
@@ -275,107 +367,12 @@ func formatTimeString(t time.Time) string {
 	return t.Format(time.RFC3339Nano)
 }
 
-///
-var (
-	repeatRegex = regexp.MustCompile(`R((?P<repeat>\d*))`)
-)
-
-// ConvertIso8601ToCron - attempts to convert an iso8601, 3 -part date into a cron repre.  Experimental
-//  - only simple cases will work without creating *multiple* schedules
-//
-func ConvertIso8601ToCron(isoRep string) (string, error) {
-	pat := strings.Split(isoRep, "/")
-	if len(pat) == 3 {
-		interval := pat[2]
-		dur := pat[0]
-		repeatTimes := 0
-		if repeatRegex.MatchString(dur) {
-			match := repeatRegex.FindStringSubmatch(dur)
-			for i, name := range repeatRegex.SubexpNames() {
-				part := match[i]
-				if i == 0 || name == "" || part == "" {
-					continue
-				}
-				val, err := strconv.Atoi(part)
-				if err != nil {
-					return "", err
-				}
-				switch name {
-				case "repeat":
-					repeatTimes = val
-				default:
-					return "", fmt.Errorf("unknown field %s", name)
-				}
-			}
-		} else {
-			return "", fmt.Errorf("No repeat pattern")
-
-		}
-		tdur, err := duration.FromString(interval)
-
-		if err != nil {
-			return "", errors.New("Illegal duration")
-		}
-		timeT := tdur.ToDuration()
-		if repeatTimes != 0 {
-			// minute is the smallest scheduling unit for metronome
-			slot := int64(timeT)
-			if slot < 1 {
-				return "", errors.New("Too small a duration")
-			} else if slot < 60 {
-
-			}
-
-		} else {
-
-		}
-
-	} else {
-		var (
-			y, M, d, h, m, s int
-		)
-		_, err := fmt.Sscanf(time.Now().Format(time.RFC3339), "%d-%d-%dT%d:%d:%dZ", &y, &M, &d, &h, &m, &s)
-		if err != nil {
-			return "", err
-		}
-		return fmt.Sprintf("%d %d %d %d * %d", m, h, d, M, y), nil
-
-	}
-
-	return "", errors.New("Unknown error")
-}
-
-// ImmediateCrontab - generates a point in time crontab
+// ImmediateCrontab - a cron expression, in UTC, firing once at the start of the next minute
 func ImmediateCrontab() string {
-	var (
-		y, M, d, h, m, s int
-	)
-	_, err := fmt.Sscanf(time.Now().Format(time.RFC3339), "%d-%d-%dT%d:%d:%dZ", &y, &M, &d, &h, &m, &s)
-	if err != nil {
-		return ""
-	}
-	return fmt.Sprintf("%d %d %d %d * %d", m, h, d, M, y)
+	return OneTimeCron(time.Now().Truncate(time.Minute).Add(time.Minute))
 }
 
-// ImmediateSchedule - create a metronome schedule
+// ImmediateSchedule - a one-time schedule firing at the start of the next minute.  See RunAt to add it to a job and clean it up
 func ImmediateSchedule() (*Schedule, error) {
-	var (
-		y, M, d, h, m, s int
-		cronstr          string
-	)
-	_, err := fmt.Sscanf(time.Now().Format(time.RFC3339), "%d-%d-%dT%d:%d:%dZ", &y, &M, &d, &h, &m, &s)
-	if err != nil {
-		return nil, err
-	}
-	cronstr = fmt.Sprintf("%d %d %d %d * %d", m, h, d, M, y)
-
-	sched := &Schedule{
-		ID:                      fmt.Sprintf("%d%d%d%d%d%d ", y, M, d, h, m, s), //"everyminute",
-		Cron:                    cronstr,                                        //"cron": "* * * * *",
-		ConcurrencyPolicy:       "ALLOW",
-		Enabled:                 true,
-		StartingDeadlineSeconds: 60,
-		Timezone:                "GMT",
-	}
-	return sched, nil
+	return OneTimeSchedule(time.Now().Truncate(time.Minute).Add(time.Minute)), nil
 }
@@ -190,9 +190,9 @@ var _ = Describe("Jobs", func() {
 					Docker: &Docker{
 						Image: "foo/bla:test",
 					},
-					Env: map[string]string{
-						"MON":     "test",
-						"CONNECT": "direct",
+					Env: map[string]EnvValue{
+						"MON":     EnvLiteral("test"),
+						"CONNECT": EnvLiteral("direct"),
 					},
 					MaxLaunchDelay: 3600,
 					Placement: &Placement{
@@ -222,9 +222,9 @@ var _ = Describe("Jobs", func() {
 						Docker: &Docker{
 							Image: "f4tq/dcos-tests:v0.31",
 						},
-						Env: map[string]string{
-							"MON":     "test",
-							"CONNECT": "direct",
+						Env: map[string]EnvValue{
+							"MON":     EnvLiteral("test"),
+							"CONNECT": EnvLiteral("direct"),
 						},
 						MaxLaunchDelay: 3600,
 						Mem:            32,
@@ -293,6 +293,132 @@ var _ = Describe("Jobs", func() {
 		})
 	})
 
+	Describe("PatchJob", func() {
+		const current = `{"description":"patch me","id":"patch.job","labels":{"owner":"zeus"},"run":{"cmd":"true","cpus":0.5,"mem":64,"disk":0,"env":{"KEEP":"1","DROP":"2"},"maxLaunchDelay":60,"user":"nobody","volumes":[],"resourceLimits":{"cpus":"unlimited"}},"schedules":[{"id":"s1","cron":"* * * * *","concurrencyPolicy":"ALLOW","enabled":true,"startingDeadlineSeconds":60,"timezone":"UTC"}],"historySummary":{"successCount":1,"failureCount":0,"lastSuccessAt":"","lastFailureAt":""}}`
+		const patched = `{"description":"patch me","id":"patch.job","labels":{"owner":"zeus"},"run":{"cmd":"true","cpus":2,"mem":64,"disk":0,"env":{"KEEP":"1"},"maxLaunchDelay":60,"user":"nobody","volumes":[],"resourceLimits":{"cpus":"unlimited"}}}`
+		jsonHeader := http.Header{"Content-Type": {"application/json"}}
+
+		It("Changes only what mutate touches", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs/patch.job"),
+					ghttp.RespondWith(http.StatusOK, current, jsonHeader),
+				),
+				ghttp.RespondWith(http.StatusOK, current, jsonHeader),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v1/jobs/patch.job"),
+					ghttp.VerifyJSON(patched),
+					ghttp.RespondWith(http.StatusOK, patched, jsonHeader),
+				),
+			)
+			job, err := client.PatchJob("patch.job", func(job *Job) error {
+				if err := job.Set("run.cpus", "2"); err != nil {
+					return err
+				}
+				return job.Unset("run.env.DROP")
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(job.GetRun().GetCpus()).To(Equal(2.0))
+			Expect(server.ReceivedRequests()).To(HaveLen(4))
+		})
+
+		It("Doesn't PUT when mutate fails or the result is invalid", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, current, jsonHeader),
+				ghttp.RespondWith(http.StatusOK, current, jsonHeader),
+			)
+			_, err := client.PatchJob("patch.job", func(job *Job) error {
+				return fmt.Errorf("changed my mind")
+			})
+			Expect(err).To(MatchError("changed my mind"))
+			_, err = client.PatchJob("patch.job", func(job *Job) error {
+				return job.Set("run.mem", "1")
+			})
+			Expect(err).To(BeAssignableToTypeOf(ValidationErrors{}))
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+	})
+
+	Describe("Optimistic concurrency", func() {
+		const original = `{"description":"","id":"occ.job","run":{"cmd":"true","cpus":0.5,"mem":64,"disk":0,"maxLaunchDelay":60,"volumes":[]}}`
+		const theirs = `{"description":"","id":"occ.job","run":{"cmd":"true","cpus":0.5,"mem":256,"disk":0,"maxLaunchDelay":60,"volumes":[]}}`
+		jsonHeader := http.Header{"Content-Type": {"application/json"}}
+		var fingerprint string
+
+		BeforeEach(func() {
+			var job Job
+			Expect(json.Unmarshal([]byte(original), &job)).To(Succeed())
+			var err error
+			fingerprint, err = job.Fingerprint()
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		It("Updates when the fingerprint matches", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, original, jsonHeader),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v1/jobs/occ.job"),
+					ghttp.RespondWith(http.StatusOK, original, jsonHeader),
+				),
+			)
+			var job Job
+			Expect(json.Unmarshal([]byte(original), &job)).To(Succeed())
+			job.Run.SetCpus(1)
+			_, err := client.UpdateJobIfMatch("occ.job", &job, fingerprint)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+
+		It("Refuses the PUT when the job changed", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, theirs, jsonHeader))
+			var job Job
+			Expect(json.Unmarshal([]byte(original), &job)).To(Succeed())
+			_, err := client.UpdateJobIfMatch("occ.job", &job, fingerprint)
+			Expect(IsConflict(err)).To(BeTrue())
+			Expect(err.(*ConflictError).Expected).To(Equal(fingerprint))
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("Rebases a patch onto a concurrent change", func() {
+			rebased := `{"description":"","id":"occ.job","run":{"cmd":"true","cpus":2,"mem":256,"disk":0,"maxLaunchDelay":60,"volumes":[]}}`
+			server.AppendHandlers(
+				// first attempt reads the original, then sees their change
+				ghttp.RespondWith(http.StatusOK, original, jsonHeader),
+				ghttp.RespondWith(http.StatusOK, theirs, jsonHeader),
+				// second attempt starts from their change
+				ghttp.RespondWith(http.StatusOK, theirs, jsonHeader),
+				ghttp.RespondWith(http.StatusOK, theirs, jsonHeader),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v1/jobs/occ.job"),
+					ghttp.VerifyJSON(rebased),
+					ghttp.RespondWith(http.StatusOK, rebased, jsonHeader),
+				),
+			)
+			calls := 0
+			job, err := client.PatchJobRetry("occ.job", 2, func(job *Job) error {
+				calls++
+				job.Run.SetCpus(2)
+				return nil
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(calls).To(Equal(2))
+			Expect(job.Run.Mem).To(Equal(256))
+			Expect(server.ReceivedRequests()).To(HaveLen(6))
+		})
+
+		It("Gives up after the last attempt", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, original, jsonHeader),
+				ghttp.RespondWith(http.StatusOK, theirs, jsonHeader),
+			)
+			_, err := client.PatchJob("occ.job", func(job *Job) error {
+				job.Run.SetCpus(2)
+				return nil
+			})
+			Expect(IsConflict(err)).To(BeTrue())
+		})
+	})
+
 	Describe("StartJob", func() {
 		var (
 			job_without_arguments = "job.without.arguments"
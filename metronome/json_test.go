@@ -47,9 +47,9 @@ var _ = Describe("JSON rendering", func() {
 		Expect(runnable.Cpus).To(Equal(1.5))
 		runnable.SetDocker(&Docker{
 			Image: "foo/bla:test",
-		}).SetEnv(map[string]string{
-			"MON":     "test",
-			"CONNECT": "direct",
+		}).SetEnv(map[string]EnvValue{
+			"MON":     EnvLiteral("test"),
+			"CONNECT": EnvLiteral("direct"),
 		}).SetArgs([]string{
 			"nuke",
 			"--dry",
@@ -82,5 +82,151 @@ var _ = Describe("JSON rendering", func() {
 		Expect(*job).To(Equal(job2))
 
 	})
+	It("Round trips secrets and secret env references", func() {
+		const withSecrets = `{"description":"","id":"secret.job","run":{"cpus":0.5,"mem":64,"disk":0,"env":{"DB_PASS":{"secret":"dbpass"},"LEVEL":"info"},"maxLaunchDelay":900,"secrets":{"dbpass":{"source":"db/password"}},"volumes":[]}}`
+		var job Job
+		Expect(json.Unmarshal([]byte(withSecrets), &job)).To(Succeed())
+		Expect(job.Run.Env["DB_PASS"].IsSecret()).To(BeTrue())
+		Expect(job.Run.Env["DB_PASS"].Secret).To(Equal("dbpass"))
+		Expect(job.Run.Env["LEVEL"]).To(Equal(EnvLiteral("info")))
+		Expect(job.Run.Secrets["dbpass"].Source).To(Equal("db/password"))
+
+		out, err := json.Marshal(&job)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(MatchJSON(withSecrets))
+	})
+	It("Builds secret env references in code", func() {
+		runnable, err := NewRun(0.5, 64, 10)
+		Expect(err).NotTo(HaveOccurred())
+		runnable.SetCmd("env").SetMaxLaunchDelay(900).AddEnv("LEVEL", "info").AddSecret("dbpass", "db/password").AddSecretEnv("DB_PASS", "dbpass")
+		job, err := NewJob("secret.job", "", Labels{}, runnable)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.Validate()).To(Succeed())
+
+		runnable.AddSecretEnv("API_KEY", "apikey")
+		Expect(job.Validate()).To(MatchError(ContainSubstring("run.env.API_KEY")))
+	})
+	It("Round trips the newer run fields", func() {
+		const newer = `{"description":"","id":"gpu.job","run":{"cpus":1,"mem":1024,"disk":0,"gpus":2,"docker":{"image":"tensorflow/tensorflow:latest-gpu","forcePullImage":true,"privileged":true,"parameters":[{"key":"label","value":"team=ml"},{"key":"label","value":"tier=batch"}]},"maxLaunchDelay":900,"networks":[{"name":"dcos","mode":"container","labels":{"zone":"a"}}],"placement":{"constraints":[{"attribute":"hostname","operator":"IS","value":"gpu-1"}]},"restart":{"activeDeadlineSeconds":60,"policy":"ON_FAILURE"},"volumes":[],"taskKillGracePeriodSeconds":30}}`
+		var job Job
+		Expect(json.Unmarshal([]byte(newer), &job)).To(Succeed())
+		run := job.GetRun()
+		Expect(run.GetGpus()).To(Equal(2))
+		Expect(run.GetTaskKillGracePeriodSeconds()).To(Equal(30))
+		Expect(run.GetNetworks()).To(Equal([]Network{{Name: "dcos", Mode: NetworkContainer, Labels: map[string]string{"zone": "a"}}}))
+		Expect(run.GetDocker().IsForcePullImage()).To(BeTrue())
+		Expect(run.GetDocker().IsPrivileged()).To(BeTrue())
+		Expect(run.GetDocker().GetParameters()).To(HaveLen(2))
+		Expect(run.GetPlacement().Constraints[0].Operator).To(Equal(IS))
+		Expect(run.GetRestart().GetPolicy()).To(Equal("ON_FAILURE"))
+		Expect(job.Validate()).To(Succeed())
+
+		out, err := json.Marshal(&job)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(MatchJSON(newer))
+	})
+	It("Builds the newer run fields in code", func() {
+		network, err := NewNetwork(NetworkContainer, "dcos")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = NewNetwork(NetworkContainer, "")
+		Expect(err).To(HaveOccurred())
+		_, err = NewNetwork("bridge", "")
+		Expect(err).To(HaveOccurred())
+
+		runnable, err := NewRun(1, 1024, 10)
+		Expect(err).NotTo(HaveOccurred())
+		runnable.SetGpus(1).SetTaskKillGracePeriodSeconds(15).AddNetwork(*network).SetDocker(
+			(&Docker{Image: "alpine"}).SetForcePullImage(true).AddParameter("label", "team=ml"),
+		).SetPlacement((&Placement{}).AddConstraint(Constraint{Attribute: "rack", Operator: IS, Value: "2"})).SetRestart(
+			(&Restart{}).SetPolicy("ON_FAILURE").SetActiveDeadlineSeconds(30),
+		)
+		Expect(runnable.Docker.Parameters).To(Equal([]DockerParameter{{Key: "label", Value: "team=ml"}}))
+		Expect(runnable.Restart).To(Equal(&Restart{Policy: "ON_FAILURE", ActiveDeadlineSeconds: 30}))
+		Expect(runnable.Networks).To(HaveLen(1))
+	})
+	It("Round trips a UCR container", func() {
+		const ucrJob = `{"description":"","id":"ucr.job","run":{"cpus":0.5,"mem":256,"disk":0,"maxLaunchDelay":900,"secrets":{"registry":{"source":"/ci/registry"}},"ucr":{"image":{"id":"registry.example.com/batch:1.2","kind":"docker","forcePull":true,"pullConfig":{"secret":"registry"}},"privileged":true,"volumes":[{"containerPath":"/scratch","hostPath":"/tmp","mode":"RW"}]},"volumes":[]}}`
+		var job Job
+		Expect(json.Unmarshal([]byte(ucrJob), &job)).To(Succeed())
+		ucr := job.GetRun().GetUcr()
+		Expect(ucr).NotTo(BeNil())
+		Expect(ucr.GetImage().PullConfig).To(Equal(&PullConfig{Secret: "registry"}))
+		Expect(ucr.IsPrivileged()).To(BeTrue())
+		Expect(ucr.GetVolumes()).To(HaveLen(1))
+		Expect(job.Validate()).To(Succeed())
+
+		out, err := json.Marshal(&job)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(MatchJSON(ucrJob))
+	})
+	It("Builds a UCR container in code", func() {
+		_, err := NewUcr("", ImageKindDocker)
+		Expect(err).To(HaveOccurred())
+		_, err = NewUcr("alpine", "oci")
+		Expect(err).To(HaveOccurred())
+
+		ucr, err := NewUcr("alpine", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ucr.SetPullSecret("registry").SetForcePull(true).GetImage()).To(Equal(&UcrImage{
+			ID: "alpine", Kind: ImageKindDocker, ForcePull: true, PullConfig: &PullConfig{Secret: "registry"},
+		}))
+		Expect(ucr.SetPullSecret("").GetImage().PullConfig).To(BeNil())
+	})
+	It("Round trips unknown fields byte for byte", func() {
+		const future = `{"description":"from a newer metronome","id":"future.job","labels":{"owner":"zeus"},"run":{"artifacts":[{"uri":"http://a.test/b.zip","executable":false,"extract":true,"cache":false,"destPath":"lib"}],"cmd":"true","cpus":1,"mem":64,"disk":0,"docker":{"image":"alpine","registry":{"mirror":["a","b"]}},"env":{"A":"1"},"maxLaunchDelay":900,"placement":{"constraints":[{"attribute":"rack","operator":"EQ","value":"2","weight":3}],"strategy":"spread"},"restart":{"activeDeadlineSeconds":0,"policy":"NEVER","backoffFactor":1.5},"secrets":{"db":{"source":"/db","version":4}},"volumes":[{"containerPath":"/mnt","hostPath":"/tmp","mode":"RO","propagation":"rprivate"}],"resourceLimits":{"cpus":"unlimited"}},"schedules":[{"id":"s1","cron":"* * * * *","concurrencyPolicy":"ALLOW","enabled":true,"startingDeadlineSeconds":60,"timezone":"UTC","jitterSeconds":5}],"dependencies":[{"id":"other.job"}],"zone":null}`
+		var job Job
+		Expect(json.Unmarshal([]byte(future), &job)).To(Succeed())
+		Expect(job.Extra).To(HaveKey("dependencies"))
+		Expect(job.Run.Extra).To(HaveKey("resourceLimits"))
+		Expect(job.Schedules[0].Extra).To(HaveKey("jitterSeconds"))
+		Expect(job.Run.Volumes[0].Extra).To(HaveKey("propagation"))
+
+		out, err := json.Marshal(&job)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(out)).To(Equal(future))
+
+		// edits to known fields keep the unknown ones
+		job.Run.SetCpus(2)
+		out, err = json.Marshal(job)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring(`"cpus":2`))
+		Expect(string(out)).To(ContainSubstring(`"resourceLimits":{"cpus":"unlimited"}`))
+		Expect(string(out)).To(HaveSuffix(`"dependencies":[{"id":"other.job"}],"zone":null}`))
+	})
+	It("Only keeps members that are really unknown", func() {
+		var job Job
+		Expect(json.Unmarshal([]byte(data5), &job)).To(Succeed())
+		Expect(job.Extra).To(BeNil())
+		Expect(job.Run.Extra).To(BeNil())
+
+		// encoding/json matches names case-insensitively
+		var sched Schedule
+		Expect(json.Unmarshal([]byte(`{"ID":"s1","Cron":"* * * * *"}`), &sched)).To(Succeed())
+		Expect(sched.Extra).To(BeNil())
+		Expect(sched.ID).To(Equal("s1"))
+
+		// an extra member can't shadow a known field
+		sched.Extra = Extra{"cron": json.RawMessage(`"0 0 * * *"`), "later": json.RawMessage(`true`)}
+		out, err := json.Marshal(sched)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(MatchJSON(`{"id":"s1","cron":"* * * * *","concurrencyPolicy":"","enabled":true,"startingDeadlineSeconds":0,"timezone":"","later":true}`))
+	})
+	It("Fingerprints the spec, not the json layout or read-only fields", func() {
+		var job, reordered Job
+		Expect(json.Unmarshal([]byte(data5), &job)).To(Succeed())
+		Expect(json.Unmarshal([]byte(`{"id":"prod.example.app","labels":{"owner":"zeus","location":"olympus"},"description":"Example Application","run":{"volumes":[{"mode":"RW","hostPath":"/etc/guest","containerPath":"/mnt/test"}],"user":"root","restart":{"policy":"NEVER","activeDeadlineSeconds":120},"placement":{"constraints":[{"value":"rack-2","operator":"EQ","attribute":"rack"}]},"maxLaunchDelay":3600,"env":{"CONNECT":"direct","MON":"test"},"docker":{"image":"foo/bla:test"},"disk":128,"mem":32,"cpus":1.5,"args":["nuke","--dry","--master","local"],"cmd":"nuke --dry --master local","artifacts":[{"cache":false,"executable":true,"extract":true,"uri":"http://foo.test.com/application.zip"}]},"historySummary":{"successCount":3}}`), &reordered)).To(Succeed())
+		fp, err := job.Fingerprint()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fp).To(HaveLen(64))
+		Expect(reordered.Fingerprint()).To(Equal(fp))
+
+		job.Run.SetMem(64)
+		Expect(job.Fingerprint()).NotTo(Equal(fp))
+	})
+	It("Rejects malformed env values", func() {
+		var run Run
+		Expect(json.Unmarshal([]byte(`{"env":{"FOO":{"value":"bar"}}}`), &run)).NotTo(Succeed())
+		Expect(json.Unmarshal([]byte(`{"env":{"FOO":3}}`), &run)).NotTo(Succeed())
+	})
 
 })
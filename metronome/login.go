@@ -0,0 +1,112 @@
+package metronome
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ACSLoginPath - DC/OS endpoint exchanging a uid and password for an authentication token
+const ACSLoginPath = "/acs/api/v1/auth/login"
+
+// RefreshMargin - a token this close to expiring is refreshed before a request is sent with it
+const RefreshMargin = time.Minute
+
+// LoginToken - an authentication token and when it stops working.  A zero Expires never expires
+type LoginToken struct {
+	Token   string    `json:"token"`
+	Expires time.Time `json:"expires"`
+}
+
+// Valid - the token exists and is good for at least `margin` more
+func (token *LoginToken) Valid(now time.Time, margin time.Duration) bool {
+	if token == nil || token.Token == "" {
+		return false
+	}
+	return token.Expires.IsZero() || now.Add(margin).Before(token.Expires)
+}
+
+// ACSLoginURL - the login endpoint of the cluster serving `metronomeURL` e.g.
+//  https://dcos.example.com/service/metronome -> https://dcos.example.com/acs/api/v1/auth/login
+func ACSLoginURL(metronomeURL string) (string, error) {
+	parsed, err := url.Parse(metronomeURL)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return "", fmt.Errorf("'%s' isn't an absolute url", metronomeURL)
+	}
+	return parsed.Scheme + "://" + parsed.Host + ACSLoginPath, nil
+}
+
+// TokenExpiry - the exp claim of a JWT.  The signature isn't checked: the cluster does that
+func TokenExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, errors.New("token isn't a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, err
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, err
+	}
+	if claims.Exp == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(claims.Exp, 0).UTC(), nil
+}
+
+// Login - POST the uid and password to `loginURL` (see ACSLoginURL) using config's tls settings and timeout.
+//  Returns the token and its expiry, zero when the token doesn't say
+func Login(config Config, loginURL string, uid string, password string) (*LoginToken, error) {
+	transport, err := newTransport(config)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: time.Duration(config.RequestTimeout) * time.Second, Transport: transport}
+	body, err := json.Marshal(map[string]string{"uid": uid, "password": password})
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.Post(loginURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	raw, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		var acsError struct {
+			Description string `json:"description"`
+		}
+		if json.Unmarshal(raw, &acsError) == nil && acsError.Description != "" {
+			return nil, fmt.Errorf("login failed: %s: %s", response.Status, acsError.Description)
+		}
+		return nil, fmt.Errorf("login failed: %s", response.Status)
+	}
+	var token LoginToken
+	if err = json.Unmarshal(raw, &token); err != nil {
+		return nil, err
+	}
+	if token.Token == "" {
+		return nil, errors.New("login failed: no token in the response")
+	}
+	if token.Expires, err = TokenExpiry(token.Token); err != nil {
+		token.Expires = time.Time{}
+	}
+	return &token, nil
+}
@@ -0,0 +1,147 @@
+package metronome_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Login", func() {
+	var server *ghttp.Server
+	jsonHeader := http.Header{"Content-Type": {"application/json"}}
+	jwt := func(claims string) string {
+		return "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".c2ln"
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Finds the cluster's login endpoint", func() {
+		login, err := ACSLoginURL("https://dcos.example.com/service/metronome")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(login).To(Equal("https://dcos.example.com/acs/api/v1/auth/login"))
+		_, err = ACSLoginURL("dcos.example.com")
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("Exchanges a uid and password for a token and its expiry", func() {
+		token := jwt(`{"uid":"zeus","exp":1792368000}`)
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("POST", ACSLoginPath),
+			ghttp.VerifyJSON(`{"uid":"zeus","password":"secret"}`),
+			ghttp.RespondWith(http.StatusOK, `{"token":"`+token+`"}`, jsonHeader),
+		))
+		got, err := Login(Config{RequestTimeout: 5}, server.URL()+ACSLoginPath, "zeus", "secret")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(got.Token).To(Equal(token))
+		Expect(got.Expires).To(Equal(time.Unix(1792368000, 0).UTC()))
+		Expect(got.Valid(time.Unix(1792368000-120, 0), time.Minute)).To(BeTrue())
+		Expect(got.Valid(time.Unix(1792368000-30, 0), time.Minute)).To(BeFalse())
+	})
+
+	It("Reports the cluster's reason for refusing", func() {
+		server.AppendHandlers(ghttp.RespondWith(http.StatusUnauthorized, `{"title":"Unauthorized","description":"Invalid credentials"}`, jsonHeader))
+		_, err := Login(Config{RequestTimeout: 5}, server.URL()+ACSLoginPath, "zeus", "wrong")
+		Expect(err).To(MatchError("login failed: 401 Unauthorized: Invalid credentials"))
+	})
+
+	It("Treats a token without exp as never expiring", func() {
+		expires, err := TokenExpiry(jwt(`{"uid":"zeus"}`))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(expires.IsZero()).To(BeTrue())
+		Expect((&LoginToken{Token: "x"}).Valid(time.Now(), time.Hour)).To(BeTrue())
+		_, err = TokenExpiry("opaque")
+		Expect(err).Should(HaveOccurred())
+	})
+
+	Describe("Token refresh", func() {
+		listing := func(token string, status int) http.HandlerFunc {
+			return ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs"),
+				ghttp.VerifyHeader(http.Header{"Authorization": {token}}),
+				ghttp.RespondWith(status, `[]`, jsonHeader),
+			)
+		}
+		refresher := func(calls *int, token string, expires time.Time) func() (*LoginToken, error) {
+			return func() (*LoginToken, error) {
+				*calls++
+				return &LoginToken{Token: token, Expires: expires}, nil
+			}
+		}
+
+		It("Refreshes the token and retries once when metronome answers 401", func() {
+			calls := 0
+			server.AppendHandlers(
+				listing("token=old", http.StatusOK),
+				listing("token=old", http.StatusUnauthorized),
+				listing("token=new", http.StatusOK),
+				listing("token=new", http.StatusOK),
+			)
+			client, err := NewClient(Config{URL: server.URL(), RequestTimeout: 5, AuthToken: "token=old", RefreshToken: refresher(&calls, "new", time.Time{})})
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = client.Jobs()
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = client.Jobs()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(calls).To(Equal(1))
+			Expect(server.ReceivedRequests()).To(HaveLen(4))
+		})
+
+		It("Refreshes the token on a 401 when a user and password are set too", func() {
+			calls := 0
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs"),
+					ghttp.VerifyBasicAuth("zeus", "secret"),
+					ghttp.RespondWith(http.StatusUnauthorized, `[]`, jsonHeader),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs"),
+					func(w http.ResponseWriter, req *http.Request) {
+						Expect(req.Header["Authorization"]).To(ContainElement("token=new"))
+					},
+					ghttp.RespondWith(http.StatusOK, `[]`, jsonHeader),
+				),
+			)
+			config := Config{URL: server.URL(), RequestTimeout: 5, AuthToken: "token=old", User: "zeus", Pw: "secret"}
+			config.RefreshToken = refresher(&calls, "new", time.Time{})
+			_, err := NewClient(config)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(calls).To(Equal(1))
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("Refreshes a token about to expire before sending", func() {
+			calls := 0
+			server.AppendHandlers(
+				listing("token=new", http.StatusOK),
+				listing("token=new", http.StatusOK),
+			)
+			config := Config{URL: server.URL(), RequestTimeout: 5, AuthToken: "token=old", TokenExpires: time.Now().Add(30 * time.Second)}
+			config.RefreshToken = refresher(&calls, "new", time.Now().Add(time.Hour))
+			client, err := NewClient(config)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = client.Jobs()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(calls).To(Equal(1))
+		})
+
+		It("Fails with the 401 without a way to refresh", func() {
+			server.AppendHandlers(listing("token=old", http.StatusUnauthorized))
+			_, err := NewClient(Config{URL: server.URL(), RequestTimeout: 5, AuthToken: "token=old"})
+			Expect(err).Should(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+})
@@ -5,13 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+
+	log "github.com/behance/go-logrus"
 )
 
 var whitespaceRe = regexp.MustCompile(`\s+`)
 
-var errConstraintViol = errors.New("Bad constraint.  Must be EQ,LIKE,UNLIKE")
+var errConstraintViol = errors.New("Bad constraint.  Must be EQ,IS,LIKE,UNLIKE")
 var errMountViol = errors.New("Mount point must designate RW,RO")
 var errContainerPathViol = errors.New("Bad container path.  Must match `^/[^/].*$`")
+var errEnvValueViol = errors.New("Env value must be a string or {\"secret\": \"name\"}")
 
 func required(msg string) error {
 	if len(msg) == 0 {
@@ -26,6 +29,8 @@ type Artifact struct {
 	Executable bool   `json:"executable"`
 	Extract    bool   `json:"extract"`
 	Cache      bool   `json:"cache"`
+
+	Extra Extra `json:"-"`
 }
 
 // GetURI - return string copy
@@ -48,9 +53,22 @@ func (theArtifact *Artifact) ShouldCache() bool {
 	return theArtifact.Cache
 }
 
-// Docker - metronome limited docker image
+// DockerParameter - arbitrary `docker run` parameter e.g. {key: "label", value: "team=ops"}
+type DockerParameter struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+
+	Extra Extra `json:"-"`
+}
+
+// Docker - metronome docker container definition
 type Docker struct {
-	Image string `json:"image"`
+	Image          string            `json:"image"`
+	ForcePullImage bool              `json:"forcePullImage,omitempty"`
+	Privileged     bool              `json:"privileged,omitempty"`
+	Parameters     []DockerParameter `json:"parameters,omitempty"`
+
+	Extra Extra `json:"-"`
 }
 
 // NewDockerImage  - create a new image
@@ -66,6 +84,45 @@ func (docker *Docker) GetImage() string {
 	return docker.Image
 }
 
+// IsForcePullImage - is the image pulled on every launch
+func (docker *Docker) IsForcePullImage() bool {
+	return docker.ForcePullImage
+}
+
+// SetForcePullImage - pull the image on every launch
+func (docker *Docker) SetForcePullImage(force bool) *Docker {
+	docker.ForcePullImage = force
+	return docker
+}
+
+// IsPrivileged - does the container run privileged
+func (docker *Docker) IsPrivileged() bool {
+	return docker.Privileged
+}
+
+// SetPrivileged - run the container privileged
+func (docker *Docker) SetPrivileged(privileged bool) *Docker {
+	docker.Privileged = privileged
+	return docker
+}
+
+// GetParameters - accessor returning the docker parameters
+func (docker *Docker) GetParameters() []DockerParameter {
+	return docker.Parameters
+}
+
+// SetParameters - replace the docker parameters
+func (docker *Docker) SetParameters(params []DockerParameter) *Docker {
+	docker.Parameters = params
+	return docker
+}
+
+// AddParameter - append a docker parameter
+func (docker *Docker) AddParameter(key string, value string) *Docker {
+	docker.Parameters = append(docker.Parameters, DockerParameter{Key: key, Value: value})
+	return docker
+}
+
 // constraint support
 
 // Operator - constrain operator values
@@ -78,12 +135,15 @@ const (
 	LIKE
 	// UNLIKE - operator
 	UNLIKE
+	// IS - operator.  Replaces EQ in newer Metronome releases
+	IS
 )
 
 var constraintOperators = [...]string{
 	"EQ",
 	"LIKE",
 	"UNLIKE",
+	"IS",
 }
 
 // String - string rep of operator
@@ -98,8 +158,10 @@ func decodeOperator(op string) (Operator, error) {
 		return LIKE, nil
 	case "UNLIKE":
 		return UNLIKE, nil
+	case "IS":
+		return IS, nil
 	default:
-		fmt.Printf("Operator.UnmarshallJSON - unknown value '%s'\n", op)
+		log.Debugf("Operator.UnmarshallJSON - unknown value '%s'", op)
 		return -1, errConstraintViol
 	}
 
@@ -133,13 +195,15 @@ type Constraint struct {
 	// operator is EQ, LIKE,UNLIKE
 	Operator Operator `json:"operator"`
 	Value    string   `json:"value"`
+
+	Extra Extra `json:"-"`
 }
 
 // StrToConstraint - takes constraint as described in Metronome documentation
 func StrToConstraint(cli string) (*Constraint, error) {
 	args := whitespaceRe.Split(cli, -1)
 	if len(args) != 3 {
-		return nil, errors.New("Not enough constraint args `attribute` {EQ|LIKE} value")
+		return nil, errors.New("Not enough constraint args `attribute` {EQ|IS|LIKE|UNLIKE} value")
 	}
 	op, err := decodeOperator(args[1])
 	if err != nil {
@@ -174,6 +238,8 @@ func (theConstraint *Constraint) GetValue() string {
 // Placement - Metronome placement
 type Placement struct {
 	Constraints []Constraint `json:"constraints"`
+
+	Extra Extra `json:"-"`
 }
 
 // GetConstraints - return constraints
@@ -181,6 +247,18 @@ func (thePlacement *Placement) GetConstraints() ([]Constraint, error) {
 	return thePlacement.Constraints, nil
 }
 
+// SetConstraints - replace the constraints
+func (thePlacement *Placement) SetConstraints(constraints []Constraint) *Placement {
+	thePlacement.Constraints = constraints
+	return thePlacement
+}
+
+// AddConstraint - append a constraint
+func (thePlacement *Placement) AddConstraint(constraint Constraint) *Placement {
+	thePlacement.Constraints = append(thePlacement.Constraints, constraint)
+	return thePlacement
+}
+
 //
 // volume types
 //
@@ -253,8 +331,10 @@ func (cp *ContainerPath) UnmarshalJSON(raw []byte) error {
 	// byte must be unmarshalled as a string otherwise there are cases where the quotes will bleed
 	// through
 	var s string
-	json.Unmarshal(raw, &s)
-	if _, err := regexp.MatchString("^/[^/].*$", s); err != nil {
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return err
+	}
+	if !containerPathRe.MatchString(s) {
 		return errContainerPathViol
 	}
 
@@ -264,8 +344,8 @@ func (cp *ContainerPath) UnmarshalJSON(raw []byte) error {
 
 // NewContainerPath - create a new container path that's checked for validity per Metronome's doc
 func NewContainerPath(path string) (self ContainerPath, err error) {
-	if _, err = regexp.MatchString("^/[^/].*$", path); err != nil {
-		return "", err
+	if !containerPathRe.MatchString(path) {
+		return "", errContainerPathViol
 	}
 	vg := ContainerPath(path)
 
@@ -280,6 +360,8 @@ type Volume struct {
 	HostPath      string        `json:"hostPath"`
 	// Values: RW,RO
 	Mode MountMode `json:"mode"`
+
+	Extra Extra `json:"-"`
 }
 
 // NewVolume - creates a new volume from raw strings
@@ -307,6 +389,8 @@ func NewVolume(rawPath string, hostPath string, modestr string) (*Volume, error)
 type Restart struct {
 	ActiveDeadlineSeconds int    `json:"activeDeadlineSeconds"`
 	Policy                string `json:"policy"`
+
+	Extra Extra `json:"-"`
 }
 
 // NewRestart - create a valid Restart policy
@@ -319,22 +403,154 @@ func NewRestart(activeDeadlineSeconds int, policy string) (*Restart, error) {
 	return &Restart{ActiveDeadlineSeconds: activeDeadlineSeconds, Policy: policy}, nil
 }
 
+// GetPolicy - accessor
+func (restart *Restart) GetPolicy() string {
+	return restart.Policy
+}
+
+// SetPolicy - set the restart policy.  NEVER or ON_FAILURE
+func (restart *Restart) SetPolicy(policy string) *Restart {
+	restart.Policy = policy
+	return restart
+}
+
+// GetActiveDeadlineSeconds - accessor
+func (restart *Restart) GetActiveDeadlineSeconds() int {
+	return restart.ActiveDeadlineSeconds
+}
+
+// SetActiveDeadlineSeconds - how long restarts are attempted after a failure
+func (restart *Restart) SetActiveDeadlineSeconds(seconds int) *Restart {
+	restart.ActiveDeadlineSeconds = seconds
+	return restart
+}
+
+// Network - a network the run's task joins.  Mode is host, container or container/bridge
+type Network struct {
+	Name   string            `json:"name,omitempty"`
+	Mode   string            `json:"mode"`
+	Labels map[string]string `json:"labels,omitempty"`
+
+	Extra Extra `json:"-"`
+}
+
+// Network modes accepted by Metronome
+const (
+	NetworkHost            = "host"
+	NetworkContainer       = "container"
+	NetworkContainerBridge = "container/bridge"
+)
+
+// NewNetwork - create a network.  container mode requires a name
+func NewNetwork(mode string, name string) (*Network, error) {
+	switch mode {
+	case NetworkHost, NetworkContainerBridge:
+	case NetworkContainer:
+		if name == "" {
+			return nil, required("Network.name for container mode")
+		}
+	default:
+		return nil, fmt.Errorf("Network mode must be '%s', '%s' or '%s' not %s", NetworkHost, NetworkContainer, NetworkContainerBridge, mode)
+	}
+	return &Network{Mode: mode, Name: name}, nil
+}
+
+// EnvValue - value of a Run environment variable.  Either a literal or a reference to a secret declared in Run.Secrets
+//  Literals marshal as a json string, secret references as {"secret": "name"}
+type EnvValue struct {
+	Value  string
+	Secret string
+}
+
+type envSecretRef struct {
+	Secret string `json:"secret"`
+}
+
+// EnvLiteral - environment value holding a plain string
+func EnvLiteral(value string) EnvValue {
+	return EnvValue{Value: value}
+}
+
+// EnvSecret - environment value referencing the Run.Secrets entry `name`
+func EnvSecret(name string) EnvValue {
+	return EnvValue{Secret: name}
+}
+
+// IsSecret - does the value reference a secret
+func (ev EnvValue) IsSecret() bool {
+	return ev.Secret != ""
+}
+
+// String - the literal or a secret marker
+func (ev EnvValue) String() string {
+	if ev.IsSecret() {
+		return fmt.Sprintf("secret(%s)", ev.Secret)
+	}
+	return ev.Value
+}
+
+// MarshalJSON - json interface implementation
+func (ev EnvValue) MarshalJSON() ([]byte, error) {
+	if ev.IsSecret() {
+		return json.Marshal(envSecretRef{Secret: ev.Secret})
+	}
+	return json.Marshal(ev.Value)
+}
+
+// UnmarshalJSON - json interface implementation.  Accepts a string or a secret reference object
+func (ev *EnvValue) UnmarshalJSON(raw []byte) error {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		*ev = EnvLiteral(s)
+		return nil
+	}
+	var ref envSecretRef
+	if err := json.Unmarshal(raw, &ref); err != nil {
+		return errEnvValueViol
+	}
+	if ref.Secret == "" {
+		return errEnvValueViol
+	}
+	*ev = EnvSecret(ref.Secret)
+	return nil
+}
+
+// Secret - a secret made available to a Run.  Source is the path in the secret store
+type Secret struct {
+	Source string `json:"source"`
+
+	Extra Extra `json:"-"`
+}
+
+// GetSource - accessor
+func (theSecret *Secret) GetSource() string {
+	return theSecret.Source
+}
+
 // Run - composite structure representing Metronone run
 type Run struct {
 	Artifacts []Artifact `json:"artifacts,omitempty"`
 	Cmd       string     `json:"cmd,omitempty"`
 
-	Args           []string          `json:"args,omitempty"`
-	Cpus           float64           `json:"cpus"`
-	Mem            int               `json:"mem"`
-	Disk           int               `json:"disk"`
-	Docker         *Docker           `json:"docker,omitempty"`
-	Env            map[string]string `json:"env,omitempty"`
-	MaxLaunchDelay int               `json:"maxLaunchDelay"`
-	Placement      *Placement        `json:"placement,omitempty"`
-	Restart        *Restart          `json:"restart,omitempty"`
-	User           string            `json:"user,omitempty"`
-	Volumes        []Volume          `json:"volumes"`
+	Args           []string            `json:"args,omitempty"`
+	Cpus           float64             `json:"cpus"`
+	Mem            int                 `json:"mem"`
+	Disk           int                 `json:"disk"`
+	Gpus           int                 `json:"gpus,omitempty"`
+	Docker         *Docker             `json:"docker,omitempty"`
+	Env            map[string]EnvValue `json:"env,omitempty"`
+	MaxLaunchDelay int                 `json:"maxLaunchDelay"`
+	Networks       []Network           `json:"networks,omitempty"`
+	Placement      *Placement          `json:"placement,omitempty"`
+	Restart        *Restart            `json:"restart,omitempty"`
+	Secrets        map[string]Secret   `json:"secrets,omitempty"`
+	Ucr            *Ucr                `json:"ucr,omitempty"`
+	User           string              `json:"user,omitempty"`
+	Volumes        []Volume            `json:"volumes"`
+
+	TaskKillGracePeriodSeconds int `json:"taskKillGracePeriodSeconds,omitempty"`
+
+	Extra Extra `json:"-"`
 }
 
 // GetArtifacts - accessor returning Artifacts
@@ -408,6 +624,17 @@ func (runner *Run) SetDisk(p int) *Run {
 	return runner
 }
 
+// GetGpus - the number of gpus to assign
+func (runner *Run) GetGpus() int {
+	return runner.Gpus
+}
+
+// SetGpus - set the number of gpus to use
+func (runner *Run) SetGpus(p int) *Run {
+	runner.Gpus = p
+	return runner
+}
+
 // GetDocker - accessor returning the docker structure if set
 func (runner *Run) GetDocker() *Docker {
 	return runner.Docker
@@ -420,16 +647,54 @@ func (runner *Run) SetDocker(docker *Docker) *Run {
 }
 
 // GetEnv - return the current environment
-func (runner *Run) GetEnv() map[string]string {
+func (runner *Run) GetEnv() map[string]EnvValue {
 	return runner.Env
 }
 
 // SetEnv - replace the environment to use
-func (runner *Run) SetEnv(mp map[string]string) *Run {
+func (runner *Run) SetEnv(mp map[string]EnvValue) *Run {
 	runner.Env = mp
 	return runner
 }
 
+// AddEnv - set a literal environment variable
+func (runner *Run) AddEnv(name string, value string) *Run {
+	if runner.Env == nil {
+		runner.Env = make(map[string]EnvValue)
+	}
+	runner.Env[name] = EnvLiteral(value)
+	return runner
+}
+
+// AddSecretEnv - set an environment variable whose value comes from the Run.Secrets entry `secret`
+func (runner *Run) AddSecretEnv(name string, secret string) *Run {
+	if runner.Env == nil {
+		runner.Env = make(map[string]EnvValue)
+	}
+	runner.Env[name] = EnvSecret(secret)
+	return runner
+}
+
+// GetSecrets - return the secrets declared for the run
+func (runner *Run) GetSecrets() map[string]Secret {
+	return runner.Secrets
+}
+
+// SetSecrets - replace the secrets declared for the run
+func (runner *Run) SetSecrets(secrets map[string]Secret) *Run {
+	runner.Secrets = secrets
+	return runner
+}
+
+// AddSecret - declare secret `name` read from `source` in the secret store
+func (runner *Run) AddSecret(name string, source string) *Run {
+	if runner.Secrets == nil {
+		runner.Secrets = make(map[string]Secret)
+	}
+	runner.Secrets[name] = Secret{Source: source}
+	return runner
+}
+
 // GetMaxLaunchDelay - accessor returning the maximum launch delay
 func (runner *Run) GetMaxLaunchDelay() int {
 	return runner.MaxLaunchDelay
@@ -441,6 +706,23 @@ func (runner *Run) SetMaxLaunchDelay(p int) *Run {
 	return runner
 }
 
+// GetNetworks - the networks the task joins
+func (runner *Run) GetNetworks() []Network {
+	return runner.Networks
+}
+
+// SetNetworks - replace the networks the task joins
+func (runner *Run) SetNetworks(networks []Network) *Run {
+	runner.Networks = networks
+	return runner
+}
+
+// AddNetwork - join an additional network
+func (runner *Run) AddNetwork(network Network) *Run {
+	runner.Networks = append(runner.Networks, network)
+	return runner
+}
+
 // GetPlacement - get the placement
 func (runner *Run) GetPlacement() *Placement {
 	return runner.Placement
@@ -463,6 +745,28 @@ func (runner *Run) SetRestart(restart *Restart) *Run {
 	return runner
 }
 
+// GetTaskKillGracePeriodSeconds - seconds between SIGTERM and SIGKILL when the task is killed
+func (runner *Run) GetTaskKillGracePeriodSeconds() int {
+	return runner.TaskKillGracePeriodSeconds
+}
+
+// SetTaskKillGracePeriodSeconds - set the grace period between SIGTERM and SIGKILL
+func (runner *Run) SetTaskKillGracePeriodSeconds(p int) *Run {
+	runner.TaskKillGracePeriodSeconds = p
+	return runner
+}
+
+// GetUcr - accessor returning the UCR container if set
+func (runner *Run) GetUcr() *Ucr {
+	return runner.Ucr
+}
+
+// SetUcr - run in the Universal Container Runtime.  Mutually exclusive with SetDocker
+func (runner *Run) SetUcr(ucr *Ucr) *Run {
+	runner.Ucr = ucr
+	return runner
+}
+
 // GetUser - get the user
 func (runner *Run) GetUser() string {
 	return runner.User
@@ -503,7 +807,7 @@ func NewRun(cpus float64, mem int, disk int) (*Run, error) {
 		Mem:            mem,
 		Disk:           disk,
 		Docker:         nil,
-		Env:            make(map[string]string),
+		Env:            make(map[string]EnvValue),
 		MaxLaunchDelay: 0,
 		Placement:      nil,
 		Restart:        nil,
@@ -525,6 +829,8 @@ type Job struct {
 	ActiveRuns     []*ActiveRun    `json:"activeRuns,omitempty"`
 	History        *History        `json:"history,omitempty"`
 	HistorySummary *HistorySummary `json:"historySummary,omitempty"`
+
+	Extra Extra `json:"-"`
 }
 
 //NewJob - create a job checking for some required fields
@@ -590,6 +896,17 @@ func (theJob *Job) SetLabel(label Labels) *Job {
 	return theJob
 }
 
+// Spec - the job without the fields metronome fills in (schedules, activeRuns, history, historySummary).
+//  What PUT /v1/jobs/$jobId accepts.  Shallow: the copy shares Run and Labels with the original
+func (theJob *Job) Spec() *Job {
+	spec := *theJob
+	spec.Schedules = nil
+	spec.ActiveRuns = nil
+	spec.History = nil
+	spec.HistorySummary = nil
+	return &spec
+}
+
 // Schedule - represent a metronome schedule
 type Schedule struct {
 	ID                      string `json:"id"`
@@ -599,6 +916,8 @@ type Schedule struct {
 	StartingDeadlineSeconds int    `json:"startingDeadlineSeconds"`
 	Timezone                string `json:"timezone"`
 	NextRunAt               string `json:"nextRunAt,omitempty"`
+
+	Extra Extra `json:"-"`
 }
 
 // JobStatus - represents a metronome job status
@@ -0,0 +1,158 @@
+package metronome
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OverlaysMember - spec file member holding per-environment overlays: partial job specs keyed by environment name e.g.
+//  {"id": "report", "run": {...}, "overlays": {"prod": {"run": {"cpus": 2, "env": {"MODE": "prod"}}}}}
+const OverlaysMember = "overlays"
+
+// StrategicMerge - merge the json document `overlay` onto `base`.
+//  Objects (labels, run.env, run.docker...) merge member by member and a null member is removed, as in a merge patch.
+//  `schedules` merge by id: an overlay schedule changes the base schedule with the same id or is added.  Other arrays replace
+func StrategicMerge(base []byte, overlay []byte) ([]byte, error) {
+	doc, err := decodeDoc(base)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := decodeDoc(overlay)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := strategicValue(doc, patch, "")
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merged)
+}
+
+func strategicValue(target interface{}, patch interface{}, path string) (interface{}, error) {
+	if path == "schedules" {
+		return mergeByID(target, patch)
+	}
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch, nil
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+	for name, value := range patchObj {
+		if value == nil {
+			delete(targetObj, name)
+			continue
+		}
+		child := name
+		if path != "" {
+			child = path + "." + name
+		}
+		merged, err := strategicValue(targetObj[name], value, child)
+		if err != nil {
+			return nil, err
+		}
+		targetObj[name] = merged
+	}
+	return targetObj, nil
+}
+
+// mergeByID - merge a list of schedules onto another matching on id.  Base order is kept, new schedules go last
+func mergeByID(target interface{}, patch interface{}) (interface{}, error) {
+	patchArr, ok := patch.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("overlay schedules must be a list")
+	}
+	targetArr, _ := target.([]interface{})
+	index := make(map[string]int)
+	for i, item := range targetArr {
+		if obj, ok := item.(map[string]interface{}); ok {
+			if id, ok := obj["id"].(string); ok {
+				index[id] = i
+			}
+		}
+	}
+	for _, item := range patchArr {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("overlay schedules must be objects")
+		}
+		id, _ := obj["id"].(string)
+		if id == "" {
+			return nil, fmt.Errorf("overlay schedules need an id")
+		}
+		if i, found := index[id]; found {
+			merged, err := strategicValue(targetArr[i], obj, "schedules[]")
+			if err != nil {
+				return nil, err
+			}
+			targetArr[i] = merged
+		} else {
+			index[id] = len(targetArr)
+			targetArr = append(targetArr, obj)
+		}
+	}
+	return targetArr, nil
+}
+
+// Overlays - the names of the overlays the spec carries, sorted
+func (theJob *Job) Overlays() ([]string, error) {
+	raw, ok := theJob.Extra[OverlaysMember]
+	if !ok {
+		return nil, nil
+	}
+	var overlays map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &overlays); err != nil {
+		return nil, fmt.Errorf("%s: %s", OverlaysMember, err.Error())
+	}
+	return overlayNames(overlays), nil
+}
+
+func overlayNames(overlays map[string]json.RawMessage) []string {
+	names := make([]string, 0, len(overlays))
+	for name := range overlays {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveOverlay - merge the overlay called `name` into the job (see StrategicMerge) and drop the overlays member so it isn't sent to metronome.
+//  An empty name only drops the member.  Naming an overlay the spec doesn't have is an error
+func (theJob *Job) ResolveOverlay(name string) error {
+	raw, ok := theJob.Extra[OverlaysMember]
+	delete(theJob.Extra, OverlaysMember)
+	if len(theJob.Extra) == 0 {
+		theJob.Extra = nil
+	}
+	if name == "" {
+		return nil
+	}
+	var overlays map[string]json.RawMessage
+	if ok {
+		if err := json.Unmarshal(raw, &overlays); err != nil {
+			return fmt.Errorf("%s: %s", OverlaysMember, err.Error())
+		}
+	}
+	overlay, ok := overlays[name]
+	if !ok {
+		return fmt.Errorf("no overlay '%s'; the spec has [%s]", name, strings.Join(overlayNames(overlays), ","))
+	}
+	base, err := json.Marshal(theJob)
+	if err != nil {
+		return err
+	}
+	merged, err := StrategicMerge(base, overlay)
+	if err != nil {
+		return fmt.Errorf("overlay %s: %s", name, err.Error())
+	}
+	var job Job
+	if err = json.Unmarshal(merged, &job); err != nil {
+		return fmt.Errorf("overlay %s: %s", name, err.Error())
+	}
+	*theJob = job
+	return nil
+}
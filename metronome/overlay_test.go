@@ -0,0 +1,65 @@
+package metronome_test
+
+import (
+	"encoding/json"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Overlay", func() {
+	const spec = `{"id":"report","labels":{"team":"reports"},
+		"run":{"cmd":"make-report","args":["--all"],"cpus":0.5,"mem":128,"disk":0,"maxLaunchDelay":60,"env":{"MODE":"staging","LOG":"info"},"docker":{"image":"reports:rc"}},
+		"schedules":[{"id":"nightly","cron":"0 3 * * *","concurrencyPolicy":"FORBID","enabled":true,"startingDeadlineSeconds":60,"timezone":"UTC"}],
+		"overlays":{
+			"prod":{"labels":{"tier":"prod"},"run":{"cpus":2,"args":["--fast"],"env":{"MODE":"prod","LOG":null},"docker":{"image":"reports:1.4"}},
+				"schedules":[{"id":"nightly","cron":"0 1 * * *"},{"id":"weekly","cron":"0 4 * * 0","concurrencyPolicy":"ALLOW","enabled":true,"startingDeadlineSeconds":60,"timezone":"UTC"}]},
+			"staging":{}
+		}}`
+	var job Job
+
+	BeforeEach(func() {
+		job = Job{}
+		Expect(json.Unmarshal([]byte(spec), &job)).To(Succeed())
+	})
+
+	It("Lists the overlays", func() {
+		Expect(job.Overlays()).To(Equal([]string{"prod", "staging"}))
+	})
+
+	It("Merges maps, schedules by id and replaces other lists", func() {
+		Expect(job.ResolveOverlay("prod")).To(Succeed())
+		Expect(*job.GetLabels()).To(Equal(Labels{"team": "reports", "tier": "prod"}))
+		Expect(job.Run.GetCpus()).To(Equal(2.0))
+		Expect(*job.Run.GetArgs()).To(Equal([]string{"--fast"}))
+		Expect(job.Run.Env).To(HaveLen(1))
+		Expect(job.Run.Docker.GetImage()).To(Equal("reports:1.4"))
+		Expect(job.Run.GetMaxLaunchDelay()).To(Equal(60))
+		Expect(job.Schedules).To(HaveLen(2))
+		Expect(job.Schedules[0].ID).To(Equal("nightly"))
+		Expect(job.Schedules[0].Cron).To(Equal("0 1 * * *"))
+		Expect(job.Schedules[0].ConcurrencyPolicy).To(Equal("FORBID"))
+		Expect(job.Schedules[1].ID).To(Equal("weekly"))
+		Expect(job.Extra).To(BeNil())
+		Expect(job.Validate()).To(Succeed())
+	})
+
+	It("Drops the overlays member for the base spec", func() {
+		Expect(job.ResolveOverlay("")).To(Succeed())
+		Expect(job.Run.GetCpus()).To(Equal(0.5))
+		out, err := json.Marshal(job)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).NotTo(ContainSubstring("overlays"))
+	})
+
+	It("Rejects an unknown overlay", func() {
+		Expect(job.ResolveOverlay("dev")).To(MatchError("no overlay 'dev'; the spec has [prod,staging]"))
+	})
+
+	It("Needs schedule ids to merge schedules", func() {
+		_, err := StrategicMerge([]byte(`{"schedules":[{"id":"a"}]}`), []byte(`{"schedules":[{"cron":"* * * * *"}]}`))
+		Expect(err).To(MatchError("overlay schedules need an id"))
+	})
+})
@@ -0,0 +1,254 @@
+package metronome
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// patch paths use the same form as FieldError.Field e.g. run.volumes[1].mode or run.env.FOO
+var pathSegmentRe = regexp.MustCompile(`^([^\[\]]+)((?:\[[0-9]+\])*)$`)
+var pathIndexRe = regexp.MustCompile(`\[([0-9]+)\]`)
+
+// pathStep - one step of a patch path.  Either an object member or an array index
+type pathStep struct {
+	member string
+	index  int
+}
+
+func parsePath(path string) ([]pathStep, error) {
+	if path == "" {
+		return nil, required("path")
+	}
+	var steps []pathStep
+	for _, seg := range strings.Split(path, ".") {
+		m := pathSegmentRe.FindStringSubmatch(seg)
+		if m == nil {
+			return nil, fmt.Errorf("bad path '%s' at '%s'", path, seg)
+		}
+		steps = append(steps, pathStep{member: m[1], index: -1})
+		for _, idx := range pathIndexRe.FindAllStringSubmatch(m[2], -1) {
+			n, _ := strconv.Atoi(idx[1])
+			steps = append(steps, pathStep{index: n})
+		}
+	}
+	return steps, nil
+}
+
+// decodeDoc - exactly one json value; anything after it is an error so '2 garbage' isn't read as 2
+func decodeDoc(raw []byte) (interface{}, error) {
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the json value")
+	}
+	return doc, nil
+}
+
+// MergePatch - apply an RFC 7386 json merge patch to `original`
+//  Objects in the patch are merged member by member; null deletes a member; anything else, arrays included, replaces
+func MergePatch(original []byte, patch []byte) ([]byte, error) {
+	var doc interface{}
+	if len(bytes.TrimSpace(original)) > 0 {
+		var err error
+		if doc, err = decodeDoc(original); err != nil {
+			return nil, err
+		}
+	}
+	p, err := decodeDoc(patch)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeValue(doc, p))
+}
+
+func mergeValue(target interface{}, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+	for name, value := range patchObj {
+		if value == nil {
+			delete(targetObj, name)
+		} else {
+			targetObj[name] = mergeValue(targetObj[name], value)
+		}
+	}
+	return targetObj
+}
+
+// setPath - set the value at `steps` creating intermediate objects as needed
+func setPath(doc interface{}, steps []pathStep, value interface{}) (interface{}, error) {
+	if len(steps) == 0 {
+		return value, nil
+	}
+	step := steps[0]
+	if step.member != "" {
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			if doc != nil {
+				return nil, fmt.Errorf("'%s' is not an object", step.member)
+			}
+			obj = make(map[string]interface{})
+		}
+		child, err := setPath(obj[step.member], steps[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		obj[step.member] = child
+		return obj, nil
+	}
+	arr, ok := doc.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("[%d] indexes a non array", step.index)
+	}
+	switch {
+	case step.index < len(arr):
+	case step.index == len(arr):
+		// one past the end appends
+		arr = append(arr, nil)
+	default:
+		return nil, fmt.Errorf("[%d] out of range, length %d", step.index, len(arr))
+	}
+	child, err := setPath(arr[step.index], steps[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	arr[step.index] = child
+	return arr, nil
+}
+
+// unsetPath - remove the member or array element at `steps`.  Missing members are not an error
+func unsetPath(doc interface{}, steps []pathStep) (interface{}, error) {
+	step := steps[0]
+	if step.member != "" {
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return doc, nil
+		}
+		if len(steps) == 1 {
+			delete(obj, step.member)
+			return obj, nil
+		}
+		child, ok := obj[step.member]
+		if !ok {
+			return obj, nil
+		}
+		child, err := unsetPath(child, steps[1:])
+		if err != nil {
+			return nil, err
+		}
+		obj[step.member] = child
+		return obj, nil
+	}
+	arr, ok := doc.([]interface{})
+	if !ok || step.index >= len(arr) {
+		return doc, nil
+	}
+	if len(steps) == 1 {
+		return append(arr[:step.index], arr[step.index+1:]...), nil
+	}
+	child, err := unsetPath(arr[step.index], steps[1:])
+	if err != nil {
+		return nil, err
+	}
+	arr[step.index] = child
+	return arr, nil
+}
+
+// rewrite - run `edit` over the job's json document and replace the job with the result
+func (theJob *Job) rewrite(edit func(doc interface{}) (interface{}, error)) error {
+	raw, err := json.Marshal(theJob)
+	if err != nil {
+		return err
+	}
+	doc, err := decodeDoc(raw)
+	if err != nil {
+		return err
+	}
+	if doc, err = edit(doc); err != nil {
+		return err
+	}
+	if raw, err = json.Marshal(doc); err != nil {
+		return err
+	}
+	var patched Job
+	if err = json.Unmarshal(raw, &patched); err != nil {
+		return err
+	}
+	*theJob = patched
+	return nil
+}
+
+// Set - set the field at `path` (e.g. run.cpus, run.env.FOO, run.volumes[0].mode) to `value`.
+//  `value` is json; when it isn't, or doesn't fit the field, it is used as a plain string so `run.user=nobody` works unquoted
+func (theJob *Job) Set(path string, value string) error {
+	steps, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	set := func(value interface{}) error {
+		return theJob.rewrite(func(doc interface{}) (interface{}, error) {
+			return setPath(doc, steps, value)
+		})
+	}
+	if parsed, jerr := decodeDoc([]byte(value)); jerr == nil {
+		if err = set(parsed); err == nil {
+			return nil
+		}
+		if _, isString := parsed.(string); isString {
+			return err
+		}
+	}
+	if serr := set(value); serr != nil {
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+		return fmt.Errorf("%s: %s", path, serr)
+	}
+	return nil
+}
+
+// Unset - remove the field at `path`.  Removing an absent field is a no-op
+func (theJob *Job) Unset(path string) error {
+	steps, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	if err = theJob.rewrite(func(doc interface{}) (interface{}, error) {
+		return unsetPath(doc, steps)
+	}); err != nil {
+		return fmt.Errorf("%s: %s", path, err)
+	}
+	return nil
+}
+
+// ApplyMergePatch - apply an RFC 7386 json merge patch to the job
+func (theJob *Job) ApplyMergePatch(patch []byte) error {
+	raw, err := json.Marshal(theJob)
+	if err != nil {
+		return err
+	}
+	if raw, err = MergePatch(raw, patch); err != nil {
+		return err
+	}
+	var patched Job
+	if err = json.Unmarshal(raw, &patched); err != nil {
+		return err
+	}
+	*theJob = patched
+	return nil
+}
@@ -0,0 +1,112 @@
+package metronome_test
+
+import (
+	"encoding/json"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Patch", func() {
+	Describe("MergePatch", func() {
+		It("Follows the RFC 7386 appendix A examples", func() {
+			for _, example := range [][3]string{
+				{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+				{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+				{`{"a":"b"}`, `{"a":null}`, `{}`},
+				{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+				{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+				{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+				{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+				{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+				{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+				{`{"a":"b"}`, `["c"]`, `["c"]`},
+				{`{"a":"foo"}`, `null`, `null`},
+				{`{"a":"foo"}`, `"bar"`, `"bar"`},
+				{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+				{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+				{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+			} {
+				out, err := MergePatch([]byte(example[0]), []byte(example[1]))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(out).To(MatchJSON(example[2]), "%s + %s", example[0], example[1])
+			}
+		})
+	})
+
+	Describe("Job edits", func() {
+		var job Job
+
+		BeforeEach(func() {
+			job = Job{}
+			Expect(json.Unmarshal([]byte(data5), &job)).To(Succeed())
+		})
+
+		It("Sets json values and falls back to strings", func() {
+			Expect(job.Set("run.cpus", "2")).To(Succeed())
+			Expect(job.Set("run.user", "nobody")).To(Succeed())
+			Expect(job.Set("run.cmd", "true")).To(Succeed())
+			Expect(job.Set("run.env.NEW", "42")).To(Succeed())
+			Expect(job.Set("run.volumes[0].mode", "RO")).To(Succeed())
+			Expect(job.Set("run.args[4]", "--verbose")).To(Succeed())
+			Expect(job.Set("labels.team", "ops")).To(Succeed())
+
+			Expect(job.Run.Cpus).To(Equal(2.0))
+			Expect(job.Run.User).To(Equal("nobody"))
+			Expect(job.Run.Cmd).To(Equal("true"))
+			Expect(job.Run.Env["NEW"]).To(Equal(EnvLiteral("42")))
+			Expect(job.Run.Volumes[0].Mode).To(Equal(RO))
+			Expect(job.Run.Args).To(HaveLen(5))
+			Expect((*job.Labels)["team"]).To(Equal("ops"))
+			// untouched
+			Expect(job.Run.MaxLaunchDelay).To(Equal(3600))
+		})
+
+		It("Rejects values that don't fit and leaves the job alone", func() {
+			Expect(job.Set("run.cpus", "lots")).NotTo(Succeed())
+			Expect(job.Set("run.volumes[0].mode", "RX")).NotTo(Succeed())
+			Expect(job.Set("run.args[9]", "x")).NotTo(Succeed())
+			Expect(job.Set("run..cpus", "1")).NotTo(Succeed())
+			Expect(job.Set("run.cpus", "2 garbage")).NotTo(Succeed())
+			Expect(job.Set("run.cpus", "2}")).NotTo(Succeed())
+			Expect(job.Run.Cpus).To(Equal(1.5))
+			Expect(job.Run.Volumes[0].Mode).To(Equal(RW))
+		})
+
+		It("Unsets fields", func() {
+			Expect(job.Unset("run.env.MON")).To(Succeed())
+			Expect(job.Unset("run.args[0]")).To(Succeed())
+			Expect(job.Unset("run.docker")).To(Succeed())
+			Expect(job.Unset("run.not.there")).To(Succeed())
+			Expect(job.Run.Env).To(Equal(map[string]EnvValue{"CONNECT": EnvLiteral("direct")}))
+			Expect(job.Run.Args).To(Equal([]string{"--dry", "--master", "local"}))
+			Expect(job.Run.Docker).To(BeNil())
+		})
+
+		It("Rejects a merge patch with trailing data", func() {
+			Expect(job.ApplyMergePatch([]byte(`{"run":{"cpus":2}} {"run":{"cpus":3}}`))).NotTo(Succeed())
+			Expect(job.Run.Cpus).To(Equal(1.5))
+		})
+
+		It("Applies a merge patch", func() {
+			Expect(job.ApplyMergePatch([]byte(`{"description":"patched","run":{"mem":64,"env":{"MON":null,"EXTRA":"x"},"restart":null}}`))).To(Succeed())
+			Expect(job.Description).To(Equal("patched"))
+			Expect(job.Run.Mem).To(Equal(64))
+			Expect(job.Run.Env).To(Equal(map[string]EnvValue{"CONNECT": EnvLiteral("direct"), "EXTRA": EnvLiteral("x")}))
+			Expect(job.Run.Restart).To(BeNil())
+			Expect(job.Run.Cpus).To(Equal(1.5))
+		})
+
+		It("Strips read-only fields for the spec", func() {
+			job.Schedules = []*Schedule{{ID: "s1"}}
+			job.History = &History{}
+			spec := job.Spec()
+			Expect(spec.Schedules).To(BeNil())
+			Expect(spec.History).To(BeNil())
+			Expect(job.Schedules).To(HaveLen(1))
+			Expect(spec.Run).To(BeIdenticalTo(job.Run))
+		})
+	})
+})
@@ -0,0 +1,157 @@
+package metronome
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RunAtPrefix - id prefix of the one-time schedules RunAt creates.  The rest of the id is the UTC fire time, then -2, -3...
+//  for further runs of the job in the same minute
+const RunAtPrefix = "run-at-"
+
+// runAtIDLayout - the fire time in a schedule id; ids are lower case letters, digits and dashes
+const runAtIDLayout = "200601021504"
+
+// runAtDeadline - startingDeadlineSeconds of one-time schedules
+const runAtDeadline = 60
+
+// OneTimeCron - a cron expression, in UTC, for the minute `at` falls in.  Cron has no year field so it fires every year on that
+//  date: the schedule must be removed after it fires (AwaitRunAt, CleanupRunAt, SweepRunAt) or the job runs again 12 months later
+func OneTimeCron(at time.Time) string {
+	at = at.UTC()
+	return fmt.Sprintf("%d %d %d %d *", at.Minute(), at.Hour(), at.Day(), int(at.Month()))
+}
+
+// OneTimeSchedule - a UTC schedule firing at `at`.  Cron has minute resolution so a time part way through a minute is rounded up to the next one
+func OneTimeSchedule(at time.Time) *Schedule {
+	if rounded := at.Truncate(time.Minute); !rounded.Equal(at) {
+		at = rounded.Add(time.Minute)
+	}
+	at = at.UTC()
+	return &Schedule{
+		ID:                      RunAtPrefix + at.Format(runAtIDLayout),
+		Cron:                    OneTimeCron(at),
+		ConcurrencyPolicy:       "ALLOW",
+		Enabled:                 true,
+		StartingDeadlineSeconds: runAtDeadline,
+		Timezone:                "UTC",
+	}
+}
+
+// OneTimeAt - when a schedule made by OneTimeSchedule or RunAt fires.  false for any other schedule
+func OneTimeAt(sched *Schedule) (time.Time, bool) {
+	if !strings.HasPrefix(sched.ID, RunAtPrefix) {
+		return time.Time{}, false
+	}
+	stamp := strings.TrimPrefix(sched.ID, RunAtPrefix)
+	if i := strings.IndexByte(stamp, '-'); i >= 0 {
+		if n, err := strconv.Atoi(stamp[i+1:]); err != nil || n < 2 {
+			return time.Time{}, false
+		}
+		stamp = stamp[:i]
+	}
+	at, err := time.Parse(runAtIDLayout, stamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+// OneTimeDone - a one-time schedule whose starting deadline has passed: it has fired, or missed its chance, and won't fire again until next year
+func OneTimeDone(sched *Schedule, now time.Time) bool {
+	at, ok := OneTimeAt(sched)
+	return ok && now.After(at.Add(time.Duration(sched.StartingDeadlineSeconds)*time.Second))
+}
+
+// RunAt - run the job once at `at` by adding a one-time UTC schedule (see OneTimeSchedule).  Done one-time schedules
+//  of the job are cleaned up first, and the id gets a -2, -3... suffix when the job already runs at that minute.
+//  Call AwaitRunAt to remove the returned schedule once it has fired
+//  POST /v1/jobs/$jobId/schedules
+func (client *Client) RunAt(jobID string, at time.Time) (*Schedule, error) {
+	now := time.Now()
+	sched := OneTimeSchedule(at)
+	if fires, _ := OneTimeAt(sched); !fires.After(now) {
+		return nil, fmt.Errorf("run at %s: the time has passed", at.Format(time.RFC3339))
+	}
+	_, kept, err := client.cleanupRunAt(jobID)
+	if err != nil {
+		return nil, err
+	}
+	base := sched.ID
+	for n := 2; kept[sched.ID]; n++ {
+		sched.ID = fmt.Sprintf("%s-%d", base, n)
+	}
+	if _, err := client.CreateSchedule(jobID, sched); err != nil {
+		return nil, err
+	}
+	return sched, nil
+}
+
+// AwaitRunAt - wait for the one-time schedule RunAt returned to pass its starting deadline, then delete it along with
+//  the job's other done one-time schedules, so it doesn't fire again next year
+func AwaitRunAt(client Metronome, jobID string, sched *Schedule) error {
+	fires, ok := OneTimeAt(sched)
+	if !ok {
+		return fmt.Errorf("schedule %s isn't a one-time schedule", sched.ID)
+	}
+	done := fires.Add(time.Duration(sched.StartingDeadlineSeconds)*time.Second + time.Second)
+	time.Sleep(done.Sub(time.Now()))
+	_, err := client.CleanupRunAt(jobID)
+	return err
+}
+
+// CleanupRunAt - delete the job's one-time schedules whose deadline has passed.  Returns the ids deleted
+//  GET /v1/jobs/$jobId/schedules then DELETE /v1/jobs/$jobId/schedules/$scheduleId
+func (client *Client) CleanupRunAt(jobID string) ([]string, error) {
+	deleted, _, err := client.cleanupRunAt(jobID)
+	return deleted, err
+}
+
+// cleanupRunAt - CleanupRunAt also returning the ids of the schedules left
+func (client *Client) cleanupRunAt(jobID string) ([]string, map[string]bool, error) {
+	scheds, err := client.Schedules(jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	var deleted []string
+	kept := make(map[string]bool)
+	for i := range *scheds {
+		sched := &(*scheds)[i]
+		if !OneTimeDone(sched, now) {
+			kept[sched.ID] = true
+			continue
+		}
+		if _, err := client.DeleteSchedule(jobID, sched.ID); err != nil {
+			return deleted, nil, fmt.Errorf("delete schedule %s: %s", sched.ID, err.Error())
+		}
+		deleted = append(deleted, sched.ID)
+	}
+	return deleted, kept, nil
+}
+
+// SweepRunAt - CleanupRunAt for every job, so done one-time schedules go whoever created them and whether or not
+//  they waited.  Returns the deleted schedules as jobId/scheduleId; the error names the jobs that couldn't be swept
+//  GET /v1/jobs then CleanupRunAt for each job
+func SweepRunAt(client Metronome) ([]string, error) {
+	jobs, err := client.Jobs()
+	if err != nil {
+		return nil, err
+	}
+	var deleted, failed []string
+	for _, job := range *jobs {
+		ids, err := client.CleanupRunAt(job.ID)
+		for _, id := range ids {
+			deleted = append(deleted, job.ID+"/"+id)
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", job.ID, err.Error()))
+		}
+	}
+	if len(failed) > 0 {
+		return deleted, fmt.Errorf("run-at cleanup failed for %s", strings.Join(failed, "; "))
+	}
+	return deleted, nil
+}
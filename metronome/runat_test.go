@@ -0,0 +1,208 @@
+package metronome_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("RunAt", func() {
+	It("Makes a 5 field UTC schedule rounded up to the minute", func() {
+		ny, err := time.LoadLocation("America/New_York")
+		Expect(err).ShouldNot(HaveOccurred())
+		sched := OneTimeSchedule(time.Date(2026, 10, 18, 8, 14, 30, 0, ny))
+		Expect(sched.ID).To(Equal("run-at-202610181215"))
+		Expect(sched.Cron).To(Equal("15 12 18 10 *"))
+		Expect(sched.Timezone).To(Equal("UTC"))
+		Expect(sched.Validate()).To(Succeed())
+
+		at, ok := OneTimeAt(sched)
+		Expect(ok).To(BeTrue())
+		Expect(at).To(BeTemporally("==", time.Date(2026, 10, 18, 12, 15, 0, 0, time.UTC)))
+		Expect(OneTimeSchedule(at).ID).To(Equal(sched.ID))
+	})
+
+	It("Is done once the starting deadline passes", func() {
+		sched := OneTimeSchedule(time.Date(2026, 10, 18, 12, 15, 0, 0, time.UTC))
+		Expect(OneTimeDone(sched, time.Date(2026, 10, 18, 12, 15, 30, 0, time.UTC))).To(BeFalse())
+		Expect(OneTimeDone(sched, time.Date(2026, 10, 18, 12, 16, 1, 0, time.UTC))).To(BeTrue())
+		_, ok := OneTimeAt(&Schedule{ID: "nightly"})
+		Expect(ok).To(BeFalse())
+		Expect(OneTimeDone(&Schedule{ID: "nightly"}, time.Now())).To(BeFalse())
+	})
+
+	It("Reads the fire time of ids with a suffix for more runs in the minute", func() {
+		at := time.Date(2026, 10, 18, 12, 15, 0, 0, time.UTC)
+		for _, id := range []string{"run-at-202610181215", "run-at-202610181215-2", "run-at-202610181215-13"} {
+			fires, ok := OneTimeAt(&Schedule{ID: id})
+			Expect(ok).To(BeTrue(), id)
+			Expect(fires).To(BeTemporally("==", at), id)
+		}
+		for _, id := range []string{"run-at-202610181215-", "run-at-202610181215-1", "run-at-202610181215-x", "run-at-2026101812"} {
+			_, ok := OneTimeAt(&Schedule{ID: id})
+			Expect(ok).To(BeFalse(), id)
+		}
+	})
+
+	It("Fires immediately in the next minute", func() {
+		sched, err := ImmediateSchedule()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sched.Validate()).To(Succeed())
+		at, _ := OneTimeAt(sched)
+		Expect(at).To(BeTemporally(">", time.Now()))
+		Expect(at).To(BeTemporally("<=", time.Now().Add(time.Minute)))
+		Expect(ImmediateCrontab()).To(MatchRegexp(`^\d+ \d+ \d+ \d+ \*$`))
+	})
+
+	Describe("Client", func() {
+		var (
+			server *ghttp.Server
+			client Metronome
+		)
+		jsonHeader := http.Header{"Content-Type": {"application/json"}}
+
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+			server.AppendHandlers(ghttp.VerifyRequest("GET", "/v1/jobs"))
+			client, _ = NewClient(Config{URL: server.URL(), RequestTimeout: 5})
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Cleans up done one-time schedules before adding one", func() {
+			done := OneTimeSchedule(time.Now().Add(-time.Hour))
+			pending := OneTimeSchedule(time.Now().Add(time.Hour))
+			scheds := fmt.Sprintf(`[{"id":"nightly","cron":"0 2 * * *"},{"id":"%s","cron":"%s","startingDeadlineSeconds":60},{"id":"%s","cron":"%s","startingDeadlineSeconds":60}]`,
+				done.ID, done.Cron, pending.ID, pending.Cron)
+			at := time.Now().Add(2 * time.Hour)
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs/report/schedules"),
+					ghttp.RespondWith(http.StatusOK, scheds, jsonHeader),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/v1/jobs/report/schedules/"+done.ID),
+					ghttp.RespondWith(http.StatusOK, `{}`, jsonHeader),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/v1/jobs/report/schedules"),
+					ghttp.VerifyJSONRepresenting(OneTimeSchedule(at)),
+					ghttp.RespondWith(http.StatusCreated, `{}`, jsonHeader),
+				),
+			)
+			sched, err := client.RunAt("report", at)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(sched.ID).To(Equal(OneTimeSchedule(at).ID))
+			Expect(server.ReceivedRequests()).To(HaveLen(4))
+		})
+
+		It("Gives another run in the same minute its own id", func() {
+			at := time.Now().Add(2 * time.Hour)
+			first := OneTimeSchedule(at)
+			scheds := fmt.Sprintf(`[{"id":"%s","cron":"%s","startingDeadlineSeconds":60},{"id":"%s-2","cron":"%s","startingDeadlineSeconds":60}]`,
+				first.ID, first.Cron, first.ID, first.Cron)
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs/report/schedules"),
+					ghttp.RespondWith(http.StatusOK, scheds, jsonHeader),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/v1/jobs/report/schedules"),
+					ghttp.RespondWith(http.StatusCreated, `{}`, jsonHeader),
+				),
+			)
+			sched, err := client.RunAt("report", at)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(sched.ID).To(Equal(first.ID + "-3"))
+			Expect(sched.Validate()).To(Succeed())
+			fires, ok := OneTimeAt(sched)
+			Expect(ok).To(BeTrue())
+			firstFires, _ := OneTimeAt(first)
+			Expect(fires).To(BeTemporally("==", firstFires))
+		})
+
+		It("Removes the schedule once its deadline has passed", func() {
+			sched := OneTimeSchedule(time.Now().Add(-2 * time.Minute))
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs/report/schedules"),
+					ghttp.RespondWith(http.StatusOK, fmt.Sprintf(`[{"id":"%s","cron":"%s","startingDeadlineSeconds":60}]`, sched.ID, sched.Cron), jsonHeader),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/v1/jobs/report/schedules/"+sched.ID),
+					ghttp.RespondWith(http.StatusOK, `{}`, jsonHeader),
+				),
+			)
+			Expect(AwaitRunAt(client, "report", sched)).To(Succeed())
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+			Expect(AwaitRunAt(client, "report", &Schedule{ID: "nightly"})).ShouldNot(Succeed())
+		})
+
+		It("Sweeps every job's one-time schedules past their deadline", func() {
+			done := OneTimeSchedule(time.Now().Add(-time.Hour))
+			pending := OneTimeSchedule(time.Now().Add(time.Hour))
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs"),
+					ghttp.RespondWith(http.StatusOK, `[{"id":"report"},{"id":"backup"}]`, jsonHeader),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs/report/schedules"),
+					ghttp.RespondWith(http.StatusOK, fmt.Sprintf(`[{"id":"%s","cron":"%s","startingDeadlineSeconds":60}]`, done.ID, done.Cron), jsonHeader),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/v1/jobs/report/schedules/"+done.ID),
+					ghttp.RespondWith(http.StatusOK, `{}`, jsonHeader),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs/backup/schedules"),
+					ghttp.RespondWith(http.StatusOK, fmt.Sprintf(`[{"id":"nightly","cron":"0 2 * * *"},{"id":"%s","cron":"%s","startingDeadlineSeconds":60}]`, pending.ID, pending.Cron), jsonHeader),
+				),
+			)
+			deleted, err := SweepRunAt(client)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(deleted).To(Equal([]string{"report/" + done.ID}))
+			Expect(server.ReceivedRequests()).To(HaveLen(5))
+		})
+
+		It("Keeps sweeping past a job that fails", func() {
+			done := OneTimeSchedule(time.Now().Add(-time.Hour))
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs"),
+					ghttp.RespondWith(http.StatusOK, `[{"id":"gone"},{"id":"report"}]`, jsonHeader),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs/gone/schedules"),
+					ghttp.RespondWith(http.StatusNotFound, `{"message":"not found"}`, jsonHeader),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs/report/schedules"),
+					ghttp.RespondWith(http.StatusOK, fmt.Sprintf(`[{"id":"%s","cron":"%s","startingDeadlineSeconds":60}]`, done.ID, done.Cron), jsonHeader),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/v1/jobs/report/schedules/"+done.ID),
+					ghttp.RespondWith(http.StatusOK, `{}`, jsonHeader),
+				),
+			)
+			deleted, err := SweepRunAt(client)
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("run-at cleanup failed for gone: "))
+			Expect(deleted).To(Equal([]string{"report/" + done.ID}))
+		})
+
+		It("Refuses a time that has passed", func() {
+			_, err := client.RunAt("report", time.Now().Add(-2*time.Minute))
+			Expect(err).Should(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+})
@@ -0,0 +1,58 @@
+package metronome
+
+import (
+	"strings"
+	"time"
+
+	"github.com/adobe-platform/go-metronome/metronome/cron"
+)
+
+// Location - the schedule's time zone.  Metronome runs schedules without one in UTC
+func (sched *Schedule) Location() (*time.Location, error) {
+	if sched.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(sched.Timezone)
+}
+
+// NextRuns - the next `count` times after `after` the schedule fires, in its time zone.  Enabled is not consulted
+func (sched *Schedule) NextRuns(after time.Time, count int) ([]time.Time, error) {
+	loc, err := sched.Location()
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := cron.Parse(sched.Cron)
+	if err != nil {
+		return nil, err
+	}
+	return parsed.NextN(after, loc, count), nil
+}
+
+// Expanded - a copy of the schedule with H tokens in its cron expression replaced by values hashed from `jobID`.
+//  See cron.ExpandHash.  CreateSchedule and UpdateSchedule send the expanded schedule
+func (sched *Schedule) Expanded(jobID string) (*Schedule, error) {
+	expanded := *sched
+	expr, err := cron.ExpandHash(sched.Cron, jobID)
+	if err != nil {
+		return nil, err
+	}
+	expanded.Cron = expr
+	return &expanded, nil
+}
+
+// Spread - for a schedule firing on the hour (minute field 0), a copy firing at a minute hashed from `jobID` instead,
+//  as if its minute were H.  false when the schedule doesn't fire on the hour
+func (sched *Schedule) Spread(jobID string) (*Schedule, bool, error) {
+	parts := strings.Fields(sched.Cron)
+	if len(parts) == 0 || parts[0] != "0" && parts[0] != "00" {
+		return sched, false, nil
+	}
+	parts[0] = "H"
+	spread := *sched
+	spread.Cron = strings.Join(parts, " ")
+	expanded, err := spread.Expanded(jobID)
+	if err != nil {
+		return nil, false, err
+	}
+	return expanded, expanded.Cron != sched.Cron, nil
+}
@@ -0,0 +1,98 @@
+package metronome_test
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Schedule", func() {
+	It("Finds the next runs in the schedule's zone", func() {
+		sched := &Schedule{ID: "nightly", Cron: "30 2 * * *", Timezone: "America/New_York"}
+		runs, err := sched.NextRuns(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC), 2)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(runs).To(HaveLen(2))
+		Expect(runs[0].UTC()).To(BeTemporally("==", time.Date(2026, 10, 19, 6, 30, 0, 0, time.UTC)))
+	})
+
+	It("Expands H tokens from the job id into a copy", func() {
+		sched := &Schedule{ID: "hourly", Cron: "H H(0-5) * * *"}
+		expanded, err := sched.Expanded("report")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sched.Cron).To(Equal("H H(0-5) * * *"))
+		Expect(expanded.Cron).To(MatchRegexp(`^\d+ [0-5] \* \* \*$`))
+		again, _ := sched.Expanded("report")
+		Expect(again.Cron).To(Equal(expanded.Cron))
+	})
+
+	It("Spreads only schedules firing on the hour", func() {
+		sched := &Schedule{ID: "hourly", Cron: "0 */2 * * 1-5"}
+		spread, changed, err := sched.Spread("report")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(changed).To(Equal(!strings.HasPrefix(spread.Cron, "0 ")))
+		Expect(strings.Fields(spread.Cron)[1:]).To(Equal([]string{"*/2", "*", "*", "1-5"}))
+		expanded, _ := (&Schedule{Cron: "H */2 * * 1-5"}).Expanded("report")
+		Expect(spread.Cron).To(Equal(expanded.Cron))
+
+		_, changed, err = (&Schedule{ID: "quarter", Cron: "15 * * * *"}).Spread("report")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(changed).To(BeFalse())
+	})
+
+	Describe("SetScheduleEnabled", func() {
+		var (
+			server *ghttp.Server
+			client Metronome
+		)
+		jsonHeader := http.Header{"Content-Type": {"application/json"}}
+		live := `{"id":"nightly","cron":"30 2 * * *","concurrencyPolicy":"FORBID","enabled":true,"startingDeadlineSeconds":300,"timezone":"America/New_York","nextRunAt":"2026-10-19T06:30:00.000+0000"}`
+
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+			server.AppendHandlers(ghttp.VerifyRequest("GET", "/v1/jobs"))
+			client, _ = NewClient(Config{URL: server.URL(), RequestTimeout: 5})
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Changes only enabled", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs/report/schedules/nightly"),
+					ghttp.RespondWith(http.StatusOK, live, jsonHeader),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v1/jobs/report/schedules/nightly"),
+					ghttp.VerifyJSON(`{"id":"nightly","cron":"30 2 * * *","concurrencyPolicy":"FORBID","enabled":false,"startingDeadlineSeconds":300,"timezone":"America/New_York"}`),
+					ghttp.RespondWith(http.StatusOK, `{}`, jsonHeader),
+				),
+			)
+			sched, changed, err := client.SetScheduleEnabled("report", "nightly", false)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			Expect(sched.Enabled).To(BeFalse())
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+
+		It("Leaves a schedule already in that state alone", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs/report/schedules/nightly"),
+					ghttp.RespondWith(http.StatusOK, live, jsonHeader),
+				),
+			)
+			_, changed, err := client.SetScheduleEnabled("report", "nightly", true)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(changed).To(BeFalse())
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+})
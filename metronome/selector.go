@@ -0,0 +1,220 @@
+package metronome
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Selector operators
+const (
+	SelectorEquals       = "="
+	SelectorNotEquals    = "!="
+	SelectorIn           = "in"
+	SelectorNotIn        = "notin"
+	SelectorExists       = "exists"
+	SelectorDoesNotExist = "!"
+)
+
+// Requirement - one term of a label selector e.g. env!=dev or tier in (batch,etl)
+type Requirement struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// Selector - Kubernetes style label selector: a job matches when it meets every requirement
+//  owner=zeus,env!=dev,tier in (batch,etl),!deprecated,team
+type Selector []Requirement
+
+var (
+	labelKey       = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_./]*[A-Za-z0-9])?$`)
+	setRequirement = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+)
+
+// ParseSelector - parse a comma separated list of requirements:
+//  key=value, key==value, key!=value, key in (v1,v2), key notin (v1,v2), key (has the label) and !key (doesn't)
+//  An empty string is an empty selector
+func ParseSelector(text string) (Selector, error) {
+	selector := make(Selector, 0)
+	for _, term := range splitTerms(text) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			return nil, errors.New("empty selector term")
+		}
+		req, err := parseRequirement(term)
+		if err != nil {
+			return nil, err
+		}
+		selector = append(selector, *req)
+	}
+	return selector, nil
+}
+
+// splitTerms - split on the commas outside of parentheses
+func splitTerms(text string) []string {
+	var terms []string
+	depth, start := 0, 0
+	for i, c := range text {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, text[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, text[start:])
+}
+
+// parseRequirement - one term
+func parseRequirement(term string) (*Requirement, error) {
+	req := &Requirement{}
+	if match := setRequirement.FindStringSubmatch(term); match != nil {
+		req.Key, req.Operator = match[1], match[2]
+		for _, value := range strings.Split(match[3], ",") {
+			req.Values = append(req.Values, strings.TrimSpace(value))
+		}
+	} else if strings.HasPrefix(term, "!") && !strings.Contains(term, "=") {
+		req.Key, req.Operator = strings.TrimSpace(term[1:]), SelectorDoesNotExist
+	} else if kv := strings.SplitN(term, "!=", 2); len(kv) == 2 {
+		req.Key, req.Operator, req.Values = strings.TrimSpace(kv[0]), SelectorNotEquals, []string{strings.TrimSpace(kv[1])}
+	} else if kv := strings.SplitN(term, "==", 2); len(kv) == 2 {
+		req.Key, req.Operator, req.Values = strings.TrimSpace(kv[0]), SelectorEquals, []string{strings.TrimSpace(kv[1])}
+	} else if kv := strings.SplitN(term, "=", 2); len(kv) == 2 {
+		req.Key, req.Operator, req.Values = strings.TrimSpace(kv[0]), SelectorEquals, []string{strings.TrimSpace(kv[1])}
+	} else {
+		req.Key, req.Operator = term, SelectorExists
+	}
+	if !labelKey.MatchString(req.Key) {
+		return nil, fmt.Errorf("selector term '%s': bad label key '%s'", term, req.Key)
+	}
+	for _, value := range req.Values {
+		if strings.ContainsAny(value, "=!() ") {
+			return nil, fmt.Errorf("selector term '%s': bad label value '%s'", term, value)
+		}
+	}
+	return req, nil
+}
+
+// Matches - the labels meet the requirement.  A missing label meets != and notin
+func (req *Requirement) Matches(labels *Labels) bool {
+	var value string
+	var has bool
+	if labels != nil {
+		value, has = (*labels)[req.Key]
+	}
+	switch req.Operator {
+	case SelectorExists:
+		return has
+	case SelectorDoesNotExist:
+		return !has
+	case SelectorEquals, SelectorIn:
+		return has && contains(req.Values, value)
+	case SelectorNotEquals, SelectorNotIn:
+		return !has || !contains(req.Values, value)
+	}
+	return false
+}
+
+// String - the requirement as it would be parsed
+func (req *Requirement) String() string {
+	switch req.Operator {
+	case SelectorExists:
+		return req.Key
+	case SelectorDoesNotExist:
+		return "!" + req.Key
+	case SelectorIn, SelectorNotIn:
+		return fmt.Sprintf("%s %s (%s)", req.Key, req.Operator, strings.Join(req.Values, ","))
+	}
+	return req.Key + req.Operator + strings.Join(req.Values, ",")
+}
+
+// Matches - the labels meet every requirement.  An empty selector matches every job
+func (selector Selector) Matches(labels *Labels) bool {
+	for i := range selector {
+		if !selector[i].Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// String - the selector as it would be parsed
+func (selector Selector) String() string {
+	terms := make([]string, 0, len(selector))
+	for i := range selector {
+		terms = append(terms, selector[i].String())
+	}
+	return strings.Join(terms, ",")
+}
+
+// MarshalJSON - the selector as it would be parsed
+func (selector Selector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(selector.String())
+}
+
+// UnmarshalJSON - a selector string e.g. "team=reports,env!=dev", or a {"label": "value"} object every label of which
+//  must match
+func (selector *Selector) UnmarshalJSON(raw []byte) error {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		parsed, err := ParseSelector(text)
+		if err != nil {
+			return err
+		}
+		*selector = parsed
+		return nil
+	}
+	var labels map[string]string
+	if err := json.Unmarshal(raw, &labels); err != nil {
+		return errors.New("selector must be a string like team=reports,env!=dev or a {\"label\": \"value\"} object")
+	}
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parsed := make(Selector, 0, len(keys))
+	for _, key := range keys {
+		parsed = append(parsed, Requirement{Key: key, Operator: SelectorEquals, Values: []string{labels[key]}})
+	}
+	*selector = parsed
+	return nil
+}
+
+// SelectJobs - the jobs whose labels match the selector, sorted by id
+func SelectJobs(client Metronome, selector Selector) ([]Job, error) {
+	jobs, err := client.Jobs()
+	if err != nil {
+		return nil, err
+	}
+	selected := make([]Job, 0)
+	for _, job := range *jobs {
+		if selector.Matches(job.Labels) {
+			selected = append(selected, job)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].ID < selected[j].ID })
+	return selected, nil
+}
+
+// contains - value is one of values
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package metronome_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Selector", func() {
+	labels := &Labels{"owner": "zeus", "env": "prod", "tier": "etl"}
+
+	It("Parses every kind of requirement", func() {
+		selector, err := ParseSelector("owner=zeus, env!=dev,tier in (batch, etl),!deprecated,team,region notin (eu)")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(selector).To(HaveLen(6))
+		Expect(selector[2]).To(Equal(Requirement{Key: "tier", Operator: SelectorIn, Values: []string{"batch", "etl"}}))
+		Expect(selector[3].Operator).To(Equal(SelectorDoesNotExist))
+		Expect(selector[4].Operator).To(Equal(SelectorExists))
+		Expect(selector.String()).To(Equal("owner=zeus,env!=dev,tier in (batch,etl),!deprecated,team,region notin (eu)"))
+	})
+
+	It("Rejects bad keys and values", func() {
+		for _, text := range []string{"=zeus", "bad key=1", "a=b=c", "tier in (a b)", "owner=zeus,,env=dev", "region==us notin"} {
+			_, err := ParseSelector(text)
+			Expect(err).Should(HaveOccurred(), text)
+		}
+		selector, err := ParseSelector("")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(selector).To(BeEmpty())
+	})
+
+	It("Matches labels", func() {
+		matches := func(text string, labels *Labels) bool {
+			selector, err := ParseSelector(text)
+			Expect(err).ShouldNot(HaveOccurred())
+			return selector.Matches(labels)
+		}
+		Expect(matches("owner=zeus,env!=dev,tier in (batch,etl)", labels)).To(BeTrue())
+		Expect(matches("owner=zeus,env=dev", labels)).To(BeFalse())
+		Expect(matches("tier notin (etl)", labels)).To(BeFalse())
+		Expect(matches("team", labels)).To(BeFalse())
+		Expect(matches("!team,owner", labels)).To(BeTrue())
+		Expect(matches("env!=dev,tier notin (etl)", nil)).To(BeTrue())
+		Expect(matches("owner", nil)).To(BeFalse())
+		Expect(matches("", nil)).To(BeTrue())
+	})
+})
+
+var _ = Describe("ForEachJob", func() {
+	It("Runs at most the given number at once and reports each job in order", func() {
+		var running, most int32
+		ids := []string{"a", "b", "c", "d", "e", "f"}
+		results, failed := ForEachJob(ids, 2, func(jobID string) (interface{}, error) {
+			now := atomic.AddInt32(&running, 1)
+			for {
+				seen := atomic.LoadInt32(&most)
+				if now <= seen || atomic.CompareAndSwapInt32(&most, seen, now) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			if jobID == "c" {
+				return nil, errors.New("boom")
+			}
+			return "done " + jobID, nil
+		})
+		Expect(atomic.LoadInt32(&most)).To(BeNumerically("<=", 2))
+		Expect(failed).To(Equal(1))
+		Expect(results).To(HaveLen(6))
+		Expect(results[0]).To(Equal(BulkResult{JobID: "a", Result: "done a"}))
+		Expect(results[2]).To(Equal(BulkResult{JobID: "c", Error: "boom"}))
+		Expect(BulkError(results, failed)).To(MatchError("1 of 6 jobs failed"))
+	})
+
+	It("Keeps what an action did before it failed", func() {
+		results, failed := ForEachJob([]string{"report", "backup"}, 2, func(jobID string) (interface{}, error) {
+			if jobID == "backup" {
+				return []string{"run-1"}, errors.New("run run-2: boom")
+			}
+			return []string{"run-3"}, nil
+		})
+		Expect(failed).To(Equal(1))
+		Expect(results[0]).To(Equal(BulkResult{JobID: "report", Result: []string{"run-3"}}))
+		Expect(results[1]).To(Equal(BulkResult{JobID: "backup", Result: []string{"run-1"}, Error: "run run-2: boom"}))
+	})
+})
@@ -0,0 +1,330 @@
+package metronome
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adobe-platform/go-metronome/metronome/cron"
+)
+
+// Patterns and limits taken from Metronome's job and schedule json schemas
+var (
+	jobIDRe         = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]*[a-z0-9]+)*)([.][a-z0-9]([a-z0-9-]*[a-z0-9]+)*)*$`)
+	scheduleIDRe    = regexp.MustCompile(`^([a-z0-9]|[a-z0-9][a-z0-9-]*[a-z0-9])$`)
+	containerPathRe = regexp.MustCompile(`^/[^/].*$`)
+)
+
+const (
+	minCpus           = 0.01
+	minMem            = 32
+	minDisk           = 0
+	minMaxLaunchDelay = 1
+	minStartDeadline  = 1
+)
+
+var restartPolicies = []string{"NEVER", "ON_FAILURE"}
+var concurrencyPolicies = []string{"ALLOW", "FORBID", "REPLACE"}
+
+// FieldError - a single validation failure located by the json path of the offending field
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Error - error interface implementation
+func (fe *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", fe.Field, fe.Message)
+}
+
+// ValidationErrors - every FieldError found while validating a Job or Schedule
+type ValidationErrors []*FieldError
+
+// Error - error interface implementation.  One line per failed field
+func (errs ValidationErrors) Error() string {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "%d validation error(s)", len(errs))
+	for _, fe := range errs {
+		fmt.Fprintf(buf, "\n\t%s", fe.Error())
+	}
+	return buf.String()
+}
+
+// validator - collects field errors while walking a spec
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) add(field string, format string, args ...interface{}) {
+	v.errs = append(v.errs, &FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// err - nil when nothing was collected so callers can test against nil
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func fieldPath(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func oneOf(val string, allowed []string) bool {
+	for _, cur := range allowed {
+		if cur == val {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate - check the job against Metronome's schema without calling the api.
+//  Returns ValidationErrors describing every problem found or nil
+func (theJob *Job) Validate() error {
+	v := new(validator)
+	theJob.validate(v)
+	return v.err()
+}
+
+// ValidateStructure - only what any spec needs: an id, a run, and an id and cron for each schedule.
+//  For specs metronome already accepted, e.g. exported ones, which Validate's stricter checks could reject
+func (theJob *Job) ValidateStructure() error {
+	v := new(validator)
+	if theJob.ID == "" {
+		v.add("id", "is required")
+	}
+	if theJob.Run == nil {
+		v.add("run", "is required")
+	}
+	for i, sched := range theJob.Schedules {
+		path := fmt.Sprintf("schedules[%d]", i)
+		if sched == nil {
+			v.add(path, "is null")
+			continue
+		}
+		if sched.ID == "" {
+			v.add(fieldPath(path, "id"), "is required")
+		}
+		if sched.Cron == "" {
+			v.add(fieldPath(path, "cron"), "is required")
+		}
+	}
+	return v.err()
+}
+
+func (theJob *Job) validate(v *validator) {
+	if theJob.ID == "" {
+		v.add("id", "is required")
+	} else if !jobIDRe.MatchString(theJob.ID) {
+		v.add("id", "'%s' must match %s", theJob.ID, jobIDRe.String())
+	}
+	if theJob.Run == nil {
+		v.add("run", "is required")
+	} else {
+		theJob.Run.validate(v, "run")
+	}
+	for i, sched := range theJob.Schedules {
+		if sched == nil {
+			continue
+		}
+		sched.validate(v, fmt.Sprintf("schedules[%d]", i))
+	}
+}
+
+func (runner *Run) validate(v *validator, path string) {
+	if runner.Cpus < minCpus {
+		v.add(fieldPath(path, "cpus"), "must be >= %v", minCpus)
+	}
+	if runner.Mem < minMem {
+		v.add(fieldPath(path, "mem"), "must be >= %d", minMem)
+	}
+	if runner.Disk < minDisk {
+		v.add(fieldPath(path, "disk"), "must be >= %d", minDisk)
+	}
+	if runner.MaxLaunchDelay < minMaxLaunchDelay {
+		v.add(fieldPath(path, "maxLaunchDelay"), "must be >= %d", minMaxLaunchDelay)
+	}
+	if runner.Gpus < 0 {
+		v.add(fieldPath(path, "gpus"), "must be >= 0")
+	}
+	if runner.TaskKillGracePeriodSeconds < 0 {
+		v.add(fieldPath(path, "taskKillGracePeriodSeconds"), "must be >= 0")
+	}
+	if runner.Docker != nil {
+		runner.Docker.validate(v, fieldPath(path, "docker"))
+	}
+	if runner.Ucr != nil {
+		if runner.Docker != nil {
+			v.add(fieldPath(path, "ucr"), "%s", errRuntimeViol.Error())
+		}
+		runner.Ucr.validate(v, fieldPath(path, "ucr"), runner.Secrets)
+	}
+	for i, network := range runner.Networks {
+		network.validate(v, fmt.Sprintf("%s[%d]", fieldPath(path, "networks"), i))
+	}
+	// walk maps in key order so the reported errors are stable
+	names := make([]string, 0, len(runner.Env))
+	for name := range runner.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		val := runner.Env[name]
+		if !val.IsSecret() {
+			continue
+		}
+		if _, ok := runner.Secrets[val.Secret]; !ok {
+			v.add(fieldPath(path, "env."+name), "references undeclared secret '%s'", val.Secret)
+		}
+	}
+	names = names[:0]
+	for name := range runner.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if runner.Secrets[name].Source == "" {
+			v.add(fieldPath(path, "secrets."+name+".source"), "is required")
+		}
+	}
+	for i, arty := range runner.Artifacts {
+		arty.validate(v, fmt.Sprintf("%s[%d]", fieldPath(path, "artifacts"), i))
+	}
+	for i, vol := range runner.Volumes {
+		vol.validate(v, fmt.Sprintf("%s[%d]", fieldPath(path, "volumes"), i))
+	}
+	if runner.Placement != nil {
+		runner.Placement.validate(v, fieldPath(path, "placement"))
+	}
+	if runner.Restart != nil {
+		runner.Restart.validate(v, fieldPath(path, "restart"))
+	}
+}
+
+func (docker *Docker) validate(v *validator, path string) {
+	if docker.Image == "" {
+		v.add(fieldPath(path, "image"), "is required")
+	}
+	for i, param := range docker.Parameters {
+		if param.Key == "" {
+			v.add(fmt.Sprintf("%s[%d].key", fieldPath(path, "parameters"), i), "is required")
+		}
+	}
+}
+
+func (ucr *Ucr) validate(v *validator, path string, secrets map[string]Secret) {
+	if ucr.Image.ID == "" {
+		v.add(fieldPath(path, "image.id"), "is required")
+	}
+	if ucr.Image.Kind != "" && ucr.Image.Kind != ImageKindDocker && ucr.Image.Kind != ImageKindAppc {
+		v.add(fieldPath(path, "image.kind"), "'%s' must be one of %s,%s", ucr.Image.Kind, ImageKindDocker, ImageKindAppc)
+	}
+	if pc := ucr.Image.PullConfig; pc != nil {
+		if pc.Secret == "" {
+			v.add(fieldPath(path, "image.pullConfig.secret"), "is required")
+		} else if _, ok := secrets[pc.Secret]; !ok {
+			v.add(fieldPath(path, "image.pullConfig.secret"), "references undeclared secret '%s'", pc.Secret)
+		}
+	}
+	for i, vol := range ucr.Volumes {
+		vol.validate(v, fmt.Sprintf("%s[%d]", fieldPath(path, "volumes"), i))
+	}
+}
+
+func (network *Network) validate(v *validator, path string) {
+	switch network.Mode {
+	case NetworkHost, NetworkContainerBridge:
+	case NetworkContainer:
+		if network.Name == "" {
+			v.add(fieldPath(path, "name"), "is required for %s mode", NetworkContainer)
+		}
+	default:
+		v.add(fieldPath(path, "mode"), "'%s' must be one of %s,%s,%s", network.Mode, NetworkHost, NetworkContainer, NetworkContainerBridge)
+	}
+}
+
+func (theArtifact *Artifact) validate(v *validator, path string) {
+	if theArtifact.URI == "" {
+		v.add(fieldPath(path, "uri"), "is required")
+	} else if u, err := url.Parse(theArtifact.URI); err != nil {
+		v.add(fieldPath(path, "uri"), "%s", err.Error())
+	} else if u.Scheme == "" {
+		v.add(fieldPath(path, "uri"), "'%s' must be an absolute uri", theArtifact.URI)
+	}
+}
+
+func (vol *Volume) validate(v *validator, path string) {
+	if !containerPathRe.MatchString(string(vol.ContainerPath)) {
+		v.add(fieldPath(path, "containerPath"), "'%s' must match %s", vol.ContainerPath, containerPathRe.String())
+	}
+	if vol.HostPath == "" {
+		v.add(fieldPath(path, "hostPath"), "is required")
+	}
+	if vol.Mode != RO && vol.Mode != RW {
+		v.add(fieldPath(path, "mode"), "must be one of %s", strings.Join(mountModes[:], ","))
+	}
+}
+
+func (thePlacement *Placement) validate(v *validator, path string) {
+	for i, con := range thePlacement.Constraints {
+		cpath := fmt.Sprintf("%s[%d]", fieldPath(path, "constraints"), i)
+		if con.Attribute == "" {
+			v.add(fieldPath(cpath, "attribute"), "is required")
+		}
+		if con.Operator < EQ || int(con.Operator) > len(constraintOperators) {
+			v.add(fieldPath(cpath, "operator"), "must be one of %s", strings.Join(constraintOperators[:], ","))
+		}
+	}
+}
+
+func (restart *Restart) validate(v *validator, path string) {
+	if !oneOf(restart.Policy, restartPolicies) {
+		v.add(fieldPath(path, "policy"), "'%s' must be one of %s", restart.Policy, strings.Join(restartPolicies, ","))
+	}
+	if restart.ActiveDeadlineSeconds < 0 {
+		v.add(fieldPath(path, "activeDeadlineSeconds"), "must be >= 0")
+	}
+}
+
+// Validate - check the schedule against Metronome's schema without calling the api.
+//  Returns ValidationErrors describing every problem found or nil
+func (sched *Schedule) Validate() error {
+	v := new(validator)
+	sched.validate(v, "")
+	return v.err()
+}
+
+func (sched *Schedule) validate(v *validator, path string) {
+	if sched.ID == "" {
+		v.add(fieldPath(path, "id"), "is required")
+	} else if !scheduleIDRe.MatchString(sched.ID) {
+		v.add(fieldPath(path, "id"), "'%s' must match %s", sched.ID, scheduleIDRe.String())
+	}
+	if sched.Cron == "" {
+		v.add(fieldPath(path, "cron"), "is required")
+	} else if expr, err := cron.ExpandHash(sched.Cron, sched.ID); err != nil {
+		v.add(fieldPath(path, "cron"), "%s", err.Error())
+	} else if err := cron.Validate(expr); err != nil {
+		v.add(fieldPath(path, "cron"), "%s", err.Error())
+	}
+	if !oneOf(sched.ConcurrencyPolicy, concurrencyPolicies) {
+		v.add(fieldPath(path, "concurrencyPolicy"), "'%s' must be one of %s", sched.ConcurrencyPolicy, strings.Join(concurrencyPolicies, ","))
+	}
+	if sched.StartingDeadlineSeconds < minStartDeadline {
+		v.add(fieldPath(path, "startingDeadlineSeconds"), "must be >= %d", minStartDeadline)
+	}
+	if sched.Timezone != "" {
+		if _, err := time.LoadLocation(sched.Timezone); err != nil {
+			v.add(fieldPath(path, "timezone"), "unknown time zone '%s'", sched.Timezone)
+		}
+	}
+}
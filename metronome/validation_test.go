@@ -0,0 +1,187 @@
+package metronome_test
+
+import (
+	"encoding/json"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fields - the json paths reported by a ValidationErrors
+func fields(err error) []string {
+	errs, ok := err.(ValidationErrors)
+	Expect(ok).To(BeTrue(), "expected ValidationErrors got %T", err)
+	paths := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		paths = append(paths, fe.Field)
+	}
+	return paths
+}
+
+var _ = Describe("Validation", func() {
+	var job Job
+
+	BeforeEach(func() {
+		job = Job{}
+		Expect(json.Unmarshal([]byte(data5), &job)).To(Succeed())
+	})
+
+	Describe("Job.Validate", func() {
+		It("Accepts a valid job", func() {
+			Expect(job.Validate()).To(Succeed())
+		})
+
+		It("Rejects a bad job id", func() {
+			job.ID = "Prod_Example"
+			Expect(fields(job.Validate())).To(ConsistOf("id"))
+		})
+
+		It("Requires a run", func() {
+			job.Run = nil
+			Expect(fields(job.Validate())).To(ConsistOf("run"))
+		})
+
+		It("Enforces resource minimums", func() {
+			job.Run.SetCpus(0.001).SetMem(16).SetDisk(-1).SetMaxLaunchDelay(0)
+			Expect(fields(job.Validate())).To(ConsistOf("run.cpus", "run.mem", "run.disk", "run.maxLaunchDelay"))
+		})
+
+		It("Checks the restart policy", func() {
+			job.Run.SetRestart(&Restart{Policy: "ALWAYS", ActiveDeadlineSeconds: -1})
+			Expect(fields(job.Validate())).To(ConsistOf("run.restart.policy", "run.restart.activeDeadlineSeconds"))
+		})
+
+		It("Checks volumes", func() {
+			job.Run.SetVolumes([]Volume{
+				{ContainerPath: "/mnt/ok", HostPath: "/tmp", Mode: RO},
+				{ContainerPath: "relative", HostPath: "", Mode: 0},
+			})
+			Expect(fields(job.Validate())).To(ConsistOf("run.volumes[1].containerPath", "run.volumes[1].hostPath", "run.volumes[1].mode"))
+		})
+
+		It("Checks gpus, grace period, networks and docker parameters", func() {
+			job.Run.SetGpus(-1).SetTaskKillGracePeriodSeconds(-5).SetNetworks([]Network{
+				{Mode: NetworkHost},
+				{Mode: NetworkContainer},
+				{Mode: "bridge"},
+			})
+			job.Run.Docker.AddParameter("", "x")
+			Expect(fields(job.Validate())).To(ConsistOf(
+				"run.gpus",
+				"run.taskKillGracePeriodSeconds",
+				"run.networks[1].name",
+				"run.networks[2].mode",
+				"run.docker.parameters[0].key",
+			))
+		})
+
+		It("Rejects docker and ucr together", func() {
+			ucr, err := NewUcr("alpine", ImageKindDocker)
+			Expect(err).NotTo(HaveOccurred())
+			job.Run.SetUcr(ucr)
+			Expect(fields(job.Validate())).To(ConsistOf("run.ucr"))
+		})
+
+		It("Checks the ucr image, pull secret and volumes", func() {
+			job.Run.SetDocker(nil).SetUcr(&Ucr{
+				Image:   UcrImage{Kind: "oci", PullConfig: &PullConfig{Secret: "registry"}},
+				Volumes: []Volume{{ContainerPath: "relative", HostPath: "/tmp", Mode: RW}},
+			})
+			Expect(fields(job.Validate())).To(ConsistOf(
+				"run.ucr.image.id",
+				"run.ucr.image.kind",
+				"run.ucr.image.pullConfig.secret",
+				"run.ucr.volumes[0].containerPath",
+			))
+			job.Run.AddSecret("registry", "/ci/registry")
+			job.Run.Ucr.Image = UcrImage{ID: "alpine", Kind: ImageKindAppc, PullConfig: &PullConfig{Secret: "registry"}}
+			job.Run.Ucr.Volumes = nil
+			Expect(job.Validate()).To(Succeed())
+		})
+
+		It("Checks artifact uris", func() {
+			job.Run.SetArtifacts([]Artifact{{URI: ""}, {URI: "application.zip"}})
+			Expect(fields(job.Validate())).To(ConsistOf("run.artifacts[0].uri", "run.artifacts[1].uri"))
+		})
+
+		It("Reports every embedded schedule problem by index", func() {
+			job.Schedules = []*Schedule{
+				{ID: "ok", Cron: "*/2 * * * *", ConcurrencyPolicy: "ALLOW", StartingDeadlineSeconds: 60, Timezone: "Etc/GMT"},
+				{ID: "Bad_Id", Cron: "61 * * * *", ConcurrencyPolicy: "SOMETIMES", Timezone: "Mars/Olympus"},
+			}
+			Expect(fields(job.Validate())).To(ConsistOf(
+				"schedules[1].id",
+				"schedules[1].cron",
+				"schedules[1].concurrencyPolicy",
+				"schedules[1].startingDeadlineSeconds",
+				"schedules[1].timezone",
+			))
+		})
+	})
+
+	Describe("Job.ValidateStructure", func() {
+		It("Accepts what metronome accepted even where Validate is stricter", func() {
+			job.Run.Cpus = 0
+			job.Schedules = []*Schedule{{ID: "odd", Cron: "0 0 30 2 *", Timezone: "Not/AZone"}}
+			Expect(job.Validate()).To(HaveOccurred())
+			Expect(job.ValidateStructure()).To(Succeed())
+		})
+
+		It("Requires an id, a run and schedule ids and crons", func() {
+			job = Job{Schedules: []*Schedule{{}, nil}}
+			Expect(fields(job.ValidateStructure())).To(Equal([]string{"id", "run", "schedules[0].id", "schedules[0].cron", "schedules[1]"}))
+		})
+	})
+
+	Describe("Schedule.Validate", func() {
+		var sched Schedule
+
+		BeforeEach(func() {
+			sched = Schedule{ID: "every2", Cron: "*/2 * * * *", ConcurrencyPolicy: "ALLOW", StartingDeadlineSeconds: 60, Timezone: "America/New_York"}
+		})
+
+		It("Accepts a valid schedule", func() {
+			Expect(sched.Validate()).To(Succeed())
+		})
+
+		It("Accepts names, ranges, lists and steps in cron", func() {
+			sched.Cron = "0,30 8-18/2 1 JAN-jun mon-FRI"
+			Expect(sched.Validate()).To(Succeed())
+		})
+
+		It("Accepts H tokens", func() {
+			sched.Cron = "H H(0-5) * * *"
+			Expect(sched.Validate()).To(Succeed())
+		})
+
+		It("Rejects malformed cron", func() {
+			for _, cron := range []string{"* * * *", "* * * * * 2017", "*/0 * * * *", "* 24 * * *", "* * 0 * *", "* * * * 8", "5-1 * * * *", "a * * * *", "H(0-99) * * * *"} {
+				sched.Cron = cron
+				Expect(fields(sched.Validate())).To(ConsistOf("cron"), cron)
+			}
+		})
+	})
+
+	Describe("ContainerPath", func() {
+		It("Rejects paths not matching the metronome pattern", func() {
+			_, err := NewContainerPath("relative/path")
+			Expect(err).To(HaveOccurred())
+			_, err = NewContainerPath("//double")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Accepts absolute paths", func() {
+			path, err := NewContainerPath("/mnt/test")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(path)).To(Equal("/mnt/test"))
+		})
+
+		It("Rejects bad paths when decoding", func() {
+			var vol Volume
+			err := json.Unmarshal([]byte(`{"containerPath":"relative","hostPath":"/tmp","mode":"RO"}`), &vol)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})